@@ -4,14 +4,203 @@ import (
 	"fmt"
 	"reflect"
 	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
 )
 
 type Config struct {
+	// TagName is the struct tag key that will be consulted for field names and
+	// options. If both TagName and TagNames are empty, no tag is consulted,
+	// and fields are named by their Go names. The package-level functions use
+	// a TagName of "map".
 	TagName string
+	// TagNames is a list of additional struct tag keys to be consulted, in
+	// order, after TagName -- eg. []string{"map", "json", "db"}. The first key
+	// with a non-empty tag on a given field provides both that field's name
+	// and its options; later keys are ignored.
+	//
+	// Options are parsed identically regardless of which key they come from;
+	// the name is followed by comma separated options, and a name of "-"
+	// causes the field to be skipped. Options are matched case-insensitively,
+	// so json's "omitempty" and "omitzero" are treated as this package's
+	// "omitEmpty" and "omitZero", and `json:"name,omitempty"` behaves as
+	// `map:"name,omitEmpty"`. Options this package doesn't recognize -- such
+	// as json's "string" -- are ignored.
+	TagNames []string
+	// OmitNilers will cause any field whose type implements the pyrrho/encoding
+	// IsNiler interface to be omitted when IsNil() returns true, as if the
+	// field had been tagged with "omitNil".
+	OmitNilers bool
+	// OmitZeroers will cause any field whose type implements the
+	// pyrrho/encoding IsZeroer interface to be omitted when IsZero() returns
+	// true, as if the field had been tagged with "omitZero".
+	OmitZeroers bool
+	// RejectJSONIncompatible will cause Marshal to return an error if any
+	// encoded value contains something encoding/json is unable to marshal --
+	// complex numbers, channels, functions, or maps with keys that can't be
+	// converted to strings. This is useful as a guardrail when the resulting
+	// map is destined to be encoded as JSON.
+	RejectJSONIncompatible bool
+	// DecodeRawMessage will cause fields of type json.RawMessage (and
+	// *json.RawMessage) to be decoded into their interface{} representation
+	// -- map[string]interface{}, []interface{}, float64, etc. -- rather than
+	// being kept as raw bytes, so that the resulting map is homogeneous. Empty
+	// RawMessages will be encoded as nil, and malformed JSON will result in an
+	// error.
+	//
+	// Plain []byte fields are unaffected by this setting, and are always kept
+	// as []byte. There is no option to base64 encode them during
+	// map-ification; encoding/json will do so if the map is later marshalled.
+	DecodeRawMessage bool
+	// NormalizeNamedScalars will cause fields of named bool, integer, float,
+	// and string types (eg. `type UserID int64`) to be converted to their
+	// underlying builtin type (int64) in the encoded map, so they compare
+	// equal to plain values, and encode predictably. Types that implement
+	// Marshaler or fmt.Stringer are unaffected, as are values held by an
+	// interface{} field.
+	NormalizeNamedScalars bool
+	// KindMarshalers maps reflect.Kinds to functions that will be used to
+	// encode every field whose type is of that kind -- eg. every
+	// reflect.Complex128 -- and doesn't implement the Marshaler interface.
+	// Values held by interface{} fields are not inspected. Types that implement
+	// Marshaler take precedence over KindMarshalers, which take precedence over
+	// the default encoding behavior (including the DecodeRawMessage and
+	// NormalizeNamedScalars options, and DurationFormat). Errors returned by a
+	// KindMarshaler will be returned from Marshal.
+	//
+	// The encoders built for a Config with KindMarshalers are cached only for
+	// the duration of a single call to Marshal, or for the lifetime of an
+	// Encoder, so this map must not be modified once it's been passed to
+	// NewEncoder.
+	KindMarshalers map[reflect.Kind]func(reflect.Value) (interface{}, error)
+	// OmitFunc, if set, is called with the key and encoded value of every
+	// candidate entry -- each struct field, including those of nested
+	// structs, and each element of a map passed to Marshal -- just before
+	// it's written, and the entry is omitted if it returns true. It's called
+	// after the "omitNil", "omitZero", and "omitEmpty" tag options, and the
+	// OmitNilers and OmitZeroers settings, have been applied.
+	OmitFunc func(key string, value interface{}) bool
+	// SkipNilSliceElements will cause MarshalSlice to leave nil elements --
+	// nil pointers-to-struct, or nil interface{}s -- out of the returned
+	// slice. By default, they're encoded as nil maps, so that the indices of
+	// the returned slice match those of the source.
+	SkipNilSliceElements bool
+	// DurationFormat controls how time.Duration (and *time.Duration) fields
+	// are encoded. Defaults to DurationNanos, which keeps the time.Duration
+	// value as-is.
+	DurationFormat DurationFormat
+	// Computed maps keys to functions that derive a value from the whole of a
+	// top-level struct passed to Marshal or MarshalSlice -- eg. a "full_name"
+	// built from first and last name fields. Each function is called with the
+	// struct value (not a pointer to it) after its fields have been encoded,
+	// and its result is written under the corresponding key, overriding any
+	// field of the same name. The functions are called for every top-level
+	// struct, regardless of its type. Computed values are subject to OmitFunc
+	// and RejectJSONIncompatible, like any other entry, and are not added to
+	// nested structs, or to maps passed to Marshal. Errors returned by a
+	// Computed function will be returned from Marshal.
+	Computed map[string]func(v interface{}) (interface{}, error)
+	// TypeFieldName, if set, is the key under which the name of each encoded
+	// struct's type is written -- eg. "_type": "Order" -- so that consumers of
+	// the map can tell which type it came from. The type name replaces any
+	// field of the same name. By default, only top-level structs passed to
+	// Marshal or MarshalSlice are tagged.
+	TypeFieldName string
+	// TypeFieldFunc, if set, is called with the type of each struct tagged
+	// with TypeFieldName, and returns the value written under that key.
+	// Defaults to reflect.Type.Name. Structs for which the name is empty --
+	// anonymous structs, for instance -- are left untagged.
+	TypeFieldFunc func(t reflect.Type) string
+	// TypeFieldNestedOnly selects the structs TypeFieldName is written into.
+	// When false, only top-level structs are tagged. When true, the maps
+	// produced for nested structs are tagged as well, at every level.
+	TypeFieldNestedOnly bool
+	// KeyAliases maps the keys of struct fields -- as resolved from their tags
+	// or names -- to the keys they'll be written under, so that one struct can
+	// be adapted to several naming schemes at call time. An alias of "" causes
+	// the field to be omitted. Aliases are applied last, after every other
+	// naming rule, and before OmitFunc and RejectJSONIncompatible, which see
+	// the aliased key. Keys with no alias are written unchanged. The keys of
+	// maps passed to Marshal, Computed keys, and TypeFieldName are not
+	// aliased.
+	KeyAliases map[string]string
+	// ValuesSeparator, if set, will cause MarshalValues to flatten nested
+	// structs into the returned map, with each nested field's key prefixed by
+	// the key of its parent and this separator; eg. "address.city" for a
+	// separator of ".". By default, nested structs are an error.
+	ValuesSeparator string
+	// ExpandEmbeddedInterfaces will cause Marshal to look through exported,
+	// untagged, embedded interface fields, and promote the fields of the
+	// struct (or pointer-to-struct) they hold into the enclosing map, as if
+	// that struct had been embedded directly. Fields of the enclosing struct
+	// take precedence over promoted fields of the same name, as do fields
+	// promoted from earlier embedded interfaces. Nil interfaces, and nil
+	// pointers held by them, contribute nothing. Interfaces holding any other
+	// kind of value are encoded under their type's name, as they are by
+	// default.
+	ExpandEmbeddedInterfaces bool
+	// OmitZeroThroughPointers will cause the "omitZero" tag option to look
+	// through pointer fields, and omit those that point at a zero value --
+	// eg. a *int pointing at 0 -- as well as those that are themselves zero.
+	// IsZeroer implementations of the pointed-to value are respected. Nil
+	// pointers are zero, and are omitted by "omitZero" (or "omitNil")
+	// regardless of this setting.
+	OmitZeroThroughPointers bool
+	// AtomicAware will cause fields of the sync/atomic types -- atomic.Int64,
+	// atomic.Bool, atomic.Value, atomic.Pointer[T], etc., and pointers to them
+	// -- to be encoded as the value returned by their Load method, rather than
+	// as the (empty) map of their unexported fields; eg. a snapshot of a struct
+	// of atomic counters. Loaded values are encoded as a field of their type
+	// would be, and an atomic.Value that has never been stored to, or a nil
+	// pointer to any atomic type, is encoded as nil. Each field is loaded
+	// independently, so the snapshot as a whole is not atomic.
+	AtomicAware bool
+	// NilersAsNil will cause any struct field whose value implements the
+	// pyrrho/encoding IsNiler interface, and reports itself as nil, to be
+	// written as an explicit nil, regardless of how its type would otherwise
+	// be encoded -- by its MarshalMapValue method, or as a map of its fields.
+	// Consumers of the map can then rely on a present key with a nil value to
+	// mean "explicitly null". Fields omitted by "omitNil", OmitNilers, or any
+	// other rule are still omitted; this only changes the value of those that
+	// remain.
+	NilersAsNil bool
+	// CloneMaps and CloneSlices will cause the maps and slices held by encoded
+	// values -- map and slice fields, such as a map[string]interface{} or a
+	// []string, and those nested within them -- to be deep copied into the
+	// encoded map.
+	//
+	// By default, they're stored as-is, and share their contents with the
+	// source; modifying a slice element, or a map entry, in the encoded map will
+	// modify the source value as well, and vice versa. Only the maps produced
+	// for structs, and for maps passed to Marshal, are always new. Copying
+	// avoids that aliasing, at the cost of an allocation per map or slice.
+	//
+	// Arrays and interface{}s are copied through, so that maps and slices
+	// within them are cloned, but pointers are not followed; the values they
+	// point to remain shared. Values returned by a Marshaler are cloned like
+	// any other. Maps and slices that contain themselves are not supported.
+	CloneMaps   bool
+	CloneSlices bool
+
+	// encodeFns, if set, is used by lookupEncodeFn in place of encodeFnCache.
+	encodeFns *sync.Map
 }
 
+// DurationFormat describes how time.Duration fields are encoded.
+type DurationFormat int
+
+const (
+	// DurationNanos encodes time.Durations unchanged, as an integer count of
+	// nanoseconds.
+	DurationNanos DurationFormat = iota
+	// DurationString encodes time.Durations as strings, formatted by
+	// time.Duration.String; eg. "1h2m3.5s".
+	DurationString
+	// DurationSeconds encodes time.Durations as a float64 count of seconds.
+	DurationSeconds
+)
+
 var defaultConfig = &Config{
 	TagName: "map",
 }
@@ -30,9 +219,10 @@ type field struct {
 	nameBytes []byte                 // []byte(name)
 	equalFold func(s, t []byte) bool // bytes.EqualFold or equivalent
 
-	tagged bool
-	index  []int
-	typ    reflect.Type
+	tagged   bool
+	embedded bool // an untagged, embedded field of non-struct type
+	index    []int
+	typ      reflect.Type
 
 	options tagOptions
 }
@@ -43,7 +233,12 @@ func fillField(f field) field {
 	return f
 }
 
-// byIndex sorts field by index sequence.
+// byIndex sorts fields into declaration order, by index sequence. Within a
+// struct, its own fields come first, in the order they're declared, followed by
+// the fields promoted from each of its embedded structs, in the order those
+// are declared; the same rule is applied within each embedded struct. That is,
+// outer fields precede inner ones, regardless of where the embedded field
+// appears among its siblings.
 type byIndex []field
 
 func (fields byIndex) Len() int {
@@ -55,28 +250,59 @@ func (fields byIndex) Swap(i, j int) {
 }
 
 func (fields byIndex) Less(i, j int) bool {
-	l := fields[i]
-	r := fields[j]
-	for k := 0; k < len(l.index); k++ {
-		if k >= len(r.index) {
-			return false
+	l := fields[i].index
+	r := fields[j].index
+	for k := 0; k < len(l) && k < len(r); k++ {
+		// A field declared at this level precedes one promoted through an
+		// embedded struct at this level.
+		if lOwn, rOwn := k == len(l)-1, k == len(r)-1; lOwn != rOwn {
+			return lOwn
 		}
-		if l.index[k] != r.index[k] {
-			return l.index[k] < r.index[k]
+		if l[k] != r[k] {
+			return l[k] < r[k]
 		}
 	}
-	return len(l.index) < len(r.index)
+	return len(l) < len(r)
+}
+
+// tagNames returns the struct tag keys to be consulted, in order, as described
+// by cfg.TagName and cfg.TagNames.
+func (cfg *Config) tagNames() []string {
+	if len(cfg.TagNames) == 0 {
+		// An empty TagName consults no tag at all; fields are named by their
+		// Go names.
+		return []string{cfg.TagName}
+	}
+	if cfg.TagName == "" {
+		return cfg.TagNames
+	}
+	return append([]string{cfg.TagName}, cfg.TagNames...)
+}
+
+// tagKey returns a comparable representation of cfg.tagNames().
+func (cfg *Config) tagKey() string {
+	if len(cfg.TagNames) == 0 {
+		return cfg.TagName
+	}
+	return strings.Join(cfg.tagNames(), ",")
+}
+
+// fieldCacheKey identifies a type's fields, as seen through a set of tag keys.
+type fieldCacheKey struct {
+	t    reflect.Type
+	tags string
 }
 
 var fieldCache struct {
-	value atomic.Value // map[reflect.Type][]field
+	value atomic.Value // map[fieldCacheKey][]field
 	mu    sync.Mutex   // used only by writers
 }
 
 // cachedTypeFields caches the return of typeFields to avoid repeated work.
 func cachedTypeFields(t reflect.Type, cfg *Config) []field {
-	m, _ := fieldCache.value.Load().(map[reflect.Type][]field)
-	f := m[t]
+	key := fieldCacheKey{t, cfg.tagKey()}
+	m, _ := fieldCache.value.Load().(map[fieldCacheKey][]field)
+	f := m[key]
 	if f != nil {
 		return f
 	}
@@ -89,12 +315,12 @@ func cachedTypeFields(t reflect.Type, cfg *Config) []field {
 	}
 
 	fieldCache.mu.Lock()
-	m, _ = fieldCache.value.Load().(map[reflect.Type][]field)
-	newM := make(map[reflect.Type][]field, len(m)+1)
+	m, _ = fieldCache.value.Load().(map[fieldCacheKey][]field)
+	newM := make(map[fieldCacheKey][]field, len(m)+1)
 	for k, v := range m {
 		newM[k] = v
 	}
-	newM[t] = f
+	newM[key] = f
 	fieldCache.value.Store(newM)
 	fieldCache.mu.Unlock()
 	return f
@@ -118,6 +344,8 @@ func typeFields(t reflect.Type, cfg *Config) []field {
 	// Fields found.
 	var fields []field
 
+	tagNames := cfg.tagNames()
+
 	for len(next) > 0 {
 		current, next = next, current[:0]
 		count, nextCount = nextCount, map[reflect.Type]int{}
@@ -150,7 +378,12 @@ func typeFields(t reflect.Type, cfg *Config) []field {
 					continue
 				}
 
-				tag := sf.Tag.Get(cfg.TagName)
+				var tag string
+				for _, tn := range tagNames {
+					if tag = sf.Tag.Get(tn); tag != "" {
+						break
+					}
+				}
 				tagged := tag != ""
 				name, opts := parseTag(tag)
 				if name == "-" {
@@ -175,11 +408,12 @@ func typeFields(t reflect.Type, cfg *Config) []field {
 				// Record the found field and index sequence ...
 				if tagged || !isEmbedded || sft.Kind() != reflect.Struct {
 					fields = append(fields, fillField(field{
-						name:    name,
-						tagged:  tagged,
-						index:   index,
-						typ:     sft,
-						options: opts,
+						name:     name,
+						tagged:   tagged,
+						embedded: isEmbedded && !tagged,
+						index:    index,
+						typ:      sft,
+						options:  opts,
 					}))
 					if count[f.typ] > 1 {
 						// If there were multiple instances, add a second, so
@@ -249,9 +483,11 @@ func typeFields(t reflect.Type, cfg *Config) []field {
 		}
 		// ... otherwise, find the single field that dominates the other
 		// similarly named fields using Go's embedding rules, modified by the
-		// presence of map tags. If there are multiple top-level fields -- which
-		// is an error in Go -- we mirror the compile-time "ambiguous selector"
-		// error as closely as possible at runtime. With a panic.
+		// presence of map tags. If there are multiple tagged fields at the
+		// dominant depth, the tags are in genuine conflict, and we panic. If
+		// there are multiple untagged fields at the dominant depth, Go
+		// considers the selector ambiguous, and -- as reflect.VisibleFields
+		// does -- we hide all of them.
 		contended := fields[i : i+count]
 		taggedIndex := -1
 		for j, f := range contended {
@@ -279,9 +515,9 @@ func typeFields(t reflect.Type, cfg *Config) []field {
 			continue
 		}
 		// All remaining contended fields have the same length. If there's more
-		// than one, we have a conflict.
+		// than one, none of them are visible.
 		if len(contended) > 1 {
-			panic(fmt.Errorf("encmap: ambiguous tagged field name '%s' in %s", contended[0].name, t.Name()))
+			continue
 		}
 		out = append(out, contended[0])
 	}
@@ -13,8 +13,11 @@ func Unmarshal(src interface{}, v interface{}) error {
 	return nil
 }
 
+// Unmarshaler is implemented by types that can decode themselves from a value
+// held by a map[string]interface{}; typically the value their MarshalMapValue
+// method produced.
 type Unmarshaler interface {
-	UnmarshalMapValue() error
+	UnmarshalMapValue(v interface{}) error
 }
 
 var unmarshalerType = reflect.TypeOf(new(Unmarshaler)).Elem()
@@ -8,5 +8,9 @@ as well as other open-source alternatives.
 Note that this package relies _heavily_ on the reflect package and, as such,
 has severely weakened compile-time type-safety. Be sure to keep an eye on your
 error returns.
+
+Also note that encoded maps share the maps and slices held by their source;
+writing to a map or slice in the encoded map writes to the source as well. Set
+Config.CloneMaps and Config.CloneSlices if the two need to be independent.
 */
 package maps
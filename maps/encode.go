@@ -1,36 +1,80 @@
 package maps
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
 	"runtime"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/pyrrho/encoding"
 )
 
 func Marshal(src interface{}) (map[string]interface{}, error) {
-	ret, err := defaultConfig.marshal(src)
-	if err != nil {
-		return nil, err
-	}
-	return ret, nil
+	return defaultEncoder.Encode(src)
 }
 
 func MarshalSlice(src interface{}) ([]map[string]interface{}, error) {
-	ret, err := defaultConfig.marshalSlice(src)
-	if err != nil {
-		return nil, err
-	}
-	return ret, nil
+	return defaultEncoder.EncodeSlice(src)
 }
 
+// MarshalWithConfig is equivalent to cfg.Marshal(src).
+func MarshalWithConfig(src interface{}, cfg *Config) (map[string]interface{}, error) {
+	return cfg.Marshal(src)
+}
+
+// Marshaler is implemented by types that can encode themselves into a value for
+// use in a map[string]interface{}. MarshalMapValue is consulted wherever a
+// Marshaler appears; as a struct field, a top-level map value, or an element of
+// a slice, array, or map field. Slices and arrays holding Marshalers will be
+// encoded as []interface{}s, and maps as map[string]interface{}s.
+//
+// The "value" tag option takes precedence over Marshaler; a field tagged with
+// "value" is stored as-is, and its MarshalMapValue method is never called.
+//
+// MarshalMapValue may return ErrSkipField to have itself left out entirely,
+// rather than encoded as nil.
 type Marshaler interface {
 	MarshalMapValue() (interface{}, error)
 }
 
-var marshalerType = reflect.TypeOf(new(Marshaler)).Elem()
+// ErrSkipField may be returned by a Marshaler's MarshalMapValue method to omit
+// it from the encoded result, giving types dynamic control over their own
+// omission. Struct fields and map entries holding the Marshaler are left out
+// of their maps, and slice and array elements out of their slices. It's never
+// returned as an error by this package.
+var ErrSkipField = errors.New("maps: skip this field")
+
+// skipField is returned by encodeFns in place of a value when a Marshaler
+// returns ErrSkipField. It must be checked for wherever encoded values are
+// stored.
+var skipField interface{} = struct{ skip bool }{true}
+
+var (
+	marshalerType     = reflect.TypeOf(new(Marshaler)).Elem()
+	jsonMarshalerType = reflect.TypeOf(new(json.Marshaler)).Elem()
+	rawMessageType    = reflect.TypeOf(json.RawMessage(nil))
+	durationType      = reflect.TypeOf(time.Duration(0))
+	isNilerType       = reflect.TypeOf(new(encoding.IsNiler)).Elem()
+	isZeroerType      = reflect.TypeOf(new(encoding.IsZeroer)).Elem()
+)
+
+// atomicTypes holds the non-generic sync/atomic types read by encodeAtomic.
+var atomicTypes = map[reflect.Type]bool{
+	reflect.TypeOf(new(atomic.Bool)).Elem():    true,
+	reflect.TypeOf(new(atomic.Int32)).Elem():   true,
+	reflect.TypeOf(new(atomic.Int64)).Elem():   true,
+	reflect.TypeOf(new(atomic.Uint32)).Elem():  true,
+	reflect.TypeOf(new(atomic.Uint64)).Elem():  true,
+	reflect.TypeOf(new(atomic.Uintptr)).Elem(): true,
+	reflect.TypeOf(new(atomic.Value)).Elem():   true,
+}
 
 func (cfg *Config) Marshal(src interface{}) (map[string]interface{}, error) {
 	ret, err := cfg.marshal(src)
@@ -49,12 +93,19 @@ func (cfg *Config) MarshalSlice(src interface{}) ([]map[string]interface{}, erro
 }
 
 func (cfg *Config) marshal(src interface{}) (m map[string]interface{}, err error) {
+	cfg = cfg.withEncodeFns()
 	srcv := reflect.ValueOf(src)
 	if srcv.Kind() == reflect.Ptr {
 		srcv = srcv.Elem()
 	}
-	if srcv.Kind() != reflect.Struct {
-		return nil, errors.New("src must be a struct, or pointer-to-struct")
+	switch srcv.Kind() {
+	case reflect.Struct:
+	case reflect.Map:
+		if !isStringableKey(srcv.Type().Key()) {
+			return nil, fmt.Errorf("src map key type %s cannot be converted to a string", srcv.Type().Key())
+		}
+	default:
+		return nil, errors.New("src must be a struct, a map, or a pointer to either")
 	}
 
 	// Any panics after this point should be converted to errors, and returned
@@ -74,11 +125,11 @@ func (cfg *Config) marshal(src interface{}) (m map[string]interface{}, err error
 		}
 	}()
 
-	ret := lookupEncodeFn(srcv.Type(), cfg)(srcv, cfg)
-	return ret.(map[string]interface{}), nil
+	return cfg.encodeTopLevel(srcv), nil
 }
 
 func (cfg *Config) marshalSlice(src interface{}) (m []map[string]interface{}, err error) {
+	cfg = cfg.withEncodeFns()
 	srcv := reflect.ValueOf(src)
 	if srcv.Kind() == reflect.Ptr {
 		srcv = srcv.Elem()
@@ -104,22 +155,190 @@ func (cfg *Config) marshalSlice(src interface{}) (m []map[string]interface{}, er
 		}
 	}()
 
-	m = make([]map[string]interface{}, srcv.Len())
+	m = make([]map[string]interface{}, 0, srcv.Len())
 	for i := 0; i < srcv.Len(); i++ {
 		elemv := srcv.Index(i)
-		if elemv.Kind() == reflect.Ptr || elemv.Kind() == reflect.Interface {
+		for (elemv.Kind() == reflect.Ptr || elemv.Kind() == reflect.Interface) && !elemv.IsNil() {
 			elemv = elemv.Elem()
 		}
-		m[i] = (lookupEncodeFn(elemv.Type(), cfg)(elemv, cfg)).(map[string]interface{})
+		if elemv.Kind() == reflect.Ptr || elemv.Kind() == reflect.Interface {
+			// The element is, or points to, nil.
+			if !cfg.SkipNilSliceElements {
+				m = append(m, nil)
+			}
+			continue
+		}
+		m = append(m, cfg.encodeTopLevel(elemv))
 	}
 	return m, nil
 }
 
+// encodeTopLevel encodes the struct or map src into a map[string]interface{}.
+// Maps are copied key-by-key, with each key converted to a string and each
+// value encoded as if it were a struct field.
+func (cfg *Config) encodeTopLevel(src reflect.Value) map[string]interface{} {
+	switch src.Kind() {
+	case reflect.Struct:
+		m, ok := lookupEncodeFn(src.Type(), cfg)(src, cfg).(map[string]interface{})
+		if !ok {
+			// The struct's cached encoder is a Marshaler or KindMarshaler that
+			// didn't produce a map; top-level structs are always encoded field
+			// by field.
+			m = newStructEncoder(src.Type(), cfg)(src, cfg).(map[string]interface{})
+		}
+		if len(cfg.Computed) > 0 {
+			m = cfg.applyComputed(src, m)
+		}
+		cfg.addTypeField(src.Type(), m)
+		return m
+	case reflect.Map:
+		if !isStringableKey(src.Type().Key()) {
+			panic(fmt.Errorf("map key type %s cannot be converted to a string", src.Type().Key()))
+		}
+		if src.IsNil() {
+			return nil
+		}
+		ret := make(map[string]interface{}, src.Len())
+		iter := src.MapRange()
+		for iter.Next() {
+			k := stringifyKey(iter.Key())
+			v := cfg.clone(cfg.encodeElem(iter.Value()))
+			if v == skipField || cfg.omitEntry(k, v) {
+				continue
+			}
+			cfg.checkJSONCompatible(k, v)
+			ret[k] = v
+		}
+		return ret
+	default:
+		panic(fmt.Errorf("cannot marshal a %s into a map[string]interface{}", src.Type()))
+	}
+}
+
+// applyComputed returns a copy of m, the encoded form of the struct src, with
+// the entries described by cfg.Computed added. Keys are visited in sorted
+// order, so that errors are reported deterministically.
+func (cfg *Config) applyComputed(src reflect.Value, m map[string]interface{}) map[string]interface{} {
+	ret := make(map[string]interface{}, len(m)+len(cfg.Computed))
+	for k, v := range m {
+		ret[k] = v
+	}
+	keys := make([]string, 0, len(cfg.Computed))
+	for k := range cfg.Computed {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	iface := src.Interface()
+	for _, k := range keys {
+		fn := cfg.Computed[k]
+		if fn == nil {
+			continue
+		}
+		v, err := fn(iface)
+		if err != nil {
+			panic(err)
+		}
+		if cfg.omitEntry(k, v) {
+			continue
+		}
+		cfg.checkJSONCompatible(k, v)
+		ret[k] = v
+	}
+	return ret
+}
+
+// encodeElem encodes the value held by a map, following any pointer or
+// interface{} indirection.
+func (cfg *Config) encodeElem(v reflect.Value) interface{} {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	return lookupEncodeFn(v.Type(), cfg)(v, cfg)
+}
+
+// textMarshaler mirrors the standard library's encoding.TextMarshaler, which
+// can't be imported by name alongside pyrrho/encoding.
+type textMarshaler interface {
+	MarshalText() ([]byte, error)
+}
+
+var textMarshalerType = reflect.TypeOf(new(textMarshaler)).Elem()
+
+// isStringableKey returns true if map keys of type t can be converted to
+// strings; if they are strings, integers, or encoding.TextMarshalers.
+func isStringableKey(t reflect.Type) bool {
+	if t.Implements(textMarshalerType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+// stringifyKey converts the map key k into a string, following the same rules
+// as encoding/json.
+func stringifyKey(k reflect.Value) string {
+	if k.Kind() == reflect.String {
+		return k.String()
+	}
+	if tm, ok := k.Interface().(textMarshaler); ok {
+		if k.Kind() == reflect.Ptr && k.IsNil() {
+			return ""
+		}
+		b, err := tm.MarshalText()
+		if err != nil {
+			panic(err)
+		}
+		return string(b)
+	}
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10)
+	}
+	panic(fmt.Errorf("map key type %s cannot be converted to a string", k.Type()))
+}
+
 type encodeFn func(src reflect.Value, cfg *Config) interface{}
 
 type encoderFnCacheKey struct {
 	t reflect.Type
-	c Config
+	c configKey
+}
+
+// configKey is a comparable representation of a Config, for use in cache keys.
+// KindMarshalers can't be represented by value, and Configs that set it use an
+// encodeFnCache of their own; see withEncodeFns.
+type configKey struct {
+	tagName                string
+	omitNilers             bool
+	omitZeroers            bool
+	rejectJSONIncompatible bool
+	decodeRawMessage       bool
+	normalizeNamedScalars  bool
+	atomicAware            bool
+	durationFormat         DurationFormat
+}
+
+func (cfg *Config) key() configKey {
+	return configKey{
+		tagName:                cfg.tagKey(),
+		omitNilers:             cfg.OmitNilers,
+		omitZeroers:            cfg.OmitZeroers,
+		rejectJSONIncompatible: cfg.RejectJSONIncompatible,
+		decodeRawMessage:       cfg.DecodeRawMessage,
+		normalizeNamedScalars:  cfg.NormalizeNamedScalars,
+		atomicAware:            cfg.AtomicAware,
+		durationFormat:         cfg.DurationFormat,
+	}
 }
 
 // `encodeFnCache` is based on encode/json's encoderCache. It stores the given
@@ -127,10 +346,26 @@ type encoderFnCacheKey struct {
 // happen once.
 var encodeFnCache sync.Map // map[encoderFnCacheKey]encodeFn
 
+// withEncodeFns returns cfg, or, if cfg sets KindMarshalers, a copy of cfg
+// with a fresh encodeFn cache of its own, to be used in place of
+// encodeFnCache for the duration of a single call.
+func (cfg *Config) withEncodeFns() *Config {
+	if len(cfg.KindMarshalers) == 0 || cfg.encodeFns != nil {
+		return cfg
+	}
+	c := *cfg
+	c.encodeFns = new(sync.Map)
+	return &c
+}
+
 func lookupEncodeFn(t reflect.Type, cfg *Config) encodeFn {
-	key := encoderFnCacheKey{t, *cfg}
+	cache := &encodeFnCache
+	if cfg.encodeFns != nil {
+		cache = cfg.encodeFns
+	}
+	key := encoderFnCacheKey{t, cfg.key()}
 	// Early-out on quick cache-hits.
-	if fn, ok := encodeFnCache.Load(key); ok {
+	if fn, ok := cache.Load(key); ok {
 		return fn.(encodeFn)
 	}
 
@@ -144,7 +379,7 @@ func lookupEncodeFn(t reflect.Type, cfg *Config) encodeFn {
 		fn encodeFn
 	)
 	wg.Add(1)
-	fi, loaded := encodeFnCache.LoadOrStore(
+	fi, loaded := cache.LoadOrStore(
 		key,
 		encodeFn(func(src reflect.Value, cfg *Config) interface{} {
 			wg.Wait()
@@ -161,7 +396,7 @@ func lookupEncodeFn(t reflect.Type, cfg *Config) encodeFn {
 	// find/construct the correct encoder and replace the indirect fn.
 	fn = newEncodeValueFn(t, cfg, true)
 	wg.Done()
-	encodeFnCache.Store(key, fn)
+	cache.Store(key, fn)
 	return fn
 }
 
@@ -178,6 +413,32 @@ func newEncodeValueFn(t reflect.Type, cfg *Config, firstPass bool) encodeFn {
 			newEncodeValueFn(t, cfg, false),
 		)
 	}
+	if fn, ok := cfg.KindMarshalers[t.Kind()]; ok && fn != nil {
+		return newKindEncoder(fn)
+	}
+	if cfg.DecodeRawMessage && (t == rawMessageType || t == reflect.PtrTo(rawMessageType)) {
+		return encodeRawMessage
+	}
+	if cfg.DurationFormat != DurationNanos && (t == durationType || t == reflect.PtrTo(durationType)) {
+		return encodeDuration
+	}
+	if cfg.AtomicAware && (isAtomic(t) || t.Kind() == reflect.Ptr && isAtomic(t.Elem())) {
+		return encodeAtomic
+	}
+	if cfg.NormalizeNamedScalars && isNamedScalar(t) {
+		return encodeNamedScalar
+	}
+	if containsMarshaler(t, map[reflect.Type]bool{}) {
+		switch t.Kind() {
+		case reflect.Slice, reflect.Array:
+			return newSliceEncoder(t, cfg)
+		case reflect.Map:
+			return newMapEncoder(t, cfg)
+		}
+	}
+	if fn, ok := scalarEncoders[t]; ok {
+		return fn
+	}
 	switch t.Kind() {
 	case reflect.Struct:
 		return newStructEncoder(t, cfg)
@@ -188,6 +449,90 @@ func newEncodeValueFn(t reflect.Type, cfg *Config, firstPass bool) encodeFn {
 	}
 }
 
+// scalarEncoders holds fast paths for the most common builtin scalar types.
+// Each reads its value with the matching typed reflect.Value getter and boxes
+// the result directly, which is cheaper than reflect.Value.Interface, and lets
+// the runtime avoid allocating for small values. Named types are not included,
+// so the encoded values are identical to those of encodeInterface.
+var scalarEncoders = map[reflect.Type]encodeFn{
+	reflect.TypeOf(false): func(src reflect.Value, cfg *Config) interface{} {
+		return src.Bool()
+	},
+	reflect.TypeOf(int(0)): func(src reflect.Value, cfg *Config) interface{} {
+		return int(src.Int())
+	},
+	reflect.TypeOf(int64(0)): func(src reflect.Value, cfg *Config) interface{} {
+		return src.Int()
+	},
+	reflect.TypeOf(float64(0)): func(src reflect.Value, cfg *Config) interface{} {
+		return src.Float()
+	},
+	reflect.TypeOf(""): func(src reflect.Value, cfg *Config) interface{} {
+		return src.String()
+	},
+}
+
+// containsMarshaler returns true if t is a slice, array, or map type whose
+// elements -- or whose elements' elements, etc. -- implement Marshaler. Maps
+// are only considered if their keys can be converted to strings.
+func containsMarshaler(t reflect.Type, visited map[reflect.Type]bool) bool {
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+	case reflect.Map:
+		if !isStringableKey(t.Key()) {
+			return false
+		}
+	default:
+		return false
+	}
+	if visited[t] {
+		return false
+	}
+	visited[t] = true
+	e := t.Elem()
+	if e.Implements(marshalerType) || reflect.PtrTo(e).Implements(marshalerType) {
+		return true
+	}
+	return containsMarshaler(e, visited)
+}
+
+// newSliceEncoder returns an encodeFn that encodes each element of a slice or
+// array of type t into a new []interface{}.
+func newSliceEncoder(t reflect.Type, cfg *Config) encodeFn {
+	elemEnc := lookupEncodeFn(t.Elem(), cfg)
+	return func(src reflect.Value, cfg *Config) interface{} {
+		if src.Kind() == reflect.Slice && src.IsNil() {
+			return nil
+		}
+		ret := make([]interface{}, 0, src.Len())
+		for i := 0; i < src.Len(); i++ {
+			if v := elemEnc(src.Index(i), cfg); v != skipField {
+				ret = append(ret, v)
+			}
+		}
+		return ret
+	}
+}
+
+// newMapEncoder returns an encodeFn that encodes each value of a map of type t
+// into a new map[string]interface{}.
+func newMapEncoder(t reflect.Type, cfg *Config) encodeFn {
+	elemEnc := lookupEncodeFn(t.Elem(), cfg)
+	return func(src reflect.Value, cfg *Config) interface{} {
+		if src.IsNil() {
+			return nil
+		}
+		ret := make(map[string]interface{}, src.Len())
+		iter := src.MapRange()
+		for iter.Next() {
+			if v := elemEnc(iter.Value(), cfg); v != skipField {
+				ret[stringifyKey(iter.Key())] = v
+			}
+		}
+		return ret
+	}
+}
+
 func encodeInterface(src reflect.Value, cfg *Config) interface{} {
 	if !src.CanInterface() {
 		panic(errors.New("How did you get here with a non-interfaceable value?"))
@@ -195,6 +540,123 @@ func encodeInterface(src reflect.Value, cfg *Config) interface{} {
 	return src.Interface()
 }
 
+func encodeRawMessage(src reflect.Value, cfg *Config) interface{} {
+	if src.Kind() == reflect.Ptr {
+		if src.IsNil() {
+			return nil
+		}
+		src = src.Elem()
+	}
+	raw := src.Bytes()
+	if len(raw) == 0 {
+		return nil
+	}
+	var ret interface{}
+	if err := json.Unmarshal(raw, &ret); err != nil {
+		panic(fmt.Errorf("maps: cannot decode json.RawMessage: %v", err))
+	}
+	return ret
+}
+
+func encodeDuration(src reflect.Value, cfg *Config) interface{} {
+	if src.Kind() == reflect.Ptr {
+		if src.IsNil() {
+			return nil
+		}
+		src = src.Elem()
+	}
+	d := time.Duration(src.Int())
+	switch cfg.DurationFormat {
+	case DurationString:
+		return d.String()
+	case DurationSeconds:
+		return d.Seconds()
+	default:
+		panic(fmt.Errorf("maps: unknown DurationFormat %d", cfg.DurationFormat))
+	}
+}
+
+// isAtomic returns true if t is one of the sync/atomic types; one of
+// atomicTypes, or an instantiation of atomic.Pointer.
+func isAtomic(t reflect.Type) bool {
+	return atomicTypes[t] ||
+		t.PkgPath() == "sync/atomic" && strings.HasPrefix(t.Name(), "Pointer[")
+}
+
+func encodeAtomic(src reflect.Value, cfg *Config) interface{} {
+	if src.Kind() == reflect.Ptr {
+		if src.IsNil() {
+			return nil
+		}
+		src = src.Elem()
+	}
+	// Load has a pointer receiver. Values that can't be addressed -- those
+	// passed to Marshal directly, for instance -- are already copies, so
+	// loading from another copy is no less accurate.
+	if !src.CanAddr() {
+		cp := reflect.New(src.Type()).Elem()
+		cp.Set(src)
+		src = cp
+	}
+	v := src.Addr().MethodByName("Load").Call(nil)[0]
+	if v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+	}
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	return lookupEncodeFn(v.Type(), cfg)(v, cfg)
+}
+
+func newKindEncoder(fn func(reflect.Value) (interface{}, error)) encodeFn {
+	return func(src reflect.Value, cfg *Config) interface{} {
+		ret, err := fn(src)
+		if err != nil {
+			panic(err)
+		}
+		return ret
+	}
+}
+
+// builtinScalarTypes maps each scalar reflect.Kind to its builtin type.
+var builtinScalarTypes = map[reflect.Kind]reflect.Type{
+	reflect.Bool:    reflect.TypeOf(false),
+	reflect.Int:     reflect.TypeOf(int(0)),
+	reflect.Int8:    reflect.TypeOf(int8(0)),
+	reflect.Int16:   reflect.TypeOf(int16(0)),
+	reflect.Int32:   reflect.TypeOf(int32(0)),
+	reflect.Int64:   reflect.TypeOf(int64(0)),
+	reflect.Uint:    reflect.TypeOf(uint(0)),
+	reflect.Uint8:   reflect.TypeOf(uint8(0)),
+	reflect.Uint16:  reflect.TypeOf(uint16(0)),
+	reflect.Uint32:  reflect.TypeOf(uint32(0)),
+	reflect.Uint64:  reflect.TypeOf(uint64(0)),
+	reflect.Uintptr: reflect.TypeOf(uintptr(0)),
+	reflect.Float32: reflect.TypeOf(float32(0)),
+	reflect.Float64: reflect.TypeOf(float64(0)),
+	reflect.String:  reflect.TypeOf(""),
+}
+
+// isNamedScalar returns true if t is a named -- not builtin -- bool, integer,
+// float, or string type that implements neither Marshaler nor fmt.Stringer.
+func isNamedScalar(t reflect.Type) bool {
+	builtin, ok := builtinScalarTypes[t.Kind()]
+	if !ok || t == builtin {
+		return false
+	}
+	if t.Implements(stringerType) || reflect.PtrTo(t).Implements(stringerType) ||
+		reflect.PtrTo(t).Implements(marshalerType) {
+		return false
+	}
+	return true
+}
+
+func encodeNamedScalar(src reflect.Value, cfg *Config) interface{} {
+	return src.Convert(builtinScalarTypes[src.Kind()]).Interface()
+}
+
 func encodeMarshaller(src reflect.Value, cfg *Config) interface{} {
 	if src.Kind() == reflect.Ptr && src.IsNil() {
 		return nil
@@ -203,11 +665,7 @@ func encodeMarshaller(src reflect.Value, cfg *Config) interface{} {
 	if !ok {
 		panic(errors.New("How did you get here w/o an enc_map.Marshaler?"))
 	}
-	ret, err := m.MarshalMapValue()
-	if err != nil {
-		panic(err)
-	}
-	return ret
+	return marshalMapValue(m)
 }
 
 func encodeAddrMarshaller(src reflect.Value, cfg *Config) interface{} {
@@ -219,7 +677,16 @@ func encodeAddrMarshaller(src reflect.Value, cfg *Config) interface{} {
 	if !ok {
 		panic(errors.New("How did you get here w/o a pointer-to enc_map.Marshaler?"))
 	}
+	return marshalMapValue(m)
+}
+
+// marshalMapValue calls m.MarshalMapValue, panicking with any error other than
+// ErrSkipField, which is replaced by skipField.
+func marshalMapValue(m Marshaler) interface{} {
 	ret, err := m.MarshalMapValue()
+	if errors.Is(err, ErrSkipField) {
+		return skipField
+	}
 	if err != nil {
 		panic(err)
 	}
@@ -274,21 +741,125 @@ type structEncoder struct {
 
 func (se *structEncoder) encode(src reflect.Value, cfg *Config) interface{} {
 	ret := make(map[string]interface{}, len(se.fields))
+	var expand []reflect.Value
 	for i, f := range se.fields {
+		k, ok := cfg.fieldKey(f)
+		if !ok {
+			continue
+		}
 		fv := fieldByIndex(src, f.index)
-		if !fv.IsValid() ||
-			(f.options.Contains("omitZero") && encoding.IsValueZero(fv)) ||
-			(f.options.Contains("omitNil") && encoding.IsValueNil(fv)) {
+		if !fv.IsValid() || cfg.omitField(f, fv) {
 			continue
 		}
+		if cfg.ExpandEmbeddedInterfaces && f.embedded && fv.Kind() == reflect.Interface {
+			if fv.IsNil() {
+				continue
+			}
+			if sv, ok := embeddedStruct(fv); ok {
+				if sv.IsValid() {
+					expand = append(expand, sv)
+				}
+				continue
+			}
+		}
 		if !src.CanInterface() {
 			panic(fmt.Errorf("How did you get here with a non-interfaceable value?"))
 		}
-		ret[f.name] = se.fieldEncs[i](fv, cfg)
+		if v, ok := cfg.encodeField(k, fv, se.fieldEncs[i]); ok {
+			ret[k] = v
+		}
+	}
+	for _, sv := range expand {
+		se.promote(ret, sv, cfg)
+	}
+	if cfg.TypeFieldNestedOnly {
+		cfg.addTypeField(src.Type(), ret)
 	}
 	return ret
 }
 
+// encodeField encodes fv, the value of a field to be written under the key k,
+// with enc, and returns the result; or false if the entry should be omitted.
+// Omissions that depend only on the field are made by omitField, before
+// encodeField is called.
+func (cfg *Config) encodeField(k string, fv reflect.Value, enc encodeFn) (interface{}, bool) {
+	var v interface{}
+	if !cfg.NilersAsNil || !isNilNiler(fv) {
+		v = cfg.clone(enc(fv, cfg))
+	}
+	if v == skipField || cfg.omitEntry(k, v) {
+		return nil, false
+	}
+	cfg.checkJSONCompatible(k, v)
+	return v, true
+}
+
+// embeddedStruct returns the struct held by the non-nil interface value v --
+// directly, or by way of one or more pointers -- and true, or false if v holds
+// some other kind of value. The returned value is invalid if v holds a nil
+// pointer-to-struct.
+func embeddedStruct(v reflect.Value) (reflect.Value, bool) {
+	v = v.Elem()
+	t := v.Type()
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Value{}, true
+		}
+		v = v.Elem()
+	}
+	return v, true
+}
+
+// promote encodes the struct sv, held by an embedded interface field, and
+// writes its entries into m, skipping any keys that are already present, or
+// that belong to one of se's own fields.
+func (se *structEncoder) promote(m map[string]interface{}, sv reflect.Value, cfg *Config) {
+	pm, ok := lookupEncodeFn(sv.Type(), cfg)(sv, cfg).(map[string]interface{})
+	if !ok {
+		return
+	}
+	if cfg.TypeFieldName != "" {
+		delete(pm, cfg.TypeFieldName)
+	}
+	for k, v := range pm {
+		if _, ok := m[k]; ok || se.hasKey(k, cfg) {
+			continue
+		}
+		m[k] = v
+	}
+}
+
+// hasKey returns true if one of se's fields is written under the key k.
+func (se *structEncoder) hasKey(k string, cfg *Config) bool {
+	for _, f := range se.fields {
+		if fk, ok := cfg.fieldKey(f); ok && fk == k && !f.embedded {
+			return true
+		}
+	}
+	return false
+}
+
+// addTypeField writes the name of the struct type t into m under the key
+// cfg.TypeFieldName, if one is set.
+func (cfg *Config) addTypeField(t reflect.Type, m map[string]interface{}) {
+	if cfg.TypeFieldName == "" || m == nil {
+		return
+	}
+	name := t.Name()
+	if cfg.TypeFieldFunc != nil {
+		name = cfg.TypeFieldFunc(t)
+	}
+	if name != "" {
+		m[cfg.TypeFieldName] = name
+	}
+}
+
 func newStructEncoder(t reflect.Type, cfg *Config) encodeFn {
 	fields := cachedTypeFields(t, cfg)
 	se := structEncoder{
@@ -304,3 +875,274 @@ func newStructEncoder(t reflect.Type, cfg *Config) encodeFn {
 	}
 	return se.encode
 }
+
+// omitField returns true if the field f, with the value fv, should be left out
+// of the encoded map; either because of its "omitNil", "omitZero", and
+// "omitEmpty" options, or because of the OmitNilers and OmitZeroers settings of
+// cfg.
+func (cfg *Config) omitField(f field, fv reflect.Value) bool {
+	if f.options.Contains("omitNil") && valueIsNil(fv) {
+		return true
+	}
+	if f.options.Contains("omitZero") {
+		if valueIsZero(fv) || cfg.OmitZeroThroughPointers && pointsToZero(fv) {
+			return true
+		}
+	}
+	if f.options.Contains("omitEmpty") && valueIsEmpty(fv) {
+		return true
+	}
+	if cfg.OmitNilers {
+		if ok, isNil := asIsNiler(fv); ok && isNil {
+			return true
+		}
+	}
+	if cfg.OmitZeroers {
+		if ok, isZero := asIsZeroer(fv); ok && isZero {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldKey returns the key the field f should be written under, after applying
+// cfg.KeyAliases, or false if it has been aliased away.
+func (cfg *Config) fieldKey(f field) (string, bool) {
+	if alias, ok := cfg.KeyAliases[f.name]; ok {
+		return alias, alias != ""
+	}
+	return f.name, true
+}
+
+// omitEntry returns true if the entry with the key k and the encoded value v
+// should be left out of the encoded map, according to cfg.OmitFunc.
+func (cfg *Config) omitEntry(k string, v interface{}) bool {
+	return cfg.OmitFunc != nil && cfg.OmitFunc(k, v)
+}
+
+// checkJSONCompatible panics with an error naming the field name if cfg has
+// RejectJSONIncompatible set, and the encoded value v can't be marshalled by
+// encoding/json.
+func (cfg *Config) checkJSONCompatible(name string, v interface{}) {
+	if !cfg.RejectJSONIncompatible {
+		return
+	}
+	if t := jsonIncompatibleType(reflect.ValueOf(v)); t != nil {
+		panic(fmt.Errorf("maps: field %s contains a value of type %s, which cannot be marshalled into JSON", name, t))
+	}
+}
+
+// jsonIncompatibleType searches v for values that encoding/json is unable to
+// marshal, and returns the type of the first one found, or nil if there are
+// none.
+func jsonIncompatibleType(v reflect.Value) reflect.Type {
+	if !v.IsValid() {
+		return nil
+	}
+	t := v.Type()
+	if t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) {
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Complex64, reflect.Complex128, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return t
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return jsonIncompatibleType(v.Elem())
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// []byte is marshalled as a base64 string.
+			return nil
+		}
+		for i := 0; i < v.Len(); i++ {
+			if et := jsonIncompatibleType(v.Index(i)); et != nil {
+				return et
+			}
+		}
+	case reflect.Map:
+		if !isStringableKey(t.Key()) {
+			return t
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			if et := jsonIncompatibleType(iter.Value()); et != nil {
+				return et
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if sf := t.Field(i); sf.PkgPath != "" && !sf.Anonymous {
+				continue
+			}
+			if et := jsonIncompatibleType(v.Field(i)); et != nil {
+				return et
+			}
+		}
+	}
+	return nil
+}
+
+// valueIsNil is a variant of encoding.IsValueNil that will also consult the
+// IsNiler interface of pointer-receivers and interface-wrapped values.
+func valueIsNil(v reflect.Value) bool {
+	if ok, isNil := asIsNiler(v); ok {
+		return isNil
+	}
+	switch v.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+		return v.IsNil()
+	}
+	return false
+}
+
+// pointsToZero returns true if v is a non-nil pointer -- or a chain of them --
+// to a zero value, as reported by valueIsZero.
+func pointsToZero(v reflect.Value) bool {
+	if v.Kind() != reflect.Ptr {
+		return false
+	}
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return false
+		}
+		v = v.Elem()
+	}
+	return valueIsZero(v)
+}
+
+// valueIsZero is a variant of encoding.IsValueZero that will also consult the
+// IsZeroer interface of pointer-receivers and interface-wrapped values.
+func valueIsZero(v reflect.Value) bool {
+	if ok, isZero := asIsZeroer(v); ok {
+		return isZero
+	}
+	return encoding.IsValueZero(v)
+}
+
+// valueIsEmpty reports whether v is empty, as encoding/json's "omitempty"
+// option understands it -- false, 0, a nil pointer or interface{}, or an empty
+// array, slice, map, or string -- unless v implements the IsZeroer interface,
+// in which case IsZero() decides. This lets nullable types, which are structs
+// and so never empty to encoding/json, be omitted when they're null or zero.
+func valueIsEmpty(v reflect.Value) bool {
+	if ok, isZero := asIsZeroer(v); ok {
+		return isZero
+	}
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
+
+// asIsNiler reports whether v implements the pyrrho/encoding IsNiler interface
+// -- directly, through its address, or through the value held by an
+// interface{} -- and if so, whether v is nil. Nil pointers, and nil values of
+// interface types that include IsNil (such as IsNiler itself), are always nil.
+func asIsNiler(v reflect.Value) (implements bool, isNil bool) {
+	if v.Kind() == reflect.Interface && !v.IsNil() {
+		return asIsNiler(v.Elem())
+	}
+	t := v.Type()
+	switch {
+	case t.Implements(isNilerType):
+		if (t.Kind() == reflect.Ptr || t.Kind() == reflect.Interface) && v.IsNil() {
+			return true, true
+		}
+		return true, v.Interface().(encoding.IsNiler).IsNil()
+	case v.CanAddr() && reflect.PtrTo(t).Implements(isNilerType):
+		return true, v.Addr().Interface().(encoding.IsNiler).IsNil()
+	}
+	return false, false
+}
+
+// clone returns a deep copy of the maps and slices in v, as described by
+// cfg.CloneMaps and cfg.CloneSlices, or v itself if neither is set.
+func (cfg *Config) clone(v interface{}) interface{} {
+	if !cfg.CloneMaps && !cfg.CloneSlices || v == nil {
+		return v
+	}
+	return cloneValue(reflect.ValueOf(v), cfg.CloneMaps, cfg.CloneSlices).Interface()
+}
+
+// cloneValue returns a copy of v in which every map (if maps is true) and
+// slice (if slices is true) reachable without following a pointer has been
+// replaced by a copy of itself.
+func cloneValue(v reflect.Value, maps, slices bool) reflect.Value {
+	switch v.Kind() {
+	case reflect.Map:
+		if !maps || v.IsNil() {
+			return v
+		}
+		ret := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			ret.SetMapIndex(iter.Key(), cloneValue(iter.Value(), maps, slices))
+		}
+		return ret
+	case reflect.Slice:
+		if !slices || v.IsNil() {
+			return v
+		}
+		ret := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			ret.Index(i).Set(cloneValue(v.Index(i), maps, slices))
+		}
+		return ret
+	case reflect.Array:
+		ret := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			ret.Index(i).Set(cloneValue(v.Index(i), maps, slices))
+		}
+		return ret
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		ret := reflect.New(v.Type()).Elem()
+		ret.Set(cloneValue(v.Elem(), maps, slices))
+		return ret
+	}
+	return v
+}
+
+// isNilNiler returns true if v implements the pyrrho/encoding IsNiler
+// interface, as determined by asIsNiler, and is nil.
+func isNilNiler(v reflect.Value) bool {
+	ok, isNil := asIsNiler(v)
+	return ok && isNil
+}
+
+// asIsZeroer reports whether v implements the pyrrho/encoding IsZeroer
+// interface -- directly, through its address, or through the value held by an
+// interface{} -- and if so, whether v is zero. Nil pointers, and nil values of
+// interface types that include IsZero (such as IsZeroer itself), are always
+// zero.
+func asIsZeroer(v reflect.Value) (implements bool, isZero bool) {
+	if v.Kind() == reflect.Interface && !v.IsNil() {
+		return asIsZeroer(v.Elem())
+	}
+	t := v.Type()
+	switch {
+	case t.Implements(isZeroerType):
+		if (t.Kind() == reflect.Ptr || t.Kind() == reflect.Interface) && v.IsNil() {
+			return true, true
+		}
+		return true, v.Interface().(encoding.IsZeroer).IsZero()
+	case v.CanAddr() && reflect.PtrTo(t).Implements(isZeroerType):
+		return true, v.Addr().Interface().(encoding.IsZeroer).IsZero()
+	}
+	return false, false
+}
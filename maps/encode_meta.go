@@ -0,0 +1,120 @@
+package maps
+
+import (
+	"errors"
+	"reflect"
+	"runtime"
+)
+
+// Field describes a single struct field encoded by MarshalWithMeta.
+type Field struct {
+	// Value is the encoded value of the field; the same value Marshal would
+	// have produced for it.
+	Value interface{}
+	// GoType is the declared Go type of the field, as formatted by
+	// reflect.Type.String; e.g. "*null.Int64".
+	GoType string
+	// WasNil is true if the field held a nil pointer, interface, map, slice,
+	// chan, or func, or a value whose IsNil method returned true.
+	WasNil bool
+	// SourceName is the name of the field in the Go source, before any tag
+	// renaming. For fields promoted from embedded structs, it is the name of
+	// the field within the struct that declares it.
+	SourceName string
+	// Position is the zero-based position of the field among those encoded,
+	// in declaration order; sorting Fields by Position orders their keys as
+	// the fields are declared in the Go source. Fields promoted from embedded
+	// structs follow the fields of the struct that embeds them. Positions are
+	// unique, but where KeyAliases write several fields under one key, only
+	// the last is returned, and the positions of the others are skipped.
+	Position int
+}
+
+// MarshalWithMeta converts the struct, or pointer-to-struct, src into a map of
+// Fields, each pairing the value Marshal would have produced with metadata
+// describing the field it came from. Keys, and the rules for which fields are
+// omitted, are the same as those of Marshal.
+//
+// Only the struct's own fields, and those promoted from its embedded structs,
+// are returned. Entries Marshal would add that don't come from a single field
+// are not; the Computed and TypeFieldName options are ignored, as is
+// ExpandEmbeddedInterfaces, so embedded interfaces are returned as fields like
+// any other.
+func MarshalWithMeta(src interface{}) (map[string]Field, error) {
+	ret, err := defaultConfig.marshalWithMeta(src)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (cfg *Config) MarshalWithMeta(src interface{}) (map[string]Field, error) {
+	ret, err := cfg.marshalWithMeta(src)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (cfg *Config) marshalWithMeta(src interface{}) (m map[string]Field, err error) {
+	cfg = cfg.withEncodeFns()
+	srcv := reflect.ValueOf(src)
+	if srcv.Kind() == reflect.Ptr {
+		srcv = srcv.Elem()
+	}
+	if srcv.Kind() != reflect.Struct {
+		return nil, errors.New("src must be a struct, or pointer-to-struct")
+	}
+
+	// Any panics after this point should be converted to errors, and returned
+	// normally. Unless it's a runtime error, it's a raw string, or it's not of
+	// type `error`. In which case, do panic.
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(runtime.Error); ok {
+				panic(r)
+			} else if s, ok := r.(string); ok {
+				panic(s)
+			} else if e, ok := r.(error); !ok {
+				panic(r)
+			} else {
+				err = e
+			}
+		}
+	}()
+
+	t := srcv.Type()
+	fields := cachedTypeFields(t, cfg)
+	m = make(map[string]Field, len(fields))
+	pos := 0
+	for _, f := range fields {
+		k, ok := cfg.fieldKey(f)
+		if !ok {
+			continue
+		}
+		fv := fieldByIndex(srcv, f.index)
+		if !fv.IsValid() || cfg.omitField(f, fv) {
+			continue
+		}
+		sf := t.FieldByIndex(f.index)
+		var enc encodeFn
+		if f.options.Contains("value") {
+			enc = encodeInterface
+		} else {
+			enc = lookupEncodeFn(sf.Type, cfg)
+		}
+		v, ok := cfg.encodeField(k, fv, enc)
+		if !ok {
+			continue
+		}
+		m[k] = Field{
+			Value:      v,
+			GoType:     sf.Type.String(),
+			WasNil:     valueIsNil(fv),
+			SourceName: sf.Name,
+			Position:   pos,
+		}
+		pos++
+	}
+	return m, nil
+}
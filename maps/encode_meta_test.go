@@ -0,0 +1,151 @@
+package maps_test
+
+import (
+	"testing"
+
+	"github.com/pyrrho/encoding/maps"
+	"github.com/stretchr/testify/require"
+)
+
+type MetaForm struct {
+	Title   string      `map:"title"`
+	Count   NilableInt  `map:"count"`
+	Parent  *MetaForm   `map:"parent"`
+	Tags    []string    `map:"tags"`
+	Skipped string      `map:"-"`
+	Maybe   *int        `map:"maybe,omitNil"`
+	Any     interface{} `map:"any"`
+	Deeper              // embedded
+}
+
+func TestMarshalWithMeta(t *testing.T) {
+	require := require.New(t)
+
+	src := MetaForm{
+		Title:  "form",
+		Count:  NilableInt{},
+		Deeper: Deeper{Exported: 2},
+	}
+	actual, err := maps.MarshalWithMeta(&src)
+	require.NoError(err)
+	require.Equal(map[string]maps.Field{
+		"title": {
+			Value:      "form",
+			GoType:     "string",
+			SourceName: "Title",
+			Position:   0,
+		},
+		"count": {
+			Value:      nil,
+			GoType:     "maps_test.NilableInt",
+			WasNil:     true,
+			SourceName: "Count",
+			Position:   1,
+		},
+		"parent": {
+			Value:      (*MetaForm)(nil),
+			GoType:     "*maps_test.MetaForm",
+			WasNil:     true,
+			SourceName: "Parent",
+			Position:   2,
+		},
+		"tags": {
+			Value:      []string(nil),
+			GoType:     "[]string",
+			WasNil:     true,
+			SourceName: "Tags",
+			Position:   3,
+		},
+		"any": {
+			Value:      nil,
+			GoType:     "interface {}",
+			WasNil:     true,
+			SourceName: "Any",
+			Position:   4,
+		},
+		"Exported": {
+			Value:      2,
+			GoType:     "int",
+			SourceName: "Exported",
+			Position:   5,
+		},
+	}, actual)
+
+	// Values match those produced by Marshal.
+	i := 7
+	src.Count = NilableInt{42, true}
+	src.Parent = &MetaForm{Title: "parent"}
+	src.Tags = []string{"a"}
+	src.Maybe = &i
+	src.Any = 1.5
+	values, err := maps.Marshal(&src)
+	require.NoError(err)
+	actual, err = maps.MarshalWithMeta(src)
+	require.NoError(err)
+	require.Len(actual, len(values))
+	for k, v := range values {
+		require.Equal(v, actual[k].Value, k)
+		require.False(actual[k].WasNil, k)
+	}
+	require.Equal("*int", actual["maybe"].GoType)
+	require.Equal("Maybe", actual["maybe"].SourceName)
+}
+
+func TestMarshalWithMetaErrors(t *testing.T) {
+	require := require.New(t)
+
+	_, err := maps.MarshalWithMeta(map[string]int{"a": 1})
+	require.Error(err)
+
+	_, err = maps.MarshalWithMeta(42)
+	require.Error(err)
+
+	// Encoding errors are reported the same way Marshal reports them.
+	cfg := &maps.Config{TagName: "map", RejectJSONIncompatible: true}
+	_, err = cfg.MarshalWithMeta(struct{ C chan int }{make(chan int)})
+	require.Error(err)
+}
+
+func TestMarshalWithMetaNilersAsNil(t *testing.T) {
+	require := require.New(t)
+
+	// Values are those Marshal produces, with the same Config.
+	cfg := &maps.Config{TagName: "map", NilersAsNil: true}
+	src := &ExplicitNils{Iface: NilableInt{}}
+	expected, err := cfg.Marshal(src)
+	require.NoError(err)
+	actual, err := cfg.MarshalWithMeta(src)
+	require.NoError(err)
+	require.Len(actual, len(expected))
+	for k, v := range expected {
+		require.Equal(v, actual[k].Value, k)
+	}
+	require.Nil(actual["Struct"].Value)
+	require.True(actual["Struct"].WasNil)
+}
+
+func TestMarshalWithMetaKeyAliasCollision(t *testing.T) {
+	require := require.New(t)
+
+	type Colliding struct {
+		A int `map:"a"`
+		B int `map:"b"`
+		C int `map:"c"`
+		D int `map:"d"`
+	}
+	cfg := &maps.Config{
+		TagName:    "map",
+		KeyAliases: map[string]string{"b": "x", "c": "x"},
+	}
+	actual, err := cfg.MarshalWithMeta(Colliding{1, 2, 3, 4})
+	require.NoError(err)
+	require.Len(actual, 3)
+
+	// The later field wins, as it does in Marshal, and positions stay unique
+	// and in declaration order.
+	require.Equal(3, actual["x"].Value)
+	require.Equal("C", actual["x"].SourceName)
+	require.Equal(0, actual["a"].Position)
+	require.Equal(2, actual["x"].Position)
+	require.Equal(3, actual["d"].Position)
+}
@@ -0,0 +1,147 @@
+package maps
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strconv"
+)
+
+// MarshalStrings converts the struct, or pointer-to-struct, src into a
+// map[string]string suitable for building URL query parameters or
+// x-www-form-urlencoded bodies. Field names and omission rules are the same as
+// those of Marshal.
+//
+// Each field is converted to a string by the first of the following that
+// applies; its Marshaler's MarshalMapValue, its encoding.TextMarshaler, its
+// fmt.Stringer, or the strconv formatting of its string, bool, integer, or
+// float value. Fields that can't be flattened this way -- nested structs,
+// slices, maps, and the like -- will result in an error. Fields that are, or
+// that marshal to, nil will be left out of the returned map.
+func MarshalStrings(src interface{}) (map[string]string, error) {
+	ret, err := defaultConfig.marshalStrings(src)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (cfg *Config) MarshalStrings(src interface{}) (map[string]string, error) {
+	ret, err := cfg.marshalStrings(src)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+var stringerType = reflect.TypeOf(new(fmt.Stringer)).Elem()
+
+func (cfg *Config) marshalStrings(src interface{}) (m map[string]string, err error) {
+	srcv := reflect.ValueOf(src)
+	if srcv.Kind() == reflect.Ptr {
+		srcv = srcv.Elem()
+	}
+	if srcv.Kind() != reflect.Struct {
+		return nil, errors.New("src must be a struct, or pointer-to-struct")
+	}
+
+	// Any panics after this point should be converted to errors, and returned
+	// normally. Unless it's a runtime error, it's a raw string, or it's not of
+	// type `error`. In which case, do panic.
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(runtime.Error); ok {
+				panic(r)
+			} else if s, ok := r.(string); ok {
+				panic(s)
+			} else if e, ok := r.(error); !ok {
+				panic(r)
+			} else {
+				err = e
+			}
+		}
+	}()
+
+	fields := cachedTypeFields(srcv.Type(), cfg)
+	m = make(map[string]string, len(fields))
+	for _, f := range fields {
+		k, ok := cfg.fieldKey(f)
+		if !ok {
+			continue
+		}
+		fv := fieldByIndex(srcv, f.index)
+		if !fv.IsValid() || cfg.omitField(f, fv) {
+			continue
+		}
+		s, ok, err := stringifyValue(fv, true)
+		if err != nil {
+			return nil, fmt.Errorf("maps: cannot convert field %s to a string: %v", f.name, err)
+		}
+		if ok && !cfg.omitEntry(k, s) {
+			m[k] = s
+		}
+	}
+	return m, nil
+}
+
+// stringifyValue converts v into a string. If v is nil, or marshals to nil, ok
+// will be false. The Marshaler interface will only be consulted if
+// useMarshaler is true, to prevent a MarshalMapValue that returns its receiver
+// from recursing forever.
+func stringifyValue(v reflect.Value, useMarshaler bool) (s string, ok bool, err error) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", false, nil
+		}
+		if useMarshaler && v.Type().Implements(marshalerType) {
+			break
+		}
+		v = v.Elem()
+	}
+	t := v.Type()
+	iface := v.Interface()
+	if !(t.Implements(marshalerType) || t.Implements(textMarshalerType) || t.Implements(stringerType)) && v.CanAddr() {
+		iface = v.Addr().Interface()
+	}
+
+	if m, isMarshaler := iface.(Marshaler); isMarshaler && useMarshaler {
+		mv, err := m.MarshalMapValue()
+		if errors.Is(err, ErrSkipField) {
+			return "", false, nil
+		}
+		if err != nil {
+			return "", false, err
+		}
+		if mv == nil {
+			return "", false, nil
+		}
+		return stringifyValue(reflect.ValueOf(mv), false)
+	}
+	if tm, isTextMarshaler := iface.(textMarshaler); isTextMarshaler {
+		b, err := tm.MarshalText()
+		if err != nil {
+			return "", false, err
+		}
+		return string(b), true, nil
+	}
+	if st, isStringer := iface.(fmt.Stringer); isStringer {
+		return st.String(), true, nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), true, nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), true, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), true, nil
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 32), true, nil
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64), true, nil
+	}
+	return "", false, fmt.Errorf("values of type %s cannot be flattened", t)
+}
@@ -0,0 +1,126 @@
+package maps_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pyrrho/encoding/maps"
+	"github.com/stretchr/testify/require"
+)
+
+type Weekday int
+
+func (d Weekday) String() string {
+	return time.Weekday(d).String()
+}
+
+type QueryParams struct {
+	Name     string
+	Page     int     `map:"page"`
+	Limit    uint8   `map:"limit,omitZero"`
+	Ratio    float64 `map:"ratio"`
+	Exact    bool    `map:"exact"`
+	Day      Weekday `map:"day"`
+	Key      TextKey `map:"key"`
+	Since    *time.Time
+	Cursor   *string     `map:"cursor,omitNil"`
+	Count    NilableInt  `map:"count"`
+	Ignored  []int       `map:"-"`
+	Anything interface{} `map:"any"`
+}
+
+func TestMarshalStrings(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		err              error
+		actual, expected map[string]string
+	)
+
+	since := time.Date(2017, 4, 1, 12, 30, 0, 0, time.UTC)
+	s := QueryParams{
+		Name:     "Hello World",
+		Page:     -2,
+		Ratio:    0.25,
+		Exact:    true,
+		Day:      Weekday(time.Tuesday),
+		Key:      TextKey{1, 2},
+		Since:    &since,
+		Count:    NilableInt{Int: 42, Valid: true},
+		Ignored:  []int{1, 2, 3},
+		Anything: 3.5,
+	}
+	expected = map[string]string{
+		"Name":  "Hello World",
+		"page":  "-2",
+		"ratio": "0.25",
+		"exact": "true",
+		"day":   "Tuesday",
+		"key":   "1-2",
+		"Since": "2017-04-01T12:30:00Z",
+		"count": "42",
+		"any":   "3.5",
+	}
+
+	actual, err = maps.MarshalStrings(s)
+	require.NoError(err)
+	require.Equal(expected, actual)
+
+	actual, err = maps.MarshalStrings(&s)
+	require.NoError(err)
+	require.Equal(expected, actual)
+
+	// Nil pointers, nil interfaces, and Marshalers that return nil are left out
+	// of the map.
+	cursor := "abc"
+	s = QueryParams{
+		Limit:  10,
+		Cursor: &cursor,
+	}
+	expected = map[string]string{
+		"Name":   "",
+		"page":   "0",
+		"limit":  "10",
+		"ratio":  "0",
+		"exact":  "false",
+		"day":    "Sunday",
+		"key":    "0-0",
+		"cursor": "abc",
+	}
+
+	actual, err = maps.MarshalStrings(s)
+	require.NoError(err)
+	require.Equal(expected, actual)
+
+	// Config tags and omit options are respected.
+	actual, err = (&maps.Config{TagName: "map_key"}).MarshalStrings(&struct {
+		FieldOne int `map_key:"field_one"`
+		FieldTwo int `map_key:"field_two,omitZero"`
+	}{FieldOne: 42})
+	require.NoError(err)
+	require.Equal(map[string]string{"field_one": "42"}, actual)
+
+	actual, err = (&maps.Config{TagName: "map", OmitNilers: true}).MarshalStrings(&PossiblyNilers{
+		TaggedZero: NilableInt{1, true},
+		Untagged:   NilableInt{},
+	})
+	require.NoError(err)
+	require.Equal(map[string]string{"TaggedZero": "1"}, actual)
+}
+
+func TestMarshalStringsErrors(t *testing.T) {
+	require := require.New(t)
+	var err error
+
+	_, err = maps.MarshalStrings(map[string]int{})
+	require.Error(err)
+
+	_, err = maps.MarshalStrings(&ParentStruct{})
+	require.Error(err)
+
+	_, err = maps.MarshalStrings(&struct{ S []int }{})
+	require.Error(err)
+
+	_, err = maps.MarshalStrings(&MarahalerParent{})
+	require.Error(err)
+}
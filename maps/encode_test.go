@@ -1,8 +1,15 @@
 package maps_test
 
 import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/pyrrho/encoding"
 	"github.com/pyrrho/encoding/maps"
 	"github.com/stretchr/testify/require"
 )
@@ -103,6 +110,24 @@ func TestSimpleUntaggedStructSlice(t *testing.T) {
 	actual, err = maps.MarshalSlice(si)
 	require.NoError(err)
 	require.Equal(expected, actual)
+
+	// Slices of pointers-to-struct are dereferenced element by element, as are
+	// interface{}s holding pointers. Nil elements become nil maps ...
+	spp := []*SimpleStruct{&s[0], nil, &s[1]}
+	expectedWithNil := []map[string]interface{}{expected[0], nil, expected[1]}
+	actual, err = maps.MarshalSlice(spp)
+	require.NoError(err)
+	require.Equal(expectedWithNil, actual)
+
+	actual, err = maps.MarshalSlice([]interface{}{&s[0], nil, &s[1]})
+	require.NoError(err)
+	require.Equal(expectedWithNil, actual)
+
+	// ... unless SkipNilSliceElements is set.
+	cfg := &maps.Config{TagName: "map", SkipNilSliceElements: true}
+	actual, err = cfg.MarshalSlice(&spp)
+	require.NoError(err)
+	require.Equal(expected, actual)
 }
 
 type SimpleStructWithInterface struct {
@@ -310,6 +335,149 @@ func TestContendingEmbeddedStructs(t *testing.T) {
 	require.Equal(expected, actual)
 }
 
+type EmbedDeep struct {
+	Deep   int
+	Shared int
+}
+
+type EmbedOther struct {
+	Shared int
+	Other  int
+}
+
+type embedUnexported struct {
+	Exported   int
+	unexported int
+	EmbedDeep  // embedded, reached through an unexported embed
+}
+
+type embedUnexportedPtr struct {
+	Exported int
+}
+
+type EmbedWrapper struct {
+	EmbedDeep // embedded
+}
+
+type EmbedWrapperToo struct {
+	EmbedDeep // embedded
+}
+
+// Shared is promoted from EmbedOther, as it's shallower than the Shared
+// reached through embedUnexported.
+type MatrixShallowest struct {
+	embedUnexported
+	EmbedOther
+}
+
+// Exported is ambiguous at depth two, and is not visible.
+type MatrixAmbiguousUnexported struct {
+	embedUnexported
+	*embedUnexportedPtr
+}
+
+// Shared is ambiguous at depth two, and is not visible.
+type MatrixAmbiguous struct {
+	EmbedDeep
+	EmbedOther
+}
+
+// A top-level field dominates an ambiguity at a greater depth.
+type MatrixDominated struct {
+	MatrixAmbiguous
+	Shared int
+}
+
+// The fields of EmbedDeep are reached at depths two and three; the shallower
+// copy wins.
+type MatrixRepeated struct {
+	EmbedDeep
+	EmbedWrapper
+}
+
+// The fields of EmbedDeep are reached twice at depth three, and are all
+// ambiguous.
+type MatrixRepeatedAmbiguous struct {
+	EmbedWrapper
+	EmbedWrapperToo
+	Own int
+}
+
+// visibleFieldNames returns the names of the fields of t that Go considers to
+// be selectable and exported, excluding embedded structs themselves.
+func visibleFieldNames(t reflect.Type) []string {
+	var ret []string
+	for _, f := range reflect.VisibleFields(t) {
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && ft.Kind() == reflect.Struct {
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		ret = append(ret, f.Name)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func TestEmbeddedPromotionMatchesGo(t *testing.T) {
+	require := require.New(t)
+
+	cases := []struct {
+		src      interface{}
+		expected map[string]interface{}
+	}{
+		{
+			MatrixShallowest{
+				embedUnexported{1, 2, EmbedDeep{3, 4}},
+				EmbedOther{5, 6},
+			},
+			map[string]interface{}{"Exported": 1, "Deep": 3, "Shared": 5, "Other": 6},
+		},
+		{
+			MatrixAmbiguousUnexported{
+				embedUnexported{1, 2, EmbedDeep{3, 4}},
+				&embedUnexportedPtr{5},
+			},
+			map[string]interface{}{"Deep": 3, "Shared": 4},
+		},
+		{
+			MatrixAmbiguous{EmbedDeep{1, 2}, EmbedOther{3, 4}},
+			map[string]interface{}{"Deep": 1, "Other": 4},
+		},
+		{
+			MatrixDominated{MatrixAmbiguous{EmbedDeep{1, 2}, EmbedOther{3, 4}}, 5},
+			map[string]interface{}{"Deep": 1, "Other": 4, "Shared": 5},
+		},
+		{
+			MatrixRepeated{EmbedDeep{1, 2}, EmbedWrapper{EmbedDeep{3, 4}}},
+			map[string]interface{}{"Deep": 1, "Shared": 2},
+		},
+		{
+			MatrixRepeatedAmbiguous{EmbedWrapper{EmbedDeep{1, 2}}, EmbedWrapperToo{EmbedDeep{3, 4}}, 5},
+			map[string]interface{}{"Own": 5},
+		},
+	}
+	for _, c := range cases {
+		name := reflect.TypeOf(c.src).Name()
+		actual, err := maps.Marshal(c.src)
+		require.NoError(err, name)
+		require.Equal(c.expected, actual, name)
+
+		// Every case should agree with reflect's view of the struct.
+		var keys []string
+		for k := range actual {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		require.Equal(visibleFieldNames(reflect.TypeOf(c.src)), keys, name)
+	}
+}
+
 type MarahalerParent struct {
 	AnInt            int
 	AnArrayIshStruct MarshalerImplementor
@@ -352,6 +520,58 @@ func TestMarshalerInterface(t *testing.T) {
 	require.Equal(expected, actual)
 }
 
+type MarshalerContainers struct {
+	Slice    []MarshalerImplementor
+	Array    [2]MarshalerImplementor
+	Ptrs     []*NilableInt
+	Map      map[string]MarshalerImplementor
+	IntKeys  map[int]NilableInt
+	Nested   [][]NilableInt
+	NilSlice []NilableInt
+	NilMap   map[string]NilableInt
+	Plain    []int
+	Structs  []NestedStruct
+}
+
+func TestMarshalerInContainers(t *testing.T) {
+	require := require.New(t)
+
+	mi := func(c int) MarshalerImplementor { return MarshalerImplementor{[3]int{1, 2, 3}, c} }
+	mv := func(c int) map[string]int { return map[string]int{"Arr0": 1 + c, "Arr1": 2 + c, "Arr2": 3 + c} }
+	s := &MarshalerContainers{
+		Slice:   []MarshalerImplementor{mi(0), mi(10)},
+		Array:   [2]MarshalerImplementor{mi(20), mi(30)},
+		Ptrs:    []*NilableInt{{1, true}, nil, {}},
+		Map:     map[string]MarshalerImplementor{"a": mi(40)},
+		IntKeys: map[int]NilableInt{1: {5, true}, 2: {}},
+		Nested:  [][]NilableInt{{{6, true}}, {}, nil},
+		Plain:   []int{1, 2},
+		Structs: []NestedStruct{{1, 2.3}},
+	}
+	expected := map[string]interface{}{
+		"Slice":    []interface{}{mv(0), mv(10)},
+		"Array":    []interface{}{mv(20), mv(30)},
+		"Ptrs":     []interface{}{1, nil, nil},
+		"Map":      map[string]interface{}{"a": mv(40)},
+		"IntKeys":  map[string]interface{}{"1": 5, "2": nil},
+		"Nested":   []interface{}{[]interface{}{6}, []interface{}{}, nil},
+		"NilSlice": nil,
+		"NilMap":   nil,
+		// Containers of non-Marshalers are kept as-is.
+		"Plain":   []int{1, 2},
+		"Structs": []NestedStruct{{1, 2.3}},
+	}
+
+	actual, err := maps.Marshal(s)
+	require.NoError(err)
+	require.Equal(expected, actual)
+
+	// Top-level map values are handled the same way.
+	actual, err = maps.Marshal(map[string][]NilableInt{"a": {{7, true}}})
+	require.NoError(err)
+	require.Equal(map[string]interface{}{"a": []interface{}{7}}, actual)
+}
+
 type DifferentTags struct {
 	FieldOne   int        `map_key:"field_one"`
 	FieldTwo   float64    `map_key:"field_two"`
@@ -385,6 +605,52 @@ func TestDifferentTags(t *testing.T) {
 	require.Equal(expected, actual)
 }
 
+type MixedTags struct {
+	MapTagged  int `map:"m" json:"j" db:"d"`
+	JSONTagged int `json:"j2,omitempty" db:"d2"`
+	DBTagged   int `db:"d3,omitZero"`
+	JSONSkip   int `json:"-" db:"d4"`
+	Untagged   int
+}
+
+func TestTagNames(t *testing.T) {
+	require := require.New(t)
+	s := &MixedTags{1, 2, 0, 4, 5}
+
+	actual, err := (&maps.Config{TagNames: []string{"map", "json", "db"}}).Marshal(s)
+	require.NoError(err)
+	require.Equal(map[string]interface{}{
+		"m":        1,
+		"j2":       2,
+		"Untagged": 5,
+	}, actual)
+
+	// TagName is consulted before TagNames.
+	actual, err = (&maps.Config{TagName: "db", TagNames: []string{"json"}}).Marshal(s)
+	require.NoError(err)
+	require.Equal(map[string]interface{}{
+		"d":        1,
+		"d2":       2,
+		"d4":       4,
+		"Untagged": 5,
+	}, actual)
+
+	// The same type may be marshalled with different tags; and an empty
+	// Config consults no tag at all, as it always has.
+	actual, err = (&maps.Config{TagName: "json"}).Marshal(s)
+	require.NoError(err)
+	require.Equal(map[string]interface{}{"j": 1, "j2": 2, "DBTagged": 0, "Untagged": 5}, actual)
+	actual, err = (&maps.Config{}).Marshal(s)
+	require.NoError(err)
+	require.Equal(map[string]interface{}{
+		"MapTagged":  1,
+		"JSONTagged": 2,
+		"DBTagged":   0,
+		"JSONSkip":   4,
+		"Untagged":   5,
+	}, actual)
+}
+
 type PossiblyNotValues struct {
 	Int1  int  `map:",omitZero"`
 	Int2  int  `map:",omitZero"`
@@ -419,6 +685,71 @@ func TestOmitZeroNil(t *testing.T) {
 	require.Equal(expected, actual)
 }
 
+type PointersToZero struct {
+	Zero     *int       `map:",omitZero"`
+	NonZero  *int       `map:",omitZero"`
+	Nil      *int       `map:",omitZero"`
+	Double   **string   `map:",omitZero"`
+	Zeroer   *MaybeZero `map:",omitZero"`
+	Untagged *int
+}
+
+type MaybeZero struct {
+	N int
+}
+
+func (m MaybeZero) IsZero() bool { return m.N < 0 }
+
+func TestOmitZeroThroughPointers(t *testing.T) {
+	require := require.New(t)
+
+	zero, one, empty := 0, 1, ""
+	emptyp := &empty
+	src := PointersToZero{
+		Zero:     &zero,
+		NonZero:  &one,
+		Double:   &emptyp,
+		Zeroer:   &MaybeZero{N: -1},
+		Untagged: &zero,
+	}
+
+	// By default, only nil pointers are zero, unless the pointer type itself
+	// implements IsZeroer.
+	actual, err := maps.Marshal(src)
+	require.NoError(err)
+	require.Equal(map[string]interface{}{
+		"Zero":     &zero,
+		"NonZero":  &one,
+		"Double":   &emptyp,
+		"Untagged": &zero,
+	}, actual)
+
+	cfg := &maps.Config{TagName: "map", OmitZeroThroughPointers: true}
+	actual, err = cfg.Marshal(src)
+	require.NoError(err)
+	require.Equal(map[string]interface{}{"NonZero": &one, "Untagged": &zero}, actual)
+
+	src.Zeroer = &MaybeZero{N: 0}
+	actual, err = cfg.Marshal(src)
+	require.NoError(err)
+	require.Contains(actual, "Zeroer")
+}
+
+type JSONSpelledOmits struct {
+	Empty string `json:"empty,omitempty"`
+	Zero  int    `json:"zero,omitzero"`
+	Nil   *int   `json:"nil,omitnil"`
+	Kept  string `json:"kept,omitempty"`
+}
+
+func TestOmitOptionSpellings(t *testing.T) {
+	require := require.New(t)
+
+	actual, err := (&maps.Config{TagName: "json"}).Marshal(JSONSpelledOmits{Kept: "k"})
+	require.NoError(err)
+	require.Equal(map[string]interface{}{"kept": "k"}, actual)
+}
+
 type AsValueParent struct {
 	Tagged     TaggedAsValueChild `map:",value"`
 	Interfaced MarshalerAsValueChild
@@ -470,3 +801,1303 @@ func TestStructsAsValue(t *testing.T) {
 	require.NoError(err)
 	require.Equal(expected, actual)
 }
+
+type CountingMarshaler struct {
+	Calls *int
+}
+
+func (c CountingMarshaler) MarshalMapValue() (interface{}, error) {
+	*c.Calls++
+	return "marshalled", nil
+}
+
+type ValueOverMarshaler struct {
+	AsValue   CountingMarshaler  `map:",value"`
+	AsValueP  *CountingMarshaler `map:",value"`
+	Marshaled CountingMarshaler
+}
+
+func TestValueTagOverridesMarshaler(t *testing.T) {
+	require := require.New(t)
+
+	valueCalls, marshalCalls := 0, 0
+	s := ValueOverMarshaler{
+		AsValue:   CountingMarshaler{&valueCalls},
+		AsValueP:  &CountingMarshaler{&valueCalls},
+		Marshaled: CountingMarshaler{&marshalCalls},
+	}
+	expected := map[string]interface{}{
+		"AsValue":   s.AsValue,
+		"AsValueP":  s.AsValueP,
+		"Marshaled": "marshalled",
+	}
+
+	// The precedence holds for both values and pointers, and through the
+	// metadata encoder.
+	for _, src := range []interface{}{s, &s} {
+		actual, err := maps.Marshal(src)
+		require.NoError(err)
+		require.Equal(expected, actual)
+
+		fields, err := maps.MarshalWithMeta(src)
+		require.NoError(err)
+		require.Equal(s.AsValue, fields["AsValue"].Value)
+		require.Equal("marshalled", fields["Marshaled"].Value)
+	}
+	require.Equal(0, valueCalls)
+	require.Equal(4, marshalCalls)
+}
+
+type NilableInt struct {
+	Int   int
+	Valid bool
+}
+
+func (n NilableInt) IsNil() bool {
+	return !n.Valid
+}
+
+func (n NilableInt) IsZero() bool {
+	return !n.Valid || n.Int == 0
+}
+
+func (n NilableInt) MarshalMapValue() (interface{}, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.Int, nil
+}
+
+type PossiblyNilers struct {
+	Tagged     NilableInt  `map:",omitNil"`
+	TaggedZero NilableInt  `map:",omitZero"`
+	Untagged   NilableInt  ``
+	Pointer    *NilableInt `map:",omitNil"`
+	Iface      interface{} `map:",omitNil"`
+}
+
+func TestOmitNilersZeroers(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		err              error
+		actual, expected map[string]interface{}
+	)
+
+	// The "omitNil" and "omitZero" options consult the IsNiler and IsZeroer
+	// interfaces, when they're available.
+	s := &PossiblyNilers{
+		Tagged:     NilableInt{},
+		TaggedZero: NilableInt{0, true},
+		Untagged:   NilableInt{},
+		Pointer:    nil,
+		Iface:      NilableInt{},
+	}
+	expected = map[string]interface{}{
+		"Untagged": nil,
+	}
+	actual, err = maps.Marshal(s)
+	require.NoError(err)
+	require.Equal(expected, actual)
+
+	// Valid values are kept, but a non-nil pointer to a nil IsNiler is nil.
+	s = &PossiblyNilers{
+		Tagged:     NilableInt{0, true},
+		TaggedZero: NilableInt{1, true},
+		Untagged:   NilableInt{2, true},
+		Pointer:    &NilableInt{},
+		Iface:      NilableInt{3, true},
+	}
+	expected = map[string]interface{}{
+		"Tagged":     0,
+		"TaggedZero": 1,
+		"Untagged":   2,
+		"Iface":      NilableInt{3, true},
+	}
+	actual, err = maps.Marshal(s)
+	require.NoError(err)
+	require.Equal(expected, actual)
+
+	// Config.OmitNilers applies "omitNil" to every IsNiler, tagged or not.
+	s = &PossiblyNilers{
+		Tagged:     NilableInt{0, true},
+		TaggedZero: NilableInt{0, true},
+		Untagged:   NilableInt{},
+		Iface:      NilableInt{3, true},
+	}
+	expected = map[string]interface{}{
+		"Tagged": 0,
+		"Iface":  NilableInt{3, true},
+	}
+	actual, err = (&maps.Config{
+		TagName:    "map",
+		OmitNilers: true,
+	}).Marshal(s)
+	require.NoError(err)
+	require.Equal(expected, actual)
+
+	// Config.OmitZeroers does the same for "omitZero".
+	expected = map[string]interface{}{
+		"Iface": NilableInt{3, true},
+	}
+	actual, err = (&maps.Config{
+		TagName:     "map",
+		OmitZeroers: true,
+	}).Marshal(s)
+	require.NoError(err)
+	require.Equal(expected, actual)
+}
+
+type PossiblyEmpty struct {
+	Int       int             `map:",omitEmpty"`
+	String    string          `map:",omitEmpty"`
+	Slice     []int           `map:",omitEmpty"`
+	Map       map[string]int  `map:",omitEmpty"`
+	Pointer   *int            `map:",omitEmpty"`
+	Struct    struct{ A int } `map:",omitEmpty"`
+	Zeroer    NilableInt      `map:",omitEmpty"`
+	ZeroerPtr *NilableInt     `map:",omitEmpty"`
+}
+
+func TestOmitEmpty(t *testing.T) {
+	require := require.New(t)
+
+	// Like encoding/json's "omitempty", "omitEmpty" drops empty scalars and
+	// containers, and nil pointers, but never plain structs. IsZeroers are
+	// dropped if IsZero() returns true.
+	actual, err := maps.Marshal(&PossiblyEmpty{
+		Slice:     []int{},
+		Zeroer:    NilableInt{0, true},
+		ZeroerPtr: &NilableInt{},
+	})
+	require.NoError(err)
+	require.Equal(map[string]interface{}{
+		"Struct": map[string]interface{}{"A": 0},
+	}, actual)
+
+	zero := 0
+	actual, err = maps.Marshal(&PossiblyEmpty{
+		Int:       -1,
+		String:    " ",
+		Slice:     []int{0},
+		Map:       map[string]int{"": 0},
+		Pointer:   &zero,
+		Zeroer:    NilableInt{1, true},
+		ZeroerPtr: &NilableInt{2, true},
+	})
+	require.NoError(err)
+	require.Equal(map[string]interface{}{
+		"Int":       -1,
+		"String":    " ",
+		"Slice":     []int{0},
+		"Map":       map[string]int{"": 0},
+		"Pointer":   &zero,
+		"Struct":    map[string]interface{}{"A": 0},
+		"Zeroer":    1,
+		"ZeroerPtr": 2,
+	}, actual)
+}
+
+type PointerNilableInt struct {
+	Int   int
+	Valid bool
+}
+
+func (n *PointerNilableInt) IsNil() bool {
+	return !n.Valid
+}
+
+type PointerNilers struct {
+	Value PointerNilableInt `map:",omitNil"`
+}
+
+func TestOmitPointerReceiverNilers(t *testing.T) {
+	require := require.New(t)
+
+	s := &PointerNilers{PointerNilableInt{}}
+	actual, err := maps.Marshal(s)
+	require.NoError(err)
+	require.Equal(map[string]interface{}{}, actual)
+
+	s = &PointerNilers{PointerNilableInt{0, true}}
+	actual, err = maps.Marshal(s)
+	require.NoError(err)
+	require.Equal(map[string]interface{}{
+		"Value": map[string]interface{}{"Int": 0, "Valid": true},
+	}, actual)
+}
+
+type TextKey struct {
+	A, B int
+}
+
+func (k TextKey) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%d-%d", k.A, k.B)), nil
+}
+
+func TestTopLevelMaps(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		err              error
+		actual, expected map[string]interface{}
+	)
+
+	// Struct values -- directly, by pointer, or in an interface{} -- are
+	// marshalled. All other values are passed through.
+	ns := NestedStruct{5, 6.7}
+	m := map[string]interface{}{
+		"Struct":    ns,
+		"StructPtr": &ns,
+		"Int":       42,
+		"Nil":       nil,
+		"Marshaler": MarshalerImplementor{[3]int{1, 2, 3}, 10},
+	}
+	nested := map[string]interface{}{
+		"AnInt":  5,
+		"AFloat": 6.7,
+	}
+	expected = map[string]interface{}{
+		"Struct":    nested,
+		"StructPtr": nested,
+		"Int":       42,
+		"Nil":       nil,
+		"Marshaler": map[string]int{
+			"Arr0": 11,
+			"Arr1": 12,
+			"Arr2": 13,
+		},
+	}
+	actual, err = maps.Marshal(m)
+	require.NoError(err)
+	require.Equal(expected, actual)
+	actual, err = maps.Marshal(&m)
+	require.NoError(err)
+	require.Equal(expected, actual)
+
+	// Keys are converted to strings following encoding/json's rules.
+	actual, err = maps.Marshal(map[int]NestedStruct{1: ns})
+	require.NoError(err)
+	require.Equal(map[string]interface{}{"1": nested}, actual)
+
+	actual, err = maps.Marshal(map[TextKey]int{{1, 2}: 3})
+	require.NoError(err)
+	require.Equal(map[string]interface{}{"1-2": 3}, actual)
+
+	_, err = maps.Marshal(map[float64]int{1.5: 2})
+	require.Error(err)
+
+	_, err = maps.Marshal(42)
+	require.Error(err)
+}
+
+func TestSliceOfMaps(t *testing.T) {
+	require := require.New(t)
+
+	s := []interface{}{
+		map[string]NestedStruct{"Nested": {5, 6.7}},
+		NestedStruct{8, 9.1},
+	}
+	expected := []map[string]interface{}{
+		{
+			"Nested": map[string]interface{}{
+				"AnInt":  5,
+				"AFloat": 6.7,
+			},
+		},
+		{
+			"AnInt":  8,
+			"AFloat": 9.1,
+		},
+	}
+
+	actual, err := maps.MarshalSlice(s)
+	require.NoError(err)
+	require.Equal(expected, actual)
+
+	_, err = maps.MarshalSlice([]int{1, 2, 3})
+	require.Error(err)
+}
+
+type JSONIncompatibleParent struct {
+	AnInt   int
+	Nested  JSONIncompatibleChild
+	Nothing func()
+}
+
+type JSONIncompatibleChild struct {
+	Values []interface{}
+}
+
+func TestRejectJSONIncompatible(t *testing.T) {
+	require := require.New(t)
+	var err error
+
+	cfg := &maps.Config{
+		TagName:                "map",
+		RejectJSONIncompatible: true,
+	}
+
+	// By default, JSON-incompatible values are kept verbatim.
+	_, err = maps.Marshal(&SimpleStruct{42, 3.14, "Hello World", complex(1, 2)})
+	require.NoError(err)
+
+	_, err = cfg.Marshal(&SimpleStruct{42, 3.14, "Hello World", complex(1, 2)})
+	require.Error(err)
+	require.Contains(err.Error(), "FieldFour")
+	require.Contains(err.Error(), "complex128")
+
+	_, err = cfg.Marshal(&SimpleStructWithInterface{})
+	require.NoError(err)
+
+	// Incompatible values are found within nested structs, slices, and
+	// interfaces. Functions are rejected even when nil.
+	_, err = cfg.Marshal(&JSONIncompatibleParent{
+		Nested: JSONIncompatibleChild{[]interface{}{1, "two", make(chan int)}},
+	})
+	require.Error(err)
+	require.Contains(err.Error(), "Values")
+	require.Contains(err.Error(), "chan int")
+
+	_, err = cfg.Marshal(&JSONIncompatibleParent{})
+	require.Error(err)
+	require.Contains(err.Error(), "Nothing")
+
+	// Maps with non-stringable keys are rejected, as are top-level map values.
+	_, err = cfg.Marshal(&struct{ M map[[2]int]int }{})
+	require.Error(err)
+	_, err = cfg.Marshal(map[string]interface{}{"c": complex64(1)})
+	require.Error(err)
+	require.Contains(err.Error(), "c")
+
+	// Compatible values pass through untouched.
+	actual, err := cfg.Marshal(&struct {
+		Bytes []byte
+		M     map[int][]string
+		P     *complex128
+	}{[]byte("hi"), map[int][]string{1: {"a"}}, nil})
+	require.NoError(err)
+	require.Equal(map[string]interface{}{
+		"Bytes": []byte("hi"),
+		"M":     map[int][]string{1: {"a"}},
+		"P":     (*complex128)(nil),
+	}, actual)
+}
+
+type RawMessages struct {
+	Raw      json.RawMessage
+	RawPtr   *json.RawMessage
+	Empty    json.RawMessage
+	Bytes    []byte
+	Fragment interface{}
+}
+
+func TestDecodeRawMessage(t *testing.T) {
+	require := require.New(t)
+	var (
+		err    error
+		actual map[string]interface{}
+	)
+
+	ptr := json.RawMessage(`[1,"two",null]`)
+	s := &RawMessages{
+		Raw:      json.RawMessage(`{"a":1,"b":[true]}`),
+		RawPtr:   &ptr,
+		Bytes:    []byte(`{"a":1}`),
+		Fragment: json.RawMessage(`"kept"`),
+	}
+
+	// By default, RawMessages are kept as raw bytes.
+	actual, err = maps.Marshal(s)
+	require.NoError(err)
+	require.Equal(json.RawMessage(`{"a":1,"b":[true]}`), actual["Raw"])
+	require.Equal(&ptr, actual["RawPtr"])
+
+	// With DecodeRawMessage, they're decoded. Plain []bytes, and RawMessages
+	// hidden behind an interface{}, are not.
+	cfg := &maps.Config{TagName: "map", DecodeRawMessage: true}
+	actual, err = cfg.Marshal(s)
+	require.NoError(err)
+	require.Equal(map[string]interface{}{
+		"Raw":      map[string]interface{}{"a": float64(1), "b": []interface{}{true}},
+		"RawPtr":   []interface{}{float64(1), "two", nil},
+		"Empty":    nil,
+		"Bytes":    []byte(`{"a":1}`),
+		"Fragment": json.RawMessage(`"kept"`),
+	}, actual)
+
+	s.RawPtr = nil
+	actual, err = cfg.Marshal(s)
+	require.NoError(err)
+	require.Nil(actual["RawPtr"])
+
+	s.Raw = json.RawMessage(`{"a":`)
+	_, err = cfg.Marshal(s)
+	require.Error(err)
+}
+
+type UserID int64
+type Ratio float32
+type Label string
+type Flag bool
+type Level uint8
+
+type StringerLevel uint8
+
+func (l StringerLevel) String() string {
+	return fmt.Sprintf("level-%d", uint8(l))
+}
+
+type NamedScalars struct {
+	ID       UserID
+	Ratio    Ratio
+	Label    Label
+	Flag     Flag
+	Level    Level
+	Stringer StringerLevel
+	Plain    int64
+	Iface    interface{}
+}
+
+func TestNormalizeNamedScalars(t *testing.T) {
+	require := require.New(t)
+	var (
+		err    error
+		actual map[string]interface{}
+	)
+
+	s := &NamedScalars{
+		ID:       42,
+		Ratio:    0.5,
+		Label:    "foo",
+		Flag:     true,
+		Level:    3,
+		Stringer: 4,
+		Plain:    5,
+		Iface:    UserID(6),
+	}
+
+	// By default, named types are kept.
+	actual, err = maps.Marshal(s)
+	require.NoError(err)
+	require.Equal(UserID(42), actual["ID"])
+	require.NotEqual(int64(42), actual["ID"])
+
+	actual, err = (&maps.Config{TagName: "map", NormalizeNamedScalars: true}).Marshal(s)
+	require.NoError(err)
+	require.Equal(map[string]interface{}{
+		"ID":       int64(42),
+		"Ratio":    float32(0.5),
+		"Label":    "foo",
+		"Flag":     true,
+		"Level":    uint8(3),
+		"Stringer": StringerLevel(4),
+		"Plain":    int64(5),
+		"Iface":    UserID(6),
+	}, actual)
+
+	// Top-level map values are normalized too.
+	actual, err = (&maps.Config{TagName: "map", NormalizeNamedScalars: true}).Marshal(map[string]Label{"a": "b"})
+	require.NoError(err)
+	require.Equal(map[string]interface{}{"a": "b"}, actual)
+}
+
+type KindMarshaled struct {
+	Complex   complex128
+	Named     NamedComplex
+	Marshaler NilableInt
+	Ptr       uintptr
+	Iface     interface{}
+}
+
+type NamedComplex complex128
+
+func TestKindMarshalers(t *testing.T) {
+	require := require.New(t)
+	var (
+		err    error
+		actual map[string]interface{}
+	)
+
+	cfg := &maps.Config{
+		TagName: "map",
+		KindMarshalers: map[reflect.Kind]func(reflect.Value) (interface{}, error){
+			reflect.Complex128: func(v reflect.Value) (interface{}, error) {
+				c := v.Complex()
+				return []float64{real(c), imag(c)}, nil
+			},
+			reflect.Uintptr: func(v reflect.Value) (interface{}, error) {
+				return fmt.Sprintf("0x%x", v.Uint()), nil
+			},
+			// Types implementing Marshaler take precedence.
+			reflect.Struct: func(v reflect.Value) (interface{}, error) {
+				return "struct", nil
+			},
+		},
+	}
+	s := &KindMarshaled{
+		Complex:   complex(1, 2),
+		Named:     NamedComplex(complex(3, 4)),
+		Marshaler: NilableInt{5, true},
+		Ptr:       0xff,
+		Iface:     complex(6, 7),
+	}
+	actual, err = cfg.Marshal(s)
+	require.NoError(err)
+	require.Equal(map[string]interface{}{
+		"Complex":   []float64{1, 2},
+		"Named":     []float64{3, 4},
+		"Marshaler": 5,
+		"Ptr":       "0xff",
+		"Iface":     complex(6, 7),
+	}, actual)
+
+	// Top-level values are structs, and aren't affected; nested ones are.
+	actual, err = cfg.Marshal(&ParentStruct{AStruct: NestedStruct{1, 2}})
+	require.NoError(err)
+	require.Equal("struct", actual["AStruct"])
+
+	// Errors are returned from Marshal.
+	cfg = &maps.Config{
+		TagName: "map",
+		KindMarshalers: map[reflect.Kind]func(reflect.Value) (interface{}, error){
+			reflect.Complex128: func(v reflect.Value) (interface{}, error) {
+				return nil, fmt.Errorf("no complex numbers")
+			},
+		},
+	}
+	_, err = cfg.Marshal(s)
+	require.EqualError(err, "no complex numbers")
+
+	// Configs with different KindMarshalers don't share cached encoders.
+	actual, err = maps.Marshal(s)
+	require.NoError(err)
+	require.Equal(complex(1, 2), actual["Complex"])
+}
+
+type KindMarshaledOuter struct {
+	Value complex128         `map:"value"`
+	Inner KindMarshaledInner `map:"inner"`
+}
+
+type KindMarshaledInner struct {
+	Value complex128 `map:"value"`
+}
+
+func TestKindMarshalersCaching(t *testing.T) {
+	require := require.New(t)
+
+	kms := map[reflect.Kind]func(reflect.Value) (interface{}, error){
+		reflect.Complex128: func(v reflect.Value) (interface{}, error) {
+			return real(v.Complex()), nil
+		},
+	}
+	src := KindMarshaledOuter{Value: 1 + 3i, Inner: KindMarshaledInner{Value: 2 + 4i}}
+	expected := map[string]interface{}{
+		"value": 1.0,
+		"inner": map[string]interface{}{"value": 2.0},
+	}
+
+	cfg := &maps.Config{TagName: "map", KindMarshalers: kms}
+	actual, err := cfg.Marshal(src)
+	require.NoError(err)
+	require.Equal(expected, actual)
+
+	enc := maps.NewEncoder(cfg)
+	for i := 0; i < 2; i++ {
+		actual, err = enc.Encode(src)
+		require.NoError(err)
+		require.Equal(expected, actual)
+	}
+
+	// Encoders built for KindMarshalers aren't cached by the identity of the
+	// map, and so aren't reused by later calls.
+	kms[reflect.Complex128] = func(v reflect.Value) (interface{}, error) {
+		return imag(v.Complex()), nil
+	}
+	actual, err = cfg.Marshal(src)
+	require.NoError(err)
+	require.Equal(map[string]interface{}{
+		"value": 3.0,
+		"inner": map[string]interface{}{"value": 4.0},
+	}, actual)
+}
+
+type OmitFuncChild struct {
+	Score int    `map:"score"`
+	Note  string `map:"note"`
+}
+
+type OmitFuncParent struct {
+	Count  int           `map:"count"`
+	Secret string        `map:"secret"`
+	Maybe  *int          `map:"maybe,omitNil"`
+	Child  OmitFuncChild `map:"child"`
+}
+
+func TestOmitFunc(t *testing.T) {
+	require := require.New(t)
+
+	var seen []string
+	cfg := &maps.Config{
+		TagName: "map",
+		OmitFunc: func(key string, value interface{}) bool {
+			seen = append(seen, key)
+			if i, ok := value.(int); ok && i < 0 {
+				return true
+			}
+			return value == "REDACTED"
+		},
+	}
+
+	actual, err := cfg.Marshal(&OmitFuncParent{
+		Count:  -1,
+		Secret: "REDACTED",
+		Child:  OmitFuncChild{Score: -5, Note: "kept"},
+	})
+	require.NoError(err)
+	require.Equal(map[string]interface{}{
+		"child": map[string]interface{}{"note": "kept"},
+	}, actual)
+	// Fields omitted by their tags are never offered to OmitFunc.
+	require.NotContains(seen, "maybe")
+
+	// Top-level map elements are filtered as well.
+	actual, err = cfg.Marshal(map[string]interface{}{"a": 1, "b": -1, "c": "REDACTED"})
+	require.NoError(err)
+	require.Equal(map[string]interface{}{"a": 1}, actual)
+
+	// As are the outputs of MarshalStrings and MarshalWithMeta.
+	strs, err := cfg.MarshalStrings(&OmitFuncChild{Score: 1, Note: "REDACTED"})
+	require.NoError(err)
+	require.Equal(map[string]string{"score": "1"}, strs)
+
+	meta, err := cfg.MarshalWithMeta(&OmitFuncChild{Score: -1, Note: "kept"})
+	require.NoError(err)
+	require.Len(meta, 1)
+	require.Equal("kept", meta["note"].Value)
+}
+
+type Durations struct {
+	Timeout  time.Duration
+	Interval *time.Duration
+	Missing  *time.Duration
+	Raw      int64
+	Iface    interface{}
+}
+
+func TestDurationFormat(t *testing.T) {
+	require := require.New(t)
+	var (
+		err    error
+		actual map[string]interface{}
+	)
+
+	interval := 1500 * time.Millisecond
+	s := &Durations{
+		Timeout:  time.Hour + 2*time.Minute + 3500*time.Millisecond,
+		Interval: &interval,
+		Raw:      7,
+		Iface:    time.Second,
+	}
+
+	// By default, durations are kept as-is.
+	actual, err = maps.Marshal(s)
+	require.NoError(err)
+	require.Equal(s.Timeout, actual["Timeout"])
+	require.Equal(&interval, actual["Interval"])
+
+	actual, err = (&maps.Config{TagName: "map", DurationFormat: maps.DurationString}).Marshal(s)
+	require.NoError(err)
+	require.Equal(map[string]interface{}{
+		"Timeout":  "1h2m3.5s",
+		"Interval": "1.5s",
+		"Missing":  nil,
+		"Raw":      int64(7),
+		"Iface":    time.Second,
+	}, actual)
+
+	actual, err = (&maps.Config{TagName: "map", DurationFormat: maps.DurationSeconds}).Marshal(s)
+	require.NoError(err)
+	require.Equal(map[string]interface{}{
+		"Timeout":  3723.5,
+		"Interval": 1.5,
+		"Missing":  nil,
+		"Raw":      int64(7),
+		"Iface":    time.Second,
+	}, actual)
+
+	// Top-level map values are formatted too.
+	actual, err = (&maps.Config{TagName: "map", DurationFormat: maps.DurationString}).Marshal(
+		map[string]time.Duration{"a": time.Minute})
+	require.NoError(err)
+	require.Equal(map[string]interface{}{"a": "1m0s"}, actual)
+
+	// KindMarshalers take precedence.
+	actual, err = (&maps.Config{
+		TagName:        "map",
+		DurationFormat: maps.DurationString,
+		KindMarshalers: map[reflect.Kind]func(reflect.Value) (interface{}, error){
+			reflect.Int64: func(v reflect.Value) (interface{}, error) { return "kind", nil },
+		},
+	}).Marshal(s)
+	require.NoError(err)
+	require.Equal("kind", actual["Timeout"])
+}
+
+type Person struct {
+	First string `map:"first"`
+	Last  string `map:"last"`
+	Age   int    `map:"age"`
+}
+
+func TestComputed(t *testing.T) {
+	require := require.New(t)
+
+	cfg := &maps.Config{
+		TagName: "map",
+		Computed: map[string]func(interface{}) (interface{}, error){
+			"full_name": func(v interface{}) (interface{}, error) {
+				p, ok := v.(Person)
+				if !ok {
+					return nil, nil
+				}
+				return p.First + " " + p.Last, nil
+			},
+			// Computed keys override fields of the same name.
+			"age": func(v interface{}) (interface{}, error) {
+				p, ok := v.(Person)
+				if !ok {
+					return nil, nil
+				}
+				return fmt.Sprintf("%d years", p.Age), nil
+			},
+		},
+	}
+
+	p := Person{"Ada", "Lovelace", 36}
+	expected := map[string]interface{}{
+		"first":     "Ada",
+		"last":      "Lovelace",
+		"age":       "36 years",
+		"full_name": "Ada Lovelace",
+	}
+	actual, err := cfg.Marshal(p)
+	require.NoError(err)
+	require.Equal(expected, actual)
+	actual, err = cfg.Marshal(&p)
+	require.NoError(err)
+	require.Equal(expected, actual)
+
+	slice, err := cfg.MarshalSlice([]Person{p, {"Alan", "Turing", 41}})
+	require.NoError(err)
+	require.Equal("Alan Turing", slice[1]["full_name"])
+
+	// Computed values are subject to OmitFunc.
+	omitting := *cfg
+	omitting.OmitFunc = func(k string, v interface{}) bool { return k == "full_name" }
+	actual, err = omitting.Marshal(p)
+	require.NoError(err)
+	require.NotContains(actual, "full_name")
+
+	// Computed functions are called for every top-level struct, whatever its
+	// type, but nested structs, and maps, are left alone.
+	actual, err = cfg.Marshal(struct{ P Person }{p})
+	require.NoError(err)
+	require.Equal(map[string]interface{}{
+		"P":         map[string]interface{}{"first": "Ada", "last": "Lovelace", "age": 36},
+		"full_name": nil,
+		"age":       nil,
+	}, actual)
+	actual, err = cfg.Marshal(map[string]int{"a": 1})
+	require.NoError(err)
+	require.Equal(map[string]interface{}{"a": 1}, actual)
+
+	// Errors are returned from Marshal.
+	failing := &maps.Config{
+		TagName: "map",
+		Computed: map[string]func(interface{}) (interface{}, error){
+			"bad": func(interface{}) (interface{}, error) { return nil, fmt.Errorf("boom") },
+		},
+	}
+	_, err = failing.Marshal(p)
+	require.EqualError(err, "boom")
+}
+
+type TypedOrder struct {
+	ID       int                `map:"id"`
+	Customer Person             `map:"customer"`
+	Shipping *Person            `map:"shipping"`
+	Note     struct{ S string } `map:"note"`
+}
+
+func TestTypeField(t *testing.T) {
+	require := require.New(t)
+
+	o := TypedOrder{ID: 1, Customer: Person{First: "Ada"}}
+	o.Note.S = "fragile"
+	customer := map[string]interface{}{"first": "Ada", "last": "", "age": 0}
+	note := map[string]interface{}{"S": "fragile"}
+
+	// Disabled by default.
+	actual, err := maps.Marshal(o)
+	require.NoError(err)
+	require.NotContains(actual, "_type")
+
+	// Top-level only.
+	cfg := &maps.Config{TagName: "map", TypeFieldName: "_type"}
+	actual, err = cfg.Marshal(&o)
+	require.NoError(err)
+	require.Equal(map[string]interface{}{
+		"_type":    "TypedOrder",
+		"id":       1,
+		"customer": customer,
+		"shipping": (*Person)(nil),
+		"note":     note,
+	}, actual)
+
+	slice, err := cfg.MarshalSlice([]interface{}{o, Person{}})
+	require.NoError(err)
+	require.Equal("TypedOrder", slice[0]["_type"])
+	require.Equal("Person", slice[1]["_type"])
+
+	// Every level, with a custom name. Anonymous structs are left alone.
+	cfg = &maps.Config{
+		TagName:             "map",
+		TypeFieldName:       "kind",
+		TypeFieldNestedOnly: true,
+		TypeFieldFunc: func(t reflect.Type) string {
+			if t.Name() == "" {
+				return ""
+			}
+			return "shop." + t.Name()
+		},
+	}
+	actual, err = cfg.Marshal(o)
+	require.NoError(err)
+	require.Equal("shop.TypedOrder", actual["kind"])
+	require.Equal("shop.Person", actual["customer"].(map[string]interface{})["kind"])
+	require.Equal(note, actual["note"])
+
+	// The type name replaces a field of the same name.
+	cfg = &maps.Config{TagName: "map", TypeFieldName: "first"}
+	actual, err = cfg.Marshal(Person{First: "Ada"})
+	require.NoError(err)
+	require.Equal("Person", actual["first"])
+}
+
+func TestKeyAliases(t *testing.T) {
+	require := require.New(t)
+
+	o := TypedOrder{ID: 1, Customer: Person{First: "Ada", Last: "Lovelace"}}
+	var omitted []string
+	cfg := &maps.Config{
+		TagName: "map",
+		KeyAliases: map[string]string{
+			"id":    "order_id",
+			"first": "given_name",
+			"last":  "",
+			"note":  "",
+		},
+		// OmitFunc sees the aliased keys.
+		OmitFunc: func(k string, v interface{}) bool {
+			omitted = append(omitted, k)
+			return k == "shipping"
+		},
+	}
+	actual, err := cfg.Marshal(o)
+	require.NoError(err)
+	require.Equal(map[string]interface{}{
+		"order_id": 1,
+		"customer": map[string]interface{}{"given_name": "Ada", "age": 0},
+	}, actual)
+	require.ElementsMatch([]string{"order_id", "customer", "shipping", "given_name", "age"}, omitted)
+
+	// Aliases apply to MarshalWithMeta and MarshalStrings, too, but not to
+	// the keys of maps.
+	cfg.OmitFunc = nil
+	meta, err := cfg.MarshalWithMeta(o.Customer)
+	require.NoError(err)
+	require.Equal("Ada", meta["given_name"].Value)
+	require.NotContains(meta, "last")
+	strs, err := cfg.MarshalStrings(o.Customer)
+	require.NoError(err)
+	require.Equal(map[string]string{"given_name": "Ada", "age": "0"}, strs)
+	actual, err = cfg.Marshal(map[string]int{"id": 1})
+	require.NoError(err)
+	require.Equal(map[string]interface{}{"id": 1}, actual)
+}
+
+type FlaggedValue struct {
+	Value   string
+	Enabled bool
+}
+
+func (f FlaggedValue) MarshalMapValue() (interface{}, error) {
+	if f.Value == "fail" {
+		return nil, fmt.Errorf("flag lookup failed")
+	}
+	if !f.Enabled {
+		return nil, maps.ErrSkipField
+	}
+	return f.Value, nil
+}
+
+type FlaggedFields struct {
+	On    FlaggedValue
+	Off   FlaggedValue
+	Ptr   *FlaggedValue
+	Slice []FlaggedValue
+	Map   map[string]FlaggedValue
+}
+
+func TestErrSkipField(t *testing.T) {
+	require := require.New(t)
+
+	on, off := FlaggedValue{"on", true}, FlaggedValue{"off", false}
+	src := FlaggedFields{
+		On:    on,
+		Off:   off,
+		Ptr:   &off,
+		Slice: []FlaggedValue{on, off, on},
+		Map:   map[string]FlaggedValue{"on": on, "off": off},
+	}
+	actual, err := maps.Marshal(src)
+	require.NoError(err)
+	require.Equal(map[string]interface{}{
+		"On":    "on",
+		"Slice": []interface{}{"on", "on"},
+		"Map":   map[string]interface{}{"on": "on"},
+	}, actual)
+
+	actual, err = maps.Marshal(map[string]FlaggedValue{"on": on, "off": off})
+	require.NoError(err)
+	require.Equal(map[string]interface{}{"on": "on"}, actual)
+
+	meta, err := maps.MarshalWithMeta(src)
+	require.NoError(err)
+	require.NotContains(meta, "Off")
+
+	strs, err := maps.MarshalStrings(struct{ On, Off FlaggedValue }{on, off})
+	require.NoError(err)
+	require.Equal(map[string]string{"On": "on"}, strs)
+
+	// Other errors are returned as usual.
+	src.On.Value = "fail"
+	_, err = maps.Marshal(src)
+	require.EqualError(err, "flag lookup failed")
+}
+
+type ScalarFields struct {
+	ID      int64
+	Count   int
+	Small   int
+	Ratio   float64
+	Score   float64
+	Name    string
+	Email   string
+	Empty   string
+	Active  bool
+	Deleted bool
+}
+
+func TestScalarFields(t *testing.T) {
+	require := require.New(t)
+
+	// Scalar fields are encoded as values of exactly their own types, named or
+	// not.
+	type Named int64
+	src := struct {
+		ScalarFields
+		Named Named
+		Int8  int8
+	}{ScalarFields{ID: -1, Count: 300, Ratio: 0.25, Name: "n", Active: true}, 5, -8}
+	actual, err := maps.Marshal(src)
+	require.NoError(err)
+	require.Equal(map[string]interface{}{
+		"ID":      int64(-1),
+		"Count":   300,
+		"Small":   0,
+		"Ratio":   0.25,
+		"Score":   0.0,
+		"Name":    "n",
+		"Email":   "",
+		"Empty":   "",
+		"Active":  true,
+		"Deleted": false,
+		"Named":   Named(5),
+		"Int8":    int8(-8),
+	}, actual)
+}
+
+func BenchmarkMarshalScalarFields(b *testing.B) {
+	s := &ScalarFields{
+		ID:     1 << 40,
+		Count:  123456,
+		Small:  7,
+		Ratio:  0.5,
+		Score:  98.6,
+		Name:   "Ada Lovelace",
+		Email:  "ada@example.com",
+		Active: true,
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := maps.Marshal(s); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+type Shape interface {
+	Area() float64
+}
+
+type Rect struct {
+	Width, Height float64
+	Name          string
+}
+
+func (r Rect) Area() float64 { return r.Width * r.Height }
+
+type Area float64
+
+func (a Area) Area() float64 { return float64(a) }
+
+type Layer struct {
+	Shape
+	Name string
+}
+
+func TestExpandEmbeddedInterfaces(t *testing.T) {
+	require := require.New(t)
+
+	// By default, embedded interfaces are encoded under their type name.
+	src := Layer{Shape: Rect{Width: 2, Height: 3, Name: "rect"}, Name: "layer"}
+	actual, err := maps.Marshal(src)
+	require.NoError(err)
+	require.Equal(map[string]interface{}{"Shape": src.Shape, "Name": "layer"}, actual)
+
+	// With ExpandEmbeddedInterfaces, the fields of the held struct are
+	// promoted, and the enclosing struct's fields win.
+	cfg := &maps.Config{TagName: "map", ExpandEmbeddedInterfaces: true}
+	actual, err = cfg.Marshal(src)
+	require.NoError(err)
+	require.Equal(map[string]interface{}{"Width": 2.0, "Height": 3.0, "Name": "layer"}, actual)
+
+	src.Shape = &Rect{Width: 4, Height: 5}
+	actual, err = cfg.Marshal(src)
+	require.NoError(err)
+	require.Equal(map[string]interface{}{"Width": 4.0, "Height": 5.0, "Name": "layer"}, actual)
+
+	// Nil interfaces, and nil pointers, contribute nothing.
+	for _, shape := range []Shape{nil, (*Rect)(nil)} {
+		src.Shape = shape
+		actual, err = cfg.Marshal(src)
+		require.NoError(err)
+		require.Equal(map[string]interface{}{"Name": "layer"}, actual)
+	}
+
+	// Non-struct values are encoded as usual.
+	src.Shape = Area(1.5)
+	actual, err = cfg.Marshal(src)
+	require.NoError(err)
+	require.Equal(map[string]interface{}{"Shape": Area(1.5), "Name": "layer"}, actual)
+
+	// The promoted fields are subject to the enclosing Config.
+	src.Shape = Rect{Width: 1}
+	cfg.OmitFunc = func(k string, v interface{}) bool { return v == 0.0 }
+	cfg.KeyAliases = map[string]string{"Width": "w"}
+	actual, err = cfg.Marshal(src)
+	require.NoError(err)
+	require.Equal(map[string]interface{}{"w": 1.0, "Name": "layer"}, actual)
+}
+
+type Metrics struct {
+	Requests atomic.Int64
+	Healthy  atomic.Bool
+	Uptime   atomic.Int64 `map:"uptime"`
+	Last     atomic.Value
+	Owner    atomic.Pointer[SimpleStruct]
+	Errors   *atomic.Uint32
+}
+
+func TestAtomicAware(t *testing.T) {
+	require := require.New(t)
+
+	m := &Metrics{}
+	m.Requests.Store(42)
+	m.Healthy.Store(true)
+	m.Uptime.Store(int64(3 * time.Second))
+
+	cfg := &maps.Config{TagName: "map", AtomicAware: true}
+	actual, err := cfg.Marshal(m)
+	require.NoError(err)
+	require.Equal(map[string]interface{}{
+		"Requests": int64(42),
+		"Healthy":  true,
+		"uptime":   int64(3 * time.Second),
+		"Last":     nil,
+		"Owner":    nil,
+		"Errors":   nil,
+	}, actual)
+
+	// Loaded values are encoded as a field of their type would be.
+	m.Last.Store(2 * time.Second)
+	m.Owner.Store(&SimpleStruct{FieldOne: 1})
+	m.Errors = new(atomic.Uint32)
+	m.Errors.Store(7)
+	cfg.DurationFormat = maps.DurationString
+	actual, err = cfg.Marshal(m)
+	require.NoError(err)
+	require.Equal("2s", actual["Last"])
+	require.Equal(&SimpleStruct{FieldOne: 1}, actual["Owner"])
+	require.Equal(uint32(7), actual["Errors"])
+
+	// By default, the atomics' unexported fields are all that's seen.
+	actual, err = maps.Marshal(m)
+	require.NoError(err)
+	require.Equal(map[string]interface{}{}, actual["Requests"])
+}
+
+type ExplicitNils struct {
+	Struct  PointerNilableInt
+	Omitted PointerNilableInt `map:",omitNil"`
+	Pointer *NilableInt
+	Iface   interface{}
+	Plain   int
+}
+
+func TestNilersAsNil(t *testing.T) {
+	require := require.New(t)
+
+	src := &ExplicitNils{Iface: NilableInt{}}
+	actual, err := maps.Marshal(src)
+	require.NoError(err)
+	require.Equal(map[string]interface{}{
+		"Struct":  map[string]interface{}{"Int": 0, "Valid": false},
+		"Pointer": nil,
+		"Iface":   NilableInt{},
+		"Plain":   0,
+	}, actual)
+
+	cfg := &maps.Config{TagName: "map", NilersAsNil: true}
+	actual, err = cfg.Marshal(src)
+	require.NoError(err)
+	require.Equal(map[string]interface{}{
+		"Struct":  nil,
+		"Pointer": nil,
+		"Iface":   nil,
+		"Plain":   0,
+	}, actual)
+
+	// Valid values are encoded as usual.
+	src.Struct = PointerNilableInt{Int: 1, Valid: true}
+	src.Iface = NilableInt{Int: 2, Valid: true}
+	actual, err = cfg.Marshal(src)
+	require.NoError(err)
+	require.Equal(map[string]interface{}{"Int": 1, "Valid": true}, actual["Struct"])
+	require.Equal(NilableInt{Int: 2, Valid: true}, actual["Iface"])
+
+	// Omission rules still apply.
+	cfg.OmitNilers = true
+	actual, err = cfg.Marshal(src)
+	require.NoError(err)
+	require.Equal(map[string]interface{}{
+		"Struct": map[string]interface{}{"Int": 1, "Valid": true},
+		"Iface":  NilableInt{Int: 2, Valid: true},
+		"Plain":  0,
+	}, actual)
+}
+
+type Aliased struct {
+	Attrs map[string]interface{}
+	Tags  []string
+	Grid  [2][]int
+	Any   interface{}
+}
+
+func TestCloneMapsSlices(t *testing.T) {
+	require := require.New(t)
+
+	newSrc := func() *Aliased {
+		return &Aliased{
+			Attrs: map[string]interface{}{"nested": map[string]interface{}{"a": 1}, "list": []int{1}},
+			Tags:  []string{"a", "b"},
+			Grid:  [2][]int{{1}, {2}},
+			Any:   []interface{}{map[string]interface{}{"b": 2}},
+		}
+	}
+
+	// By default, the encoded map shares its maps and slices with the source.
+	src := newSrc()
+	actual, err := maps.Marshal(src)
+	require.NoError(err)
+	actual["Attrs"].(map[string]interface{})["added"] = true
+	actual["Tags"].([]string)[0] = "z"
+	require.Equal(true, src.Attrs["added"])
+	require.Equal("z", src.Tags[0])
+
+	src = newSrc()
+	cfg := &maps.Config{TagName: "map", CloneMaps: true, CloneSlices: true}
+	actual, err = cfg.Marshal(src)
+	require.NoError(err)
+	require.Equal(newSrc().Attrs, actual["Attrs"])
+	require.Equal(newSrc().Any, actual["Any"])
+
+	attrs := actual["Attrs"].(map[string]interface{})
+	attrs["added"] = true
+	attrs["nested"].(map[string]interface{})["a"] = 2
+	attrs["list"].([]int)[0] = 2
+	actual["Tags"].([]string)[0] = "z"
+	actual["Grid"].([2][]int)[0][0] = 2
+	actual["Any"].([]interface{})[0].(map[string]interface{})["b"] = 3
+	require.Equal(newSrc(), src)
+
+	// Each option can be used on its own.
+	cfg = &maps.Config{TagName: "map", CloneMaps: true}
+	actual, err = cfg.Marshal(src)
+	require.NoError(err)
+	actual["Attrs"].(map[string]interface{})["added"] = true
+	actual["Tags"].([]string)[0] = "z"
+	require.NotContains(src.Attrs, "added")
+	require.Equal("z", src.Tags[0])
+
+	// Values of maps passed to Marshal are cloned too.
+	m := map[string]interface{}{"tags": []string{"a"}}
+	actual, err = (&maps.Config{TagName: "map", CloneSlices: true}).Marshal(m)
+	require.NoError(err)
+	actual["tags"].([]string)[0] = "z"
+	require.Equal([]string{"a"}, m["tags"])
+}
+
+type NilInterfaces struct {
+	Niler  encoding.IsNiler  `map:"niler,omitNil"`
+	Zeroer encoding.IsZeroer `map:"zeroer,omitZero"`
+	Empty  encoding.IsZeroer `map:"empty,omitEmpty"`
+	Plain  encoding.IsNiler  `map:"plain"`
+}
+
+func TestNilInterfaceFields(t *testing.T) {
+	require := require.New(t)
+
+	// Nil interfaces that include IsNil or IsZero are nil, and zero.
+	actual, err := maps.Marshal(NilInterfaces{})
+	require.NoError(err)
+	require.Equal(map[string]interface{}{"plain": nil}, actual)
+
+	actual, err = (&maps.Config{TagName: "map", NilersAsNil: true}).Marshal(NilInterfaces{})
+	require.NoError(err)
+	require.Equal(map[string]interface{}{"plain": nil}, actual)
+
+	actual, err = (&maps.Config{TagName: "map", OmitNilers: true}).Marshal(NilInterfaces{})
+	require.NoError(err)
+	require.Equal(map[string]interface{}{}, actual)
+	actual, err = (&maps.Config{TagName: "map", OmitZeroers: true}).Marshal(NilInterfaces{})
+	require.NoError(err)
+	require.Equal(map[string]interface{}{"plain": nil}, actual)
+
+	actual, err = maps.Marshal(NilInterfaces{Niler: NilableInt{}, Plain: NilableInt{Valid: true}})
+	require.NoError(err)
+	require.Equal(map[string]interface{}{"plain": NilableInt{Valid: true}}, actual)
+}
@@ -0,0 +1,144 @@
+package maps
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+)
+
+// MarshalValues converts the struct, or pointer-to-struct, src into a
+// map[string][]string suitable for building a url.Values or http.Header. Field
+// names and omission rules are the same as those of Marshal. Note that keys are
+// not canonicalized; use http.Header.Add, or tag fields with their canonical
+// names, if that matters.
+//
+// Slice and array fields become one string per element, and every other field
+// a single string. Both fields and elements are converted to strings as
+// described by MarshalStrings, and nil elements are skipped. Fields with no
+// strings -- those that are, or that marshal to, nil, and empty slices -- will
+// be left out of the returned map. Nested structs will result in an error,
+// unless Config.ValuesSeparator is set.
+func MarshalValues(src interface{}) (map[string][]string, error) {
+	ret, err := defaultConfig.marshalValues(src)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (cfg *Config) MarshalValues(src interface{}) (map[string][]string, error) {
+	ret, err := cfg.marshalValues(src)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (cfg *Config) marshalValues(src interface{}) (m map[string][]string, err error) {
+	srcv := reflect.ValueOf(src)
+	if srcv.Kind() == reflect.Ptr {
+		srcv = srcv.Elem()
+	}
+	if srcv.Kind() != reflect.Struct {
+		return nil, errors.New("src must be a struct, or pointer-to-struct")
+	}
+
+	// Any panics after this point should be converted to errors, and returned
+	// normally. Unless it's a runtime error, it's a raw string, or it's not of
+	// type `error`. In which case, do panic.
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(runtime.Error); ok {
+				panic(r)
+			} else if s, ok := r.(string); ok {
+				panic(s)
+			} else if e, ok := r.(error); !ok {
+				panic(r)
+			} else {
+				err = e
+			}
+		}
+	}()
+
+	m = make(map[string][]string)
+	if err := cfg.addValues(m, "", srcv); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// addValues adds the fields of the struct v to m, prefixing each key with
+// prefix, if it's set.
+func (cfg *Config) addValues(m map[string][]string, prefix string, v reflect.Value) error {
+	for _, f := range cachedTypeFields(v.Type(), cfg) {
+		k, ok := cfg.fieldKey(f)
+		if !ok {
+			continue
+		}
+		if prefix != "" {
+			k = prefix + cfg.ValuesSeparator + k
+		}
+		fv := fieldByIndex(v, f.index)
+		if !fv.IsValid() || cfg.omitField(f, fv) {
+			continue
+		}
+		for fv.Kind() == reflect.Ptr && !fv.IsNil() && !stringifies(fv.Type()) {
+			fv = fv.Elem()
+		}
+		if fv.Kind() == reflect.Struct && !stringifies(fv.Type()) && !f.options.Contains("value") {
+			if cfg.ValuesSeparator == "" {
+				return fmt.Errorf("maps: cannot convert nested struct field %s to strings", k)
+			}
+			if err := cfg.addValues(m, k, fv); err != nil {
+				return err
+			}
+			continue
+		}
+		ss, err := stringifyValues(fv)
+		if err != nil {
+			return fmt.Errorf("maps: cannot convert field %s to strings: %v", k, err)
+		}
+		if len(ss) > 0 && !cfg.omitEntry(k, ss) {
+			m[k] = ss
+		}
+	}
+	return nil
+}
+
+// stringifyValues converts v into a slice of strings; one per element if v is a
+// slice or array that stringifyValue wouldn't convert as a whole, and one in
+// total otherwise.
+func stringifyValues(v reflect.Value) ([]string, error) {
+	if (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) &&
+		v.Type().Elem().Kind() != reflect.Uint8 && !stringifies(v.Type()) {
+		ret := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			s, ok, err := stringifyValue(v.Index(i), true)
+			if err != nil {
+				return nil, fmt.Errorf("element %d: %v", i, err)
+			}
+			if ok {
+				ret = append(ret, s)
+			}
+		}
+		return ret, nil
+	}
+	s, ok, err := stringifyValue(v, true)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return []string{s}, nil
+}
+
+// stringifies returns true if values of type t, or pointers to them, implement
+// one of the interfaces stringifyValue consults before falling back to
+// formatting by kind.
+func stringifies(t reflect.Type) bool {
+	for _, it := range []reflect.Type{marshalerType, textMarshalerType, stringerType} {
+		if t.Implements(it) || reflect.PtrTo(t).Implements(it) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package maps_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pyrrho/encoding/maps"
+	"github.com/stretchr/testify/require"
+)
+
+type HeaderAuth struct {
+	Scheme string `map:"Scheme"`
+	Token  string `map:"Token,omitZero"`
+}
+
+type RequestHeaders struct {
+	Accept      []string     `map:"Accept"`
+	RequestID   int          `map:"X-Request-Id"`
+	Days        [2]Weekday   `map:"X-Days"`
+	Since       *time.Time   `map:"If-Modified-Since"`
+	Counts      []NilableInt `map:"X-Counts"`
+	Empty       []string     `map:"X-Empty"`
+	Key         TextKey      `map:"X-Key"`
+	Auth        *HeaderAuth  `map:"Auth"`
+	Unmentioned string       `map:"-"`
+}
+
+func TestMarshalValues(t *testing.T) {
+	require := require.New(t)
+
+	since := time.Date(2017, 4, 1, 12, 30, 0, 0, time.UTC)
+	h := RequestHeaders{
+		Accept:    []string{"text/html", "application/json"},
+		RequestID: 42,
+		Days:      [2]Weekday{1, 5},
+		Since:     &since,
+		Counts:    []NilableInt{{1, true}, {}, {3, true}},
+		Empty:     []string{},
+		Key:       TextKey{1, 2},
+	}
+	actual, err := maps.MarshalValues(&h)
+	require.NoError(err)
+	require.Equal(map[string][]string{
+		"Accept":            {"text/html", "application/json"},
+		"X-Request-Id":      {"42"},
+		"X-Days":            {"Monday", "Friday"},
+		"If-Modified-Since": {"2017-04-01T12:30:00Z"},
+		"X-Counts":          {"1", "3"},
+		"X-Key":             {"1-2"},
+	}, actual)
+
+	// Nested structs are an error, unless a separator is provided.
+	h.Auth = &HeaderAuth{Scheme: "Bearer"}
+	_, err = maps.MarshalValues(h)
+	require.EqualError(err, "maps: cannot convert nested struct field Auth to strings")
+
+	cfg := &maps.Config{TagName: "map", ValuesSeparator: "-"}
+	actual, err = cfg.MarshalValues(h)
+	require.NoError(err)
+	require.Equal([]string{"Bearer"}, actual["Auth-Scheme"])
+	require.NotContains(actual, "Auth-Token")
+}
+
+func TestMarshalValuesErrors(t *testing.T) {
+	require := require.New(t)
+	var err error
+
+	_, err = maps.MarshalValues(map[string]int{})
+	require.Error(err)
+
+	_, err = maps.MarshalValues(&struct{ S [][]int }{S: [][]int{{1}}})
+	require.EqualError(err, "maps: cannot convert field S to strings: element 0: values of type []int cannot be flattened")
+
+	_, err = maps.MarshalValues(&struct{ M map[string]int }{})
+	require.Error(err)
+}
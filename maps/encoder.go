@@ -0,0 +1,48 @@
+package maps
+
+import "sync"
+
+// Encoder marshals values using a fixed Config. Creating an Encoder once and
+// reusing it avoids passing a Config on every call, and binds the Config's
+// settings for the lifetime of the Encoder. Encoders are safe for concurrent
+// use.
+type Encoder struct {
+	cfg Config
+}
+
+// NewEncoder constructs and returns a new Encoder that marshals with a copy of
+// cfg. Modifying cfg after NewEncoder returns will not affect the Encoder. If
+// cfg is nil, the default configuration used by Marshal will be used.
+func NewEncoder(cfg *Config) *Encoder {
+	if cfg == nil {
+		cfg = defaultConfig
+	}
+	e := &Encoder{cfg: *cfg}
+	if len(e.cfg.KindMarshalers) > 0 {
+		// Share one encodeFn cache across every call made with e.
+		e.cfg.encodeFns = new(sync.Map)
+	}
+	return e
+}
+
+var defaultEncoder = NewEncoder(defaultConfig)
+
+// Encode converts src into a map[string]interface{}, following the same rules
+// as Marshal.
+func (e *Encoder) Encode(src interface{}) (map[string]interface{}, error) {
+	ret, err := e.cfg.marshal(src)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// EncodeSlice converts src into a []map[string]interface{}, following the same
+// rules as MarshalSlice.
+func (e *Encoder) EncodeSlice(src interface{}) ([]map[string]interface{}, error) {
+	ret, err := e.cfg.marshalSlice(src)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
@@ -0,0 +1,73 @@
+package maps_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/pyrrho/encoding/maps"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncoder(t *testing.T) {
+	require := require.New(t)
+
+	s := &SimpleStructWithTags{}
+	expected, err := maps.Marshal(s)
+	require.NoError(err)
+
+	// A nil Config uses the defaults of Marshal.
+	enc := maps.NewEncoder(nil)
+	actual, err := enc.Encode(s)
+	require.NoError(err)
+	require.Equal(expected, actual)
+
+	slice, err := enc.EncodeSlice([]*SimpleStructWithTags{s, s})
+	require.NoError(err)
+	require.Equal([]map[string]interface{}{expected, expected}, slice)
+
+	_, err = enc.Encode(42)
+	require.Error(err)
+}
+
+func TestEncoderBindsConfig(t *testing.T) {
+	require := require.New(t)
+
+	cfg := &maps.Config{TagName: "map", OmitZeroers: true}
+	enc := maps.NewEncoder(cfg)
+
+	// Changes to the Config after NewEncoder don't affect the Encoder.
+	cfg.OmitZeroers = false
+	actual, err := enc.Encode(&struct{ N NilableInt }{})
+	require.NoError(err)
+	require.Equal(map[string]interface{}{}, actual)
+
+	actual, err = cfg.Marshal(&struct{ N NilableInt }{})
+	require.NoError(err)
+	require.Equal(map[string]interface{}{"N": nil}, actual)
+}
+
+func TestEncoderConcurrent(t *testing.T) {
+	enc := maps.NewEncoder(&maps.Config{TagName: "map"})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			actual, err := enc.Encode(&TopLevelStruct{AnInt: i})
+			require.NoError(t, err)
+			require.Equal(t, i, actual["AnInt"])
+		}(i)
+	}
+	wg.Wait()
+}
+
+func BenchmarkEncoderEncode(b *testing.B) {
+	enc := maps.NewEncoder(&maps.Config{TagName: "map", OmitNilers: true})
+	s := &SimpleStructWithTags{}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := enc.Encode(s); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -4,8 +4,12 @@ import (
 	"strings"
 )
 
+// tagOptions holds the options of a struct tag, keyed by their canonical
+// spelling; see canonicalOption.
 type tagOptions map[string]string
 
+// parseTag splits a struct tag into its name and its comma separated options.
+// Options may carry a value, as in "opt=value".
 func parseTag(tag string) (string, tagOptions) {
 	strs := strings.Split(tag, ",")
 	name := strs[0]
@@ -16,18 +20,27 @@ func parseTag(tag string) (string, tagOptions) {
 		if idx < 0 {
 			opts.setOption(str, "")
 		} else {
-			opts.setOption(str[:idx], str[:idx])
+			opts.setOption(str[:idx], str[idx+1:])
 		}
 	}
 	return name, opts
 }
 
+// canonicalOption returns the spelling of option under which it's stored and
+// looked up. Options are matched case-insensitively, so the encoding/json
+// spellings "omitempty" and "omitzero" -- and the one-word "omitnil" -- are
+// accepted as aliases of "omitEmpty", "omitZero", and "omitNil", as is any
+// other capitalization.
+func canonicalOption(option string) string {
+	return strings.ToLower(option)
+}
+
 func (opts tagOptions) setOption(option string, value string) {
-	opts[strings.ToLower(option)] = value
+	opts[canonicalOption(option)] = value
 }
 
 func (opts tagOptions) getOption(option string) (val string, ok bool) {
-	val, ok = opts[strings.ToLower(option)]
+	val, ok = opts[canonicalOption(option)]
 	return
 }
 
@@ -0,0 +1,125 @@
+package maps
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// WalkFunc is the type of the function called by Walk for each leaf field. path
+// is the dot-separated list of keys under which Marshal would have stored the
+// field, and value is the field's Go value, as-is.
+//
+// If a WalkFunc returns an error, the walk is stopped, and Walk returns that
+// error unmodified.
+type WalkFunc func(path string, value interface{}) error
+
+// Walk calls fn for every leaf field of the struct or map src, or of the struct
+// or map src points to, without building the map[string]interface{} Marshal
+// would produce. Fields are visited in declaration order -- with the fields of
+// embedded structs visited after those of the struct that embeds them, as in
+// the Position of MarshalWithMeta's Fields -- and map entries in order of their
+// keys.
+//
+// Walk follows the same rules as Marshal; fields are named by their tags and
+// KeyAliases, and fields Marshal would have omitted are skipped. Struct-typed
+// fields that Marshal would encode into nested maps are walked in turn, with
+// their keys appended to path. All other fields -- including Marshalers,
+// fields tagged with "value", pointers, and structs with no fields of their
+// own, like time.Time -- are leaves, and are passed to fn without being
+// encoded.
+func Walk(src interface{}, fn WalkFunc) error {
+	return defaultConfig.Walk(src, fn)
+}
+
+// Walk is as the package-level Walk, but follows the rules set by cfg.
+func (cfg *Config) Walk(src interface{}, fn WalkFunc) error {
+	srcv := reflect.ValueOf(src)
+	if srcv.Kind() == reflect.Ptr {
+		srcv = srcv.Elem()
+	}
+	switch srcv.Kind() {
+	case reflect.Struct:
+		return cfg.walkStruct("", srcv, fn)
+	case reflect.Map:
+		if !isStringableKey(srcv.Type().Key()) {
+			return fmt.Errorf("src map key type %s cannot be converted to a string", srcv.Type().Key())
+		}
+		return cfg.walkMap(srcv, fn)
+	default:
+		return errors.New("src must be a struct, a map, or a pointer to either")
+	}
+}
+
+func (cfg *Config) walkStruct(prefix string, v reflect.Value, fn WalkFunc) error {
+	for _, f := range cachedTypeFields(v.Type(), cfg) {
+		k, ok := cfg.fieldKey(f)
+		if !ok {
+			continue
+		}
+		fv := fieldByIndex(v, f.index)
+		if !fv.IsValid() || cfg.omitField(f, fv) {
+			continue
+		}
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		var err error
+		if !f.options.Contains("value") && cfg.walksInto(fv.Type()) {
+			err = cfg.walkStruct(path, fv, fn)
+		} else {
+			err = fn(path, fv.Interface())
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// walkMap walks the entries of the map v. As with Marshal, pointers and
+// interfaces held by the map are followed before deciding whether an entry is a
+// leaf.
+func (cfg *Config) walkMap(v reflect.Value, fn WalkFunc) error {
+	keys := make([]string, 0, v.Len())
+	vals := make(map[string]reflect.Value, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		k := stringifyKey(iter.Key())
+		keys = append(keys, k)
+		vals[k] = iter.Value()
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		ev := vals[k]
+		for (ev.Kind() == reflect.Ptr || ev.Kind() == reflect.Interface) && !ev.IsNil() {
+			ev = ev.Elem()
+		}
+		var err error
+		if ev.Kind() != reflect.Ptr && ev.Kind() != reflect.Interface && cfg.walksInto(ev.Type()) {
+			err = cfg.walkStruct(k, ev, fn)
+		} else {
+			err = fn(k, vals[k].Interface())
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// walksInto returns true if values of type t are structs that Marshal would
+// encode field-by-field into a nested map, and that have fields to walk.
+func (cfg *Config) walksInto(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct ||
+		t.Implements(marshalerType) ||
+		reflect.PtrTo(t).Implements(marshalerType) {
+		return false
+	}
+	if fn, ok := cfg.KindMarshalers[reflect.Struct]; ok && fn != nil {
+		return false
+	}
+	return len(cachedTypeFields(t, cfg)) > 0
+}
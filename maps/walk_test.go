@@ -0,0 +1,206 @@
+package maps_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pyrrho/encoding/maps"
+	"github.com/stretchr/testify/require"
+)
+
+type WalkAddress struct {
+	Street string `map:"street"`
+	Zip    string `map:"zip"`
+}
+
+type WalkUser struct {
+	Name     string             `map:"name"`
+	Email    string             `map:"email"`
+	Address  WalkAddress        `map:"address"`
+	Previous *WalkAddress       `map:"previous,omitNil"`
+	Raw      WalkAddress        `map:"raw,value"`
+	Count    NilableInt         `map:"count"`
+	Joined   time.Time          `map:"joined"`
+	Secret   string             `map:"-"`
+	Labels   map[string]string  `map:"labels"`
+	Extra    interface{}        `map:"extra"`
+	Nested   struct{ Deep int } `map:"nested"`
+}
+
+type walkEntry struct {
+	path  string
+	value interface{}
+}
+
+func collectWalk(t *testing.T, src interface{}) []walkEntry {
+	var ret []walkEntry
+	err := maps.Walk(src, func(path string, value interface{}) error {
+		ret = append(ret, walkEntry{path, value})
+		return nil
+	})
+	require.NoError(t, err)
+	return ret
+}
+
+func TestWalk(t *testing.T) {
+	require := require.New(t)
+
+	joined := time.Date(2012, 12, 21, 21, 21, 21, 0, time.UTC)
+	u := WalkUser{
+		Name:    "Ada",
+		Email:   "ada@example.com",
+		Address: WalkAddress{"1 Main St", "12345"},
+		Raw:     WalkAddress{"2 Side St", "67890"},
+		Count:   NilableInt{42, true},
+		Joined:  joined,
+		Secret:  "hunter2",
+		Labels:  map[string]string{"a": "b"},
+	}
+	u.Nested.Deep = 7
+
+	expected := []walkEntry{
+		{"name", "Ada"},
+		{"email", "ada@example.com"},
+		{"address.street", "1 Main St"},
+		{"address.zip", "12345"},
+		{"raw", WalkAddress{"2 Side St", "67890"}},
+		{"count", NilableInt{42, true}},
+		{"joined", joined},
+		{"labels", map[string]string{"a": "b"}},
+		{"extra", nil},
+		{"nested.Deep", 7},
+	}
+	require.Equal(expected, collectWalk(t, u))
+	require.Equal(expected, collectWalk(t, &u))
+
+	// Non-nil pointers are leaves, as they are in Marshal.
+	prev := &WalkAddress{"3 Old Rd", "00000"}
+	u.Previous = prev
+	entries := collectWalk(t, u)
+	require.Equal(walkEntry{"previous", prev}, entries[4])
+
+	// Maps are walked in order of their keys, following pointers to structs.
+	entries = collectWalk(t, map[string]interface{}{
+		"b":    2,
+		"a":    &WalkAddress{"4 Map Ln", "11111"},
+		"none": nil,
+	})
+	require.Equal([]walkEntry{
+		{"a.street", "4 Map Ln"},
+		{"a.zip", "11111"},
+		{"b", 2},
+		{"none", nil},
+	}, entries)
+}
+
+func TestWalkAbort(t *testing.T) {
+	require := require.New(t)
+
+	errFound := errors.New("found PII")
+	var visited []string
+	err := maps.Walk(WalkUser{}, func(path string, value interface{}) error {
+		visited = append(visited, path)
+		if path == "email" {
+			return errFound
+		}
+		return nil
+	})
+	require.Equal(errFound, err)
+	require.Equal([]string{"name", "email"}, visited)
+}
+
+func TestWalkConfig(t *testing.T) {
+	require := require.New(t)
+
+	type Tagged struct {
+		A int `map:"map_a" json:"json_a"`
+		B WalkAddress
+	}
+	cfg := &maps.Config{TagName: "json"}
+	var paths []string
+	err := cfg.Walk(Tagged{}, func(path string, value interface{}) error {
+		paths = append(paths, path)
+		return nil
+	})
+	require.NoError(err)
+	require.Equal([]string{"json_a", "B.Street", "B.Zip"}, paths)
+
+	require.Error(maps.Walk(42, func(string, interface{}) error { return nil }))
+	require.Error(maps.Walk(map[[2]int]int{}, func(string, interface{}) error { return nil }))
+	var nilUser *WalkUser
+	require.Error(maps.Walk(nilUser, func(string, interface{}) error { return nil }))
+}
+
+func TestWalkKeyAliases(t *testing.T) {
+	require := require.New(t)
+
+	type Aliased struct {
+		Name    string      `map:"name"`
+		Email   string      `map:"email"`
+		Address WalkAddress `map:"address"`
+	}
+	cfg := &maps.Config{
+		TagName: "map",
+		KeyAliases: map[string]string{
+			"name":    "full_name",
+			"email":   "",
+			"address": "addr",
+			"zip":     "postcode",
+		},
+	}
+	var paths []string
+	err := cfg.Walk(Aliased{}, func(path string, value interface{}) error {
+		paths = append(paths, path)
+		return nil
+	})
+	require.NoError(err)
+	// Paths are built from aliased keys, and fields aliased to "" are skipped,
+	// as they are by Marshal.
+	require.Equal([]string{"full_name", "addr.street", "addr.postcode"}, paths)
+	m, err := cfg.Marshal(Aliased{})
+	require.NoError(err)
+	require.Equal(map[string]interface{}{
+		"full_name": "",
+		"addr":      map[string]interface{}{"street": "", "postcode": ""},
+	}, m)
+}
+
+// DeclarationOrder embeds structs before, and after, a field of its own.
+type DeclarationOrder struct {
+	Deeper
+	AnInt int
+	LevelTwoLeft
+	TopLevelStruct `map:"top"`
+}
+
+func TestWalkDeclarationOrder(t *testing.T) {
+	require := require.New(t)
+
+	src := DeclarationOrder{
+		Deeper:       Deeper{Exported: 1},
+		AnInt:        2,
+		LevelTwoLeft: LevelTwoLeft{AnInt: 3, AString: "s", AFloat: 4},
+	}
+
+	// Outer fields come first, then those promoted from each embedded struct
+	// in turn. Tagged embedded structs are fields like any other.
+	expected := []walkEntry{
+		{"AnInt", 2},
+		{"top.AnInt", 0},
+		{"top.Exported", 0},
+		{"Exported", 1},
+		{"AString", "s"},
+		{"AFloat", 4.0},
+	}
+	require.Equal(expected, collectWalk(t, src))
+
+	// MarshalWithMeta numbers its Fields in the same order.
+	meta, err := maps.MarshalWithMeta(src)
+	require.NoError(err)
+	keys := []string{"AnInt", "top", "Exported", "AString", "AFloat"}
+	require.Len(meta, len(keys))
+	for i, k := range keys {
+		require.Equal(i, meta[k].Position, k)
+	}
+}
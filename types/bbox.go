@@ -0,0 +1,184 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// BBox is an axis-aligned bounding box, describing the spatial extent of one or
+// more geometries by their minimum and maximum longitude (X) and latitude (Y)
+// components. Any other components are ignored.
+//
+// The zero value is the degenerate BBox containing only the origin. An empty
+// BBox -- one that contains nothing at all -- is represented by minimums
+// greater than their maximums, as constructed by NewBBoxEmpty, and is the
+// natural starting point when aggregating extents with Expand or Union.
+//
+// JSON interactions (MarshalJSON and UnmarshalJSON) will convert to and from
+// the four element array [minX, minY, maxX, maxY], per the "bbox" member of
+// RFC 7946. Empty BBoxes are encoded as the JSON 'null' keyword.
+type BBox struct {
+	MinX, MinY, MaxX, MaxY float64
+}
+
+// Constructors
+
+// NewBBox constructs and returns a new BBox bounded by the given minimum and
+// maximum coordinates. If a minimum is greater than its maximum, the two will
+// be swapped.
+func NewBBox(minX, minY, maxX, maxY float64) BBox {
+	if minX > maxX {
+		minX, maxX = maxX, minX
+	}
+	if minY > maxY {
+		minY, maxY = maxY, minY
+	}
+	return BBox{minX, minY, maxX, maxY}
+}
+
+// NewBBoxEmpty constructs and returns a new empty BBox, which contains no
+// points, and intersects no other BBoxes.
+func NewBBoxEmpty() BBox {
+	return BBox{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+}
+
+// bboxOf returns the BBox of the first two components of every coordinate in
+// the flat coordinate slice flat.
+func bboxOf(flat []float64, stride int) BBox {
+	b := NewBBoxEmpty()
+	for i := 0; i+1 < len(flat); i += stride {
+		b.expand(flat[i], flat[i+1])
+	}
+	return b
+}
+
+// Getters
+
+// IsEmpty returns true if b contains no points; if either of its minimums is
+// greater than the corresponding maximum.
+func (b BBox) IsEmpty() bool {
+	return b.MinX > b.MaxX || b.MinY > b.MaxY
+}
+
+// Contains returns true if the point p lies within b, or on its boundary. Nil
+// and empty SFPoints are not contained by any BBox.
+func (b BBox) Contains(p SFPoint) bool {
+	if p.IsNil() || p.IsEmpty() {
+		return false
+	}
+	x, y := p.X(), p.Y()
+	return b.MinX <= x && x <= b.MaxX && b.MinY <= y && y <= b.MaxY
+}
+
+// Intersects returns true if b and o share at least one point, including
+// points on their boundaries. Empty BBoxes intersect nothing.
+func (b BBox) Intersects(o BBox) bool {
+	if b.IsEmpty() || o.IsEmpty() {
+		return false
+	}
+	return b.MinX <= o.MaxX && o.MinX <= b.MaxX && b.MinY <= o.MaxY && o.MinY <= b.MaxY
+}
+
+// Union returns the smallest BBox containing both b and o. The union of an
+// empty BBox and any other is the other.
+func (b BBox) Union(o BBox) BBox {
+	switch {
+	case o.IsEmpty():
+		return b
+	case b.IsEmpty():
+		return o
+	}
+	return BBox{
+		math.Min(b.MinX, o.MinX),
+		math.Min(b.MinY, o.MinY),
+		math.Max(b.MaxX, o.MaxX),
+		math.Max(b.MaxY, o.MaxY),
+	}
+}
+
+// ToPolygon returns the rectangle described by b as a new SFPolygon,
+// constructed by NewSFPolygonFromBBox. An empty SFPolygon will be returned if b
+// is empty.
+func (b BBox) ToPolygon() SFPolygon {
+	if b.IsEmpty() {
+		return SFPolygon{}
+	}
+	return NewSFPolygonFromBBox(b.MinX, b.MinY, b.MaxX, b.MaxY)
+}
+
+// Setters
+
+// Expand grows b in place, as little as possible, so that it contains p. Nil
+// and empty SFPoints leave b unchanged.
+func (b *BBox) Expand(p SFPoint) {
+	if p.IsNil() || p.IsEmpty() {
+		return
+	}
+	b.expand(p.X(), p.Y())
+}
+
+func (b *BBox) expand(x, y float64) {
+	b.MinX, b.MaxX = math.Min(b.MinX, x), math.Max(b.MaxX, x)
+	b.MinY, b.MaxY = math.Min(b.MinY, y), math.Max(b.MaxY, y)
+}
+
+// Interfaces
+
+// String implements the fmt Stringer interface, describing b by its array
+// representation.
+func (b BBox) String() string {
+	if b.IsEmpty() {
+		return "<empty BBox>"
+	}
+	return fmt.Sprintf("[%v %v %v %v]", b.MinX, b.MinY, b.MaxX, b.MaxY)
+}
+
+// MarshalJSON implements the encoding/json Marshaler interface. It will encode
+// b as the array [minX, minY, maxX, maxY], or as the JSON 'null' keyword if b
+// is empty.
+func (b BBox) MarshalJSON() ([]byte, error) {
+	if b.IsEmpty() {
+		return []byte("null"), nil
+	}
+	return json.Marshal([4]float64{b.MinX, b.MinY, b.MaxX, b.MaxY})
+}
+
+// UnmarshalJSON implements the encoding/json Unmarshaler interface. It expects
+// to receive a four element array of numbers, [minX, minY, maxX, maxY], and
+// will assign its value to b. The JSON 'null' keyword will decode into an
+// empty BBox. Arrays of any other length, and arrays whose minimums are
+// greater than their maximums, are an error.
+func (b *BBox) UnmarshalJSON(data []byte) error {
+	if b == nil {
+		return fmt.Errorf("types.BBox: UnmarshalJSON called on nil pointer")
+	}
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		*b = NewBBoxEmpty()
+		return nil
+	}
+	var coords []float64
+	if err := json.Unmarshal(data, &coords); err != nil {
+		return fmt.Errorf("types.BBox: %v", err)
+	}
+	if len(coords) != 4 {
+		return fmt.Errorf("types.BBox: expected 4 coordinates, found %d", len(coords))
+	}
+	nb := BBox{coords[0], coords[1], coords[2], coords[3]}
+	if nb.IsEmpty() {
+		return fmt.Errorf("types.BBox: minimums %v must not exceed maximums %v", coords[:2], coords[2:])
+	}
+	*b = nb
+	return nil
+}
+
+// MarshalMapValue implements the pyrrho/encoding/maps Marshaler interface. It
+// will return b as a []float64 of [minX, minY, maxX, maxY], or nil if b is
+// empty.
+func (b BBox) MarshalMapValue() (interface{}, error) {
+	if b.IsEmpty() {
+		return nil, nil
+	}
+	return []float64{b.MinX, b.MinY, b.MaxX, b.MaxY}, nil
+}
@@ -0,0 +1,119 @@
+package types_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pyrrho/encoding/maps"
+	"github.com/pyrrho/encoding/types"
+	"github.com/stretchr/testify/require"
+	"github.com/twpayne/go-geom"
+)
+
+func TestBBoxCtors(t *testing.T) {
+	require := require.New(t)
+
+	require.Equal(types.BBox{MinX: 1, MinY: 2, MaxX: 3, MaxY: 4}, types.NewBBox(3, 4, 1, 2))
+	require.False(types.BBox{}.IsEmpty())
+	require.True(types.NewBBoxEmpty().IsEmpty())
+}
+
+func TestBBoxContains(t *testing.T) {
+	require := require.New(t)
+
+	b := types.NewBBox(0, 0, 10, 5)
+	require.True(b.Contains(types.NewSFPointXY(5, 2)))
+	require.True(b.Contains(types.NewSFPointXYZ(10, 5, 100)))
+	require.False(b.Contains(types.NewSFPointXY(10.1, 2)))
+	require.False(b.Contains(types.NewSFPointXY(5, -1)))
+	require.False(b.Contains(types.SFPoint{}))
+	require.False(b.Contains(types.NewSFPointEmpty(geom.XY)))
+	require.False(types.NewBBoxEmpty().Contains(types.NewSFPointXY(0, 0)))
+}
+
+func TestBBoxIntersects(t *testing.T) {
+	require := require.New(t)
+
+	b := types.NewBBox(0, 0, 10, 10)
+	require.True(b.Intersects(types.NewBBox(5, 5, 15, 15)))
+	require.True(b.Intersects(types.NewBBox(10, 10, 15, 15)))
+	require.True(b.Intersects(types.NewBBox(2, 2, 3, 3)))
+	require.False(b.Intersects(types.NewBBox(11, 0, 15, 10)))
+	require.False(b.Intersects(types.NewBBoxEmpty()))
+	require.False(types.NewBBoxEmpty().Intersects(b))
+}
+
+func TestBBoxExpandUnion(t *testing.T) {
+	require := require.New(t)
+
+	b := types.NewBBoxEmpty()
+	b.Expand(types.SFPoint{})
+	require.True(b.IsEmpty())
+	b.Expand(types.NewSFPointXY(1, 2))
+	require.Equal(types.NewBBox(1, 2, 1, 2), b)
+	b.Expand(types.NewSFPointXY(-1, 5))
+	require.Equal(types.NewBBox(-1, 2, 1, 5), b)
+
+	require.Equal(types.NewBBox(-1, 0, 3, 5), b.Union(types.NewBBox(0, 0, 3, 3)))
+	require.Equal(b, b.Union(types.NewBBoxEmpty()))
+	require.Equal(b, types.NewBBoxEmpty().Union(b))
+}
+
+func TestBBoxToPolygon(t *testing.T) {
+	require := require.New(t)
+
+	require.Equal(types.NewSFPolygonFromBBox(1, 2, 3, 4), types.NewBBox(1, 2, 3, 4).ToPolygon())
+	require.True(types.NewBBoxEmpty().ToPolygon().IsNil())
+}
+
+func TestGeometryBBox(t *testing.T) {
+	require := require.New(t)
+
+	require.Equal(types.NewBBox(1, 2, 1, 2), types.NewSFPointXYZ(1, 2, 3).BBox())
+	require.True(types.SFPoint{}.BBox().IsEmpty())
+	require.True(types.NewSFPointEmpty(geom.XY).BBox().IsEmpty())
+
+	l := types.NewSFLineStringXY([][2]float64{{3, 1}, {-2, 4}, {0, 0}})
+	require.Equal(types.NewBBox(-2, 0, 3, 4), l.BBox())
+	require.True(types.SFLineString{}.BBox().IsEmpty())
+
+	p := types.NewSFPolygonXY(testPolygonExternal, testPolygonInternal)
+	require.Equal(types.NewBBox(10, 10, 40, 40), p.BBox())
+	require.Equal(p.Envelope(), p.BBox().ToPolygon())
+	require.True(types.SFPolygon{}.BBox().IsEmpty())
+}
+
+func TestBBoxJSON(t *testing.T) {
+	require := require.New(t)
+
+	data, err := json.Marshal(types.NewBBox(-1.5, 2, 3, 4))
+	require.NoError(err)
+	require.JSONEq(`[-1.5,2,3,4]`, string(data))
+	data, err = json.Marshal(types.NewBBoxEmpty())
+	require.NoError(err)
+	require.Equal(`null`, string(data))
+
+	var b types.BBox
+	require.NoError(json.Unmarshal([]byte(`[-1.5, 2, 3, 4]`), &b))
+	require.Equal(types.NewBBox(-1.5, 2, 3, 4), b)
+	require.NoError(json.Unmarshal([]byte(`null`), &b))
+	require.True(b.IsEmpty())
+
+	b = types.NewBBox(1, 1, 2, 2)
+	require.Error(json.Unmarshal([]byte(`[1, 2, 3]`), &b))
+	require.Error(json.Unmarshal([]byte(`[3, 4, 1, 2]`), &b))
+	require.Error(json.Unmarshal([]byte(`{"minX": 1}`), &b))
+	require.Equal(types.NewBBox(1, 1, 2, 2), b)
+}
+
+func TestBBoxMarshalMapValue(t *testing.T) {
+	require := require.New(t)
+
+	var _ maps.Marshaler = types.BBox{}
+	v, err := types.NewBBox(1, 2, 3, 4).MarshalMapValue()
+	require.NoError(err)
+	require.Equal([]float64{1, 2, 3, 4}, v)
+	v, err = types.NewBBoxEmpty().MarshalMapValue()
+	require.NoError(err)
+	require.Nil(v)
+}
@@ -0,0 +1,338 @@
+package types
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/geojson"
+	"github.com/twpayne/go-geom/encoding/wkt"
+)
+
+// SFGeometry is implemented by each of the SF geometry types -- SFPoint,
+// SFLineString, and SFPolygon -- so that a geometry of any of those types can
+// be held, inspected, and encoded without knowing its concrete type. Decoding
+// requires a concrete type; UnmarshalGeometryJSON and ScanGeometry will choose
+// one based on the input.
+type SFGeometry interface {
+	// GeometryType returns the GeoJSON type name of the geometry; eg. "Point".
+	GeometryType() string
+	// BBox returns the bounding box of the geometry.
+	BBox() BBox
+	IsNil() bool
+	IsZero() bool
+	String() string
+	Value() (driver.Value, error)
+	MarshalJSON() ([]byte, error)
+	MarshalMapValue() (interface{}, error)
+}
+
+var (
+	_ SFGeometry = SFPoint{}
+	_ SFGeometry = SFLineString{}
+	_ SFGeometry = SFPolygon{}
+)
+
+// newGeometry returns a pointer to a new, nil, SF geometry of the type
+// described by the GeoJSON type name, or nil if there is no such SF type.
+func newGeometry(name string) interface {
+	SFGeometry
+	json.Unmarshaler
+	Scan(interface{}) error
+} {
+	switch name {
+	case "Point":
+		return &SFPoint{}
+	case "LineString":
+		return &SFLineString{}
+	case "Polygon":
+		return &SFPolygon{}
+	}
+	return nil
+}
+
+// utf8BOM is the UTF-8 encoded byte order mark, which some tools write at the
+// start of GeoJSON files.
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// trimGeoJSON returns data without a leading UTF-8 byte order mark, or any
+// surrounding whitespace, so that the contents of GeoJSON files can be decoded
+// as-is.
+func trimGeoJSON(data []byte) []byte {
+	return bytes.TrimSpace(bytes.TrimPrefix(bytes.TrimSpace(data), utf8BOM))
+}
+
+// UnmarshalGeometryJSON decodes the GeoJSON geometry data into the SF type that
+// matches its "type" member, and returns it as an SFGeometry; eg. a "Point"
+// will be returned as an SFPoint. The JSON 'null' keyword decodes into a nil
+// SFGeometry. A leading UTF-8 byte order mark, and surrounding whitespace, are
+// ignored. An error will be returned if data is not a GeoJSON geometry, or if
+// its type has no SF counterpart.
+func UnmarshalGeometryJSON(data []byte) (SFGeometry, error) {
+	data = trimGeoJSON(data)
+	var obj *struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, fmt.Errorf("types: %v", err)
+	}
+	if obj == nil {
+		return nil, nil
+	}
+	g := newGeometry(obj.Type)
+	if g == nil {
+		return nil, fmt.Errorf("types: unsupported GeoJSON geometry type %q", obj.Type)
+	}
+	if err := g.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+	return derefGeometry(g), nil
+}
+
+// ScanGeometry decodes the WKB encoded src -- as would be passed to a Scan
+// method -- into the SF type that matches its geometry type, as determined by
+// GeometryTypeFromWKB, and returns it as an SFGeometry. A nil src decodes into
+// a nil SFGeometry. Like the Scan methods, ScanGeometry accepts a string, a
+// *[]byte, or a driver.Valuer in place of a []byte. An error will be returned
+// if src is of any other type, or if its geometry type has no SF counterpart.
+func ScanGeometry(src interface{}) (SFGeometry, error) {
+	if src == nil {
+		return nil, nil
+	}
+	b, err := scanBytes(src)
+	if err != nil {
+		return nil, fmt.Errorf("types: %v", err)
+	}
+	name, err := GeometryTypeFromWKB(b)
+	if err != nil {
+		return nil, err
+	}
+	g := newGeometry(name)
+	if g == nil {
+		return nil, fmt.Errorf("types: unsupported WKB geometry type %q", name)
+	}
+	if err := g.Scan(b); err != nil {
+		return nil, err
+	}
+	return derefGeometry(g), nil
+}
+
+// derefGeometry returns the value pointed to by g, which must have been
+// returned by newGeometry.
+func derefGeometry(g SFGeometry) SFGeometry {
+	switch g := g.(type) {
+	case *SFPoint:
+		return *g
+	case *SFLineString:
+		return *g
+	case *SFPolygon:
+		return *g
+	}
+	return g
+}
+
+// wktString returns the WKT representation of g, for use by the String methods
+// of the SF types. Geometries without a layout are described as "<nil name>".
+func wktString(g geom.T, name string) string {
+	if g == nil || g.Layout() == geom.NoLayout {
+		return "<nil " + name + ">"
+	}
+	s, err := wkt.Marshal(g)
+	if err != nil {
+		return fmt.Sprintf("<invalid %s: %v>", name, err)
+	}
+	return s
+}
+
+// EmptyGeometryAsNull controls how the MarshalJSON methods of the SF geometry
+// types handle nil geometries (those for which IsNil returns true). By
+// default, marshalling a nil geometry is an error. When EmptyGeometryAsNull is
+// true, nil geometries will instead be encoded as the JSON 'null' keyword,
+// which is friendlier when geometries are optional members of larger
+// documents.
+//
+// Regardless of this setting, UnmarshalJSON will decode 'null' into a nil
+// geometry; one for which IsNil returns true.
+var EmptyGeometryAsNull = false
+
+// AcceptBareCoordinates controls whether SFPoint.UnmarshalJSON accepts a bare
+// coordinate array -- [lng, lat] or [lng, lat, alt] -- in place of a GeoJSON
+// Point object, as sent by some APIs that slim down their geometries. The
+// layout of the resulting SFPoint is inferred from the length of the array.
+// By default, only GeoJSON objects are accepted.
+var AcceptBareCoordinates = false
+
+// MapValueAsGeoJSON controls what the MarshalMapValue methods of the SF
+// geometry types return. By default, each geometry returns itself, leaving
+// encoding to whoever consumes the resulting map. When MapValueAsGeoJSON is
+// true, geometries will instead return their GeoJSON representation as a
+// map[string]interface{} -- the same structure json.Unmarshal would produce --
+// so that maps built by pyrrho/encoding/maps contain only JSON-native values.
+//
+// Empty geometries follow the rules of MarshalJSON; they are an error, unless
+// EmptyGeometryAsNull is true, in which case they become nil.
+var MapValueAsGeoJSON = false
+
+// GeoJSONEmitBBox controls whether the MarshalJSON methods of the SF geometry
+// types include the RFC 7946 "bbox" member -- the extent of the geometry's
+// coordinates, as [minX, minY, maxX, maxY], or [minX, minY, minZ, maxX, maxY,
+// maxZ] for geometries with a Z component -- so that consumers can cull
+// geometries without reading every coordinate. Empty geometries, which have no
+// extent, are encoded without a bbox regardless of this setting.
+var GeoJSONEmitBBox = false
+
+// ValidateBBox controls whether the UnmarshalJSON methods of the SF geometry
+// types check the "bbox" member of the geometries they decode. By default, a
+// bbox is accepted and discarded, whatever its value. When ValidateBBox is
+// true, a bbox must be an array of 2*n numbers, where n is 2 or 3, and must
+// contain every coordinate of the geometry -- a bbox larger than the geometry
+// is accepted -- or an error will be returned. The Z range of a 3D bbox is only
+// checked against geometries with a Z component.
+var ValidateBBox = false
+
+// marshalGeoJSON returns the GeoJSON encoded representation of g, with a
+// "bbox" member if GeoJSONEmitBBox is true.
+func marshalGeoJSON(g geom.T) ([]byte, error) {
+	if GeoJSONEmitBBox && !g.Empty() {
+		return geojson.Marshal(g, geojson.EncodeGeometryWithBBox())
+	}
+	return geojson.Marshal(g)
+}
+
+// checkGeoJSONBBox returns an error if ValidateBBox is true, and the "bbox"
+// member of the GeoJSON object data, if it has one, is malformed or doesn't
+// contain every coordinate of g, the geometry decoded from data.
+func checkGeoJSONBBox(data []byte, g geom.T) error {
+	if !ValidateBBox || g == nil {
+		return nil
+	}
+	var obj struct {
+		BBox []float64 `json:"bbox"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	bbox := obj.BBox
+	if bbox == nil {
+		return nil
+	}
+	n := len(bbox) / 2
+	if len(bbox) != 4 && len(bbox) != 6 {
+		return fmt.Errorf("bbox must have 4 or 6 elements, found %d", len(bbox))
+	}
+	for i := 0; i < n; i++ {
+		if bbox[i] > bbox[n+i] {
+			return fmt.Errorf("bbox %v has a minimum greater than its maximum", bbox)
+		}
+	}
+	if g.Empty() {
+		return nil
+	}
+	b := g.Bounds()
+	for i := 0; i < n; i++ {
+		dim := i
+		if i == 2 {
+			if dim = g.Layout().ZIndex(); dim < 0 {
+				continue
+			}
+		}
+		if b.Min(dim) < bbox[i] || b.Max(dim) > bbox[n+i] {
+			return fmt.Errorf("bbox %v does not contain every coordinate of the geometry", bbox)
+		}
+	}
+	return nil
+}
+
+// geoJSONMapValue encodes m as JSON and decodes the result into an
+// interface{}, for use by the MarshalMapValue methods of the SF geometry types.
+func geoJSONMapValue(m json.Marshaler) (interface{}, error) {
+	data, err := m.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var iface interface{}
+	if err := json.Unmarshal(data, &iface); err != nil {
+		return nil, err
+	}
+	return iface, nil
+}
+
+// scanBytes returns the WKB held by src, a value passed to a Scan method.
+// Drivers don't all agree on how to pass it, so in addition to []byte, src may
+// be a string, a non-nil *[]byte, or a driver.Valuer whose Value is one of
+// those. An error naming the type of src will be returned for anything else.
+func scanBytes(src interface{}) ([]byte, error) {
+	v := src
+	if valuer, ok := src.(driver.Valuer); ok {
+		var err error
+		if v, err = valuer.Value(); err != nil {
+			return nil, err
+		}
+	}
+	switch x := v.(type) {
+	case []byte:
+		return x, nil
+	case *[]byte:
+		if x != nil {
+			return *x, nil
+		}
+	case string:
+		return []byte(x), nil
+	}
+	return nil, fmt.Errorf("cannot scan type %T (%v)", src, src)
+}
+
+// checkWKBGeometryType returns an error if the header of the WKB encoded b
+// describes a geometry of a type other than want, so that Scan methods can
+// report a mismatch by name rather than by the go-geom type that was decoded.
+// Malformed headers are left for the WKB decoder to report.
+func checkWKBGeometryType(b []byte, want string) error {
+	if got, err := GeometryTypeFromWKB(b); err == nil && got != want {
+		return fmt.Errorf("expected %s, got %s", want, got)
+	}
+	return nil
+}
+
+// wkbGeometryTypes maps the base WKB geometry type codes to their GeoJSON type
+// names.
+var wkbGeometryTypes = map[uint32]string{
+	1: "Point",
+	2: "LineString",
+	3: "Polygon",
+	4: "MultiPoint",
+	5: "MultiLineString",
+	6: "MultiPolygon",
+	7: "GeometryCollection",
+}
+
+// GeometryTypeFromWKB returns the GeoJSON type name -- "Point", "Polygon",
+// etc. -- of the geometry described by the WKB encoded b, by inspecting only
+// its header. Both byte orders are supported, as are the ISO (eg. 1001 for a
+// Point Z) and PostGIS EWKB (high bit flags for Z, M, and SRID) encodings of
+// the geometry type. An error will be returned if the header is truncated or
+// malformed, or if it describes an unknown geometry type.
+func GeometryTypeFromWKB(b []byte) (string, error) {
+	if len(b) < 5 {
+		return "", fmt.Errorf("types: cannot read the geometry type of a %d byte WKB", len(b))
+	}
+	var order binary.ByteOrder
+	switch b[0] {
+	case 0:
+		order = binary.BigEndian
+	case 1:
+		order = binary.LittleEndian
+	default:
+		return "", fmt.Errorf("types: invalid WKB byte order %#x", b[0])
+	}
+	code := order.Uint32(b[1:5])
+	// Strip the EWKB Z, M, and SRID flags, and then the ISO dimension offset.
+	base := (code &^ 0xe0000000) % 1000
+	name, ok := wkbGeometryTypes[base]
+	if !ok {
+		return "", fmt.Errorf("types: unknown WKB geometry type %d", code)
+	}
+	return name, nil
+}
@@ -0,0 +1,369 @@
+package types_test
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/ewkb"
+	"github.com/twpayne/go-geom/encoding/wkb"
+
+	"github.com/pyrrho/encoding/maps"
+	"github.com/pyrrho/encoding/types"
+)
+
+func TestEmptyGeometryAsNull(t *testing.T) {
+	require := require.New(t)
+	var data []byte
+	var err error
+
+	type Parent struct {
+		Name    string
+		Point   types.SFPoint
+		Polygon types.SFPolygon
+	}
+	empty := Parent{Name: "empty"}
+
+	// By default, a single empty geometry fails the whole document.
+	_, err = json.Marshal(empty)
+	require.Error(err)
+
+	types.EmptyGeometryAsNull = true
+	defer func() { types.EmptyGeometryAsNull = false }()
+
+	data, err = json.Marshal(empty)
+	require.NoError(err)
+	require.JSONEq(`{"Name":"empty","Point":null,"Polygon":null}`, string(data))
+
+	// Non-empty geometries are unaffected.
+	p := types.NewSFPointXY(1.2, 2.3)
+	data, err = json.Marshal(p)
+	require.NoError(err)
+	require.EqualValues(testPointGeoJSON, data)
+
+	// 'null' decodes back into empty geometries, clearing any prior value.
+	rt := Parent{
+		Point:   types.NewSFPointXY(1.2, 2.3),
+		Polygon: types.NewSFPolygonXY([][2]float64{{30, 10}, {40, 40}, {20, 40}, {30, 10}}),
+	}
+	err = json.Unmarshal([]byte(`{"Name":"empty","Point":null,"Polygon":null}`), &rt)
+	require.NoError(err)
+	require.True(rt.Point.IsNil())
+	require.True(rt.Polygon.IsNil())
+}
+
+func TestMapValueAsGeoJSON(t *testing.T) {
+	require := require.New(t)
+	var data map[string]interface{}
+	var err error
+
+	type Parent struct {
+		Name    string
+		Point   types.SFPoint
+		Polygon types.SFPolygon
+	}
+	parent := Parent{
+		Name:    "parent",
+		Point:   types.NewSFPointXY(1.2, 2.3),
+		Polygon: types.NewSFPolygonXY([][2]float64{{30, 10}, {40, 40}, {20, 40}, {30, 10}}),
+	}
+
+	// By default, geometries marshal into maps as themselves.
+	data, err = maps.Marshal(parent)
+	require.NoError(err)
+	require.Equal(parent.Point, data["Point"])
+	require.Equal(parent.Polygon, data["Polygon"])
+
+	types.MapValueAsGeoJSON = true
+	defer func() { types.MapValueAsGeoJSON = false }()
+
+	data, err = maps.Marshal(parent)
+	require.NoError(err)
+	require.Equal(map[string]interface{}{
+		"Name": "parent",
+		"Point": map[string]interface{}{
+			"type":        "Point",
+			"coordinates": []interface{}{1.2, 2.3},
+		},
+		"Polygon": map[string]interface{}{
+			"type": "Polygon",
+			"coordinates": []interface{}{[]interface{}{
+				[]interface{}{30.0, 10.0},
+				[]interface{}{40.0, 40.0},
+				[]interface{}{20.0, 40.0},
+				[]interface{}{30.0, 10.0},
+			}},
+		},
+	}, data)
+
+	// The resulting map round-trips through encoding/json.
+	b, err := json.Marshal(data)
+	require.NoError(err)
+	var rt Parent
+	err = json.Unmarshal(b, &rt)
+	require.NoError(err)
+	require.Equal(parent.Point.FlatCoords(), rt.Point.FlatCoords())
+	require.Equal(parent.Polygon.FlatCoords(), rt.Polygon.FlatCoords())
+
+	// Empty geometries follow the rules of MarshalJSON.
+	_, err = maps.Marshal(Parent{})
+	require.Error(err)
+
+	types.EmptyGeometryAsNull = true
+	defer func() { types.EmptyGeometryAsNull = false }()
+	data, err = maps.Marshal(Parent{})
+	require.NoError(err)
+	require.Nil(data["Point"])
+	require.Nil(data["Polygon"])
+}
+
+func TestGeometryType(t *testing.T) {
+	require := require.New(t)
+
+	require.Equal("Point", types.NewSFPointXY(1, 2).GeometryType())
+	require.Equal("LineString", types.NewSFLineStringXY([][2]float64{{1, 2}, {3, 4}}).GeometryType())
+	require.Equal("Polygon", types.NewSFPolygonFromBBox(0, 0, 1, 1).GeometryType())
+
+	// The zero value reports its type too.
+	require.Equal("Point", types.SFPoint{}.GeometryType())
+}
+
+func TestGeometryTypeFromWKB(t *testing.T) {
+	require := require.New(t)
+
+	geoms := []geom.T{
+		geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2}),
+		geom.NewPoint(geom.XYZM).MustSetCoords(geom.Coord{1, 2, 3, 4}),
+		geom.NewLineString(geom.XYZ).MustSetCoords([]geom.Coord{{1, 2, 3}, {4, 5, 6}}),
+		geom.NewPolygon(geom.XYM).MustSetCoords([][]geom.Coord{{{0, 0, 1}, {1, 0, 1}, {1, 1, 1}, {0, 0, 1}}}),
+		geom.NewMultiPoint(geom.XY).MustSetCoords([]geom.Coord{{1, 2}}),
+		geom.NewMultiLineString(geom.XY).MustSetCoords([][]geom.Coord{{{1, 2}, {3, 4}}}),
+		geom.NewMultiPolygon(geom.XY).MustSetCoords([][][]geom.Coord{{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}}),
+		geom.NewGeometryCollection().MustPush(geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2})),
+	}
+	names := []string{
+		"Point", "Point", "LineString", "Polygon",
+		"MultiPoint", "MultiLineString", "MultiPolygon", "GeometryCollection",
+	}
+	for i, g := range geoms {
+		for _, order := range []binary.ByteOrder{wkb.NDR, wkb.XDR} {
+			// ISO WKB.
+			b, err := wkb.Marshal(g, order)
+			require.NoError(err)
+			name, err := types.GeometryTypeFromWKB(b)
+			require.NoError(err)
+			require.Equal(names[i], name)
+
+			// PostGIS EWKB.
+			b, err = ewkb.Marshal(g, order)
+			require.NoError(err)
+			name, err = types.GeometryTypeFromWKB(b)
+			require.NoError(err)
+			require.Equal(names[i], name)
+		}
+	}
+
+	// EWKB with an SRID.
+	srid := geom.NewPoint(geom.XYZ).MustSetCoords(geom.Coord{1, 2, 3}).SetSRID(4326)
+	for _, order := range []binary.ByteOrder{wkb.NDR, wkb.XDR} {
+		b, err := ewkb.Marshal(srid, order)
+		require.NoError(err)
+		name, err := types.GeometryTypeFromWKB(b)
+		require.NoError(err)
+		require.Equal("Point", name)
+	}
+
+	// Only the header is read.
+	name, err := types.GeometryTypeFromWKB([]byte{0x01, 0x03, 0x00, 0x00, 0x00})
+	require.NoError(err)
+	require.Equal("Polygon", name)
+
+	for _, bad := range [][]byte{
+		nil,
+		{0x01, 0x01, 0x00, 0x00},
+		{0x02, 0x01, 0x00, 0x00, 0x00},
+		{0x01, 0x08, 0x00, 0x00, 0x00},
+		{0x01, 0x00, 0x00, 0x00, 0x00},
+	} {
+		_, err = types.GeometryTypeFromWKB(bad)
+		require.Error(err, "%v", bad)
+	}
+}
+
+func TestUnmarshalGeometryJSON(t *testing.T) {
+	require := require.New(t)
+
+	geometries := []types.SFGeometry{
+		types.NewSFPointXYZ(1, 2, 3),
+		types.NewSFLineStringXY([][2]float64{{1, 2}, {3, 4}}),
+		types.NewSFPolygonXY([][2]float64{{0, 0}, {1, 0}, {1, 1}, {0, 0}}),
+	}
+	for _, g := range geometries {
+		data, err := json.Marshal(g)
+		require.NoError(err)
+		actual, err := types.UnmarshalGeometryJSON(data)
+		require.NoError(err)
+		require.Equal(g, actual)
+		require.Equal(g.BBox(), actual.BBox())
+	}
+
+	// Geometries can be held by struct fields of interface type.
+	type Row struct{ Geom types.SFGeometry }
+	data, err := json.Marshal(Row{geometries[0]})
+	require.NoError(err)
+	require.JSONEq(`{"Geom":{"type":"Point","coordinates":[1,2,3]}}`, string(data))
+
+	g, err := types.UnmarshalGeometryJSON([]byte(`null`))
+	require.NoError(err)
+	require.Nil(g)
+
+	_, err = types.UnmarshalGeometryJSON([]byte(`{"type":"MultiPoint","coordinates":[]}`))
+	require.EqualError(err, `types: unsupported GeoJSON geometry type "MultiPoint"`)
+	_, err = types.UnmarshalGeometryJSON([]byte(`{"type":"Point","coordinates":"1,2"}`))
+	require.Error(err)
+	_, err = types.UnmarshalGeometryJSON([]byte(`[1, 2]`))
+	require.Error(err)
+}
+
+func TestScanGeometry(t *testing.T) {
+	require := require.New(t)
+
+	geometries := []types.SFGeometry{
+		types.NewSFPointXY(1, 2),
+		types.NewSFLineStringXY([][2]float64{{1, 2}, {3, 4}}),
+		types.NewSFPolygonXY([][2]float64{{0, 0}, {1, 0}, {1, 1}, {0, 0}}),
+	}
+	for _, g := range geometries {
+		v, err := g.Value()
+		require.NoError(err)
+		actual, err := types.ScanGeometry(v)
+		require.NoError(err)
+		require.Equal(g, actual)
+	}
+
+	g, err := types.ScanGeometry(nil)
+	require.NoError(err)
+	require.Nil(g)
+
+	mp, err := wkb.Marshal(geom.NewMultiPoint(geom.XY), binary.LittleEndian)
+	require.NoError(err)
+	_, err = types.ScanGeometry(mp)
+	require.EqualError(err, `types: unsupported WKB geometry type "MultiPoint"`)
+	_, err = types.ScanGeometry("POINT (1 2)")
+	require.Error(err)
+}
+
+func TestGeoJSONByteOrderMark(t *testing.T) {
+	require := require.New(t)
+
+	wrap := func(s string) [][]byte {
+		return [][]byte{
+			[]byte("\xef\xbb\xbf" + s),
+			[]byte("\xef\xbb\xbf  " + s + "\n"),
+			[]byte("\r\n\t" + s + "\r\n"),
+		}
+	}
+
+	for _, data := range wrap(`{"type":"Point","coordinates":[1,2]}`) {
+		var p types.SFPoint
+		require.NoError(p.UnmarshalJSON(data), "%q", data)
+		require.Equal(types.NewSFPointXY(1, 2), p)
+
+		g, err := types.UnmarshalGeometryJSON(data)
+		require.NoError(err, "%q", data)
+		require.Equal(types.NewSFPointXY(1, 2), g)
+	}
+	for _, data := range wrap(`{"type":"LineString","coordinates":[[1,2],[3,4]]}`) {
+		var l types.SFLineString
+		require.NoError(l.UnmarshalJSON(data), "%q", data)
+		require.Equal(types.NewSFLineStringXY([][2]float64{{1, 2}, {3, 4}}), l)
+	}
+	for _, data := range wrap(`{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]}`) {
+		var p types.SFPolygon
+		require.NoError(p.UnmarshalJSON(data), "%q", data)
+		require.Equal(types.NewSFPolygonXY([][2]float64{{0, 0}, {1, 0}, {1, 1}, {0, 0}}), p)
+	}
+	for _, data := range wrap(`{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":null}`) {
+		var f types.SFFeature
+		require.NoError(f.UnmarshalJSON(data), "%q", data)
+		require.Equal([]float64{1, 2}, f.Geometry.FlatCoords())
+	}
+
+	// Only a leading byte order mark is ignored.
+	var p types.SFPoint
+	require.Error(p.UnmarshalJSON([]byte(`{"type":"Point","coordinates":[1,2]}` + "\xef\xbb\xbf")))
+	require.Error(p.UnmarshalJSON([]byte("\xef\xbb\xbf\xef\xbb\xbf" + `{"type":"Point","coordinates":[1,2]}`)))
+}
+
+func TestGeoJSONEmitBBox(t *testing.T) {
+	require := require.New(t)
+
+	l := types.NewSFLineStringXY([][2]float64{{3, 1}, {-2, 4}, {0, 0}})
+	data, err := json.Marshal(l)
+	require.NoError(err)
+	require.NotContains(string(data), "bbox")
+
+	types.GeoJSONEmitBBox = true
+	defer func() { types.GeoJSONEmitBBox = false }()
+
+	data, err = json.Marshal(l)
+	require.NoError(err)
+	require.JSONEq(`{"type":"LineString","bbox":[-2,0,3,4],"coordinates":[[3,1],[-2,4],[0,0]]}`, string(data))
+
+	data, err = json.Marshal(types.NewSFPointXYZ(1, 2, 3))
+	require.NoError(err)
+	require.JSONEq(`{"type":"Point","bbox":[1,2,3,1,2,3],"coordinates":[1,2,3]}`, string(data))
+
+	data, err = json.Marshal(types.NewSFPolygonXY(testPolygonExternal, testPolygonInternal))
+	require.NoError(err)
+	require.Contains(string(data), `"bbox":[10,10,40,40]`)
+
+	// Empty geometries have no extent.
+	data, err = json.Marshal(types.NewSFPointEmpty(geom.XY))
+	require.NoError(err)
+	require.JSONEq(`{"type":"Point","coordinates":[]}`, string(data))
+
+	// Emitted bboxes round-trip, and validate.
+	types.ValidateBBox = true
+	defer func() { types.ValidateBBox = false }()
+	data, err = json.Marshal(l)
+	require.NoError(err)
+	var rt types.SFLineString
+	require.NoError(json.Unmarshal(data, &rt))
+	require.Equal(l, rt)
+}
+
+func TestValidateBBox(t *testing.T) {
+	require := require.New(t)
+
+	wrong := `{"type":"LineString","bbox":[0,0,1,1],"coordinates":[[0,0],[2,2]]}`
+	var l types.SFLineString
+	require.NoError(json.Unmarshal([]byte(wrong), &l))
+	require.Equal(types.NewSFLineStringXY([][2]float64{{0, 0}, {2, 2}}), l)
+
+	types.ValidateBBox = true
+	defer func() { types.ValidateBBox = false }()
+
+	l = types.SFLineString{}
+	err := json.Unmarshal([]byte(wrong), &l)
+	require.EqualError(err, "types.SFLineString: bbox [0 0 1 1] does not contain every coordinate of the geometry")
+	require.True(l.IsNil())
+
+	// Loose bboxes are fine; malformed ones are not.
+	var p types.SFPoint
+	require.NoError(json.Unmarshal([]byte(`{"type":"Point","bbox":[0,0,5,5],"coordinates":[1,2]}`), &p))
+	require.Equal(types.NewSFPointXY(1, 2), p)
+	require.Error(json.Unmarshal([]byte(`{"type":"Point","bbox":[0,0,5],"coordinates":[1,2]}`), &p))
+	require.Error(json.Unmarshal([]byte(`{"type":"Point","bbox":[5,5,0,0],"coordinates":[1,2]}`), &p))
+
+	// Z ranges are checked against geometries with Z components.
+	require.NoError(json.Unmarshal([]byte(`{"type":"Point","bbox":[1,2,0,1,2,9],"coordinates":[1,2]}`), &p))
+	require.NoError(json.Unmarshal([]byte(`{"type":"Point","bbox":[1,2,0,1,2,9],"coordinates":[1,2,3]}`), &p))
+	require.Error(json.Unmarshal([]byte(`{"type":"Point","bbox":[1,2,0,1,2,1],"coordinates":[1,2,3]}`), &p))
+
+	var poly types.SFPolygon
+	err = json.Unmarshal([]byte(`{"type":"Polygon","bbox":[0,0,1,1],"coordinates":[[[0,0],[2,0],[2,2],[0,0]]]}`), &poly)
+	require.Error(err)
+}
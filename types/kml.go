@@ -0,0 +1,124 @@
+package types
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+
+	"github.com/twpayne/go-geom"
+)
+
+// KML (Keyhole Markup Language) is the XML dialect read by Google Earth, and
+// most other desktop globes. See https://developers.google.com/kml/ and OGC
+// 07-147r2 for the details.
+//
+// Only encoding is supported. Geometries are written as bare <Point>,
+// <LineString>, and <Polygon> elements by their MarshalKML methods, and are
+// wrapped in <Placemark>s within a complete document by KMLDocument. KML has no
+// notion of a coordinate reference system -- coordinates are always WGS 84
+// longitudes and latitudes -- so SRIDs are not written. Altitudes are written
+// for geometries with a Z component; measures are dropped.
+
+// kmlNamespace is the XML namespace of KML 2.2.
+const kmlNamespace = "http://www.opengis.net/kml/2.2"
+
+// kmlMarshaler is implemented by the SF geometry types that can be encoded as
+// KML.
+type kmlMarshaler interface {
+	MarshalKML() ([]byte, error)
+}
+
+var (
+	_ kmlMarshaler = SFPoint{}
+	_ kmlMarshaler = SFLineString{}
+	_ kmlMarshaler = SFPolygon{}
+)
+
+// appendKMLCoordinates appends the contents of a KML <coordinates> element --
+// a space separated list of lng,lat or lng,lat,alt tuples -- describing the
+// coordinates in flat, to dst.
+func appendKMLCoordinates(dst []byte, layout geom.Layout, flat []float64) []byte {
+	stride, z := layout.Stride(), layout.ZIndex()
+	for i := 0; i+1 < len(flat); i += stride {
+		if i > 0 {
+			dst = append(dst, ' ')
+		}
+		dst = strconv.AppendFloat(dst, flat[i], 'f', -1, 64)
+		dst = append(dst, ',')
+		dst = strconv.AppendFloat(dst, flat[i+1], 'f', -1, 64)
+		if z >= 0 {
+			dst = append(dst, ',')
+			dst = strconv.AppendFloat(dst, flat[i+z], 'f', -1, 64)
+		}
+	}
+	return dst
+}
+
+// appendKMLElement appends the element <name>, containing a <coordinates>
+// element describing the coordinates in flat, to dst.
+func appendKMLElement(dst []byte, name string, layout geom.Layout, flat []float64) []byte {
+	dst = append(dst, "<"+name+"><coordinates>"...)
+	dst = appendKMLCoordinates(dst, layout, flat)
+	return append(dst, "</coordinates></"+name+">"...)
+}
+
+// KMLDocument is a KML document holding a list of placemarks, as opened by
+// Google Earth's File > Open, for instance.
+type KMLDocument struct {
+	// Name, if set, is the name of the document.
+	Name string
+	// Placemarks are written into the document in order.
+	Placemarks []KMLPlacemark
+}
+
+// KMLPlacemark is a named geometry within a KMLDocument.
+type KMLPlacemark struct {
+	// Name and Description, if set, are displayed alongside the geometry.
+	Name        string
+	Description string
+	// Geometry is the placemark's location, and must be an SFPoint,
+	// SFLineString, or SFPolygon.
+	Geometry SFGeometry
+}
+
+// MarshalKML returns the complete KML document described by d, including its
+// XML declaration, with each of its placemarks encoded by the MarshalKML method
+// of its geometry. Names and descriptions are escaped as XML text. An error
+// will be returned if any placemark has a nil Geometry, or a Geometry that
+// can't be encoded as KML.
+func (d KMLDocument) MarshalKML() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<kml xmlns="` + kmlNamespace + `"><Document>`)
+	writeKMLText(&buf, "name", d.Name)
+	for i, pm := range d.Placemarks {
+		m, ok := pm.Geometry.(kmlMarshaler)
+		if !ok {
+			return nil, fmt.Errorf("types.KMLDocument: placemark %d: cannot encode a geometry of type %T as KML", i, pm.Geometry)
+		}
+		g, err := m.MarshalKML()
+		if err != nil {
+			return nil, fmt.Errorf("types.KMLDocument: placemark %d: %v", i, err)
+		}
+		buf.WriteString("<Placemark>")
+		writeKMLText(&buf, "name", pm.Name)
+		writeKMLText(&buf, "description", pm.Description)
+		buf.Write(g)
+		buf.WriteString("</Placemark>")
+	}
+	buf.WriteString("</Document></kml>\n")
+	return buf.Bytes(), nil
+}
+
+// writeKMLText writes the element <name>, containing the XML escaped text, to
+// buf, unless text is empty.
+func writeKMLText(buf *bytes.Buffer, name, text string) {
+	if text == "" {
+		return
+	}
+	buf.WriteString("<" + name + ">")
+	// Writes to a bytes.Buffer never fail.
+	_ = xml.EscapeText(buf, []byte(text))
+	buf.WriteString("</" + name + ">")
+}
@@ -0,0 +1,94 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/pyrrho/encoding/types"
+	"github.com/stretchr/testify/require"
+	"github.com/twpayne/go-geom"
+)
+
+func TestSFPointMarshalKML(t *testing.T) {
+	require := require.New(t)
+
+	data, err := types.NewSFPointXY(-122.0822035425683, 37.42228990140251).MarshalKML()
+	require.NoError(err)
+	require.Equal("<Point><coordinates>-122.0822035425683,37.42228990140251</coordinates></Point>", string(data))
+
+	data, err = types.NewSFPointXYZ(1.5, -2, 100).MarshalKML()
+	require.NoError(err)
+	require.Equal("<Point><coordinates>1.5,-2,100</coordinates></Point>", string(data))
+
+	// Measures are dropped.
+	p := types.NewSFPoint(*geom.NewPointFlat(geom.XYM, []float64{1, 2, 3}))
+	data, err = p.MarshalKML()
+	require.NoError(err)
+	require.Equal("<Point><coordinates>1,2</coordinates></Point>", string(data))
+
+	_, err = types.SFPoint{}.MarshalKML()
+	require.Error(err)
+	_, err = types.NewSFPointEmpty(geom.XY).MarshalKML()
+	require.Error(err)
+}
+
+func TestSFLineStringMarshalKML(t *testing.T) {
+	require := require.New(t)
+
+	data, err := types.NewSFLineStringXY([][2]float64{{0, 0}, {1, 1.5}}).MarshalKML()
+	require.NoError(err)
+	require.Equal("<LineString><coordinates>0,0 1,1.5</coordinates></LineString>", string(data))
+
+	_, err = types.SFLineString{}.MarshalKML()
+	require.Error(err)
+	_, err = types.NewSFLineStringXY([][2]float64{{0, 0}}).MarshalKML()
+	require.Error(err)
+}
+
+func TestSFPolygonMarshalKML(t *testing.T) {
+	require := require.New(t)
+
+	p := types.NewSFPolygonXY(
+		[][2]float64{{0, 0}, {4, 0}, {4, 4}, {0, 0}},
+		[][2]float64{{1, 1}, {2, 1}, {2, 2}, {1, 1}},
+	)
+	data, err := p.MarshalKML()
+	require.NoError(err)
+	require.Equal("<Polygon>"+
+		"<outerBoundaryIs><LinearRing><coordinates>0,0 4,0 4,4 0,0</coordinates></LinearRing></outerBoundaryIs>"+
+		"<innerBoundaryIs><LinearRing><coordinates>1,1 2,1 2,2 1,1</coordinates></LinearRing></innerBoundaryIs>"+
+		"</Polygon>", string(data))
+
+	_, err = types.SFPolygon{}.MarshalKML()
+	require.Error(err)
+}
+
+func TestKMLDocument(t *testing.T) {
+	require := require.New(t)
+
+	d := types.KMLDocument{
+		Name: "Trips",
+		Placemarks: []types.KMLPlacemark{
+			{Name: "Home & Away", Geometry: types.NewSFPointXY(1, 2)},
+			{Description: "<b>route</b>", Geometry: types.NewSFLineStringXY([][2]float64{{1, 2}, {3, 4}})},
+		},
+	}
+	data, err := d.MarshalKML()
+	require.NoError(err)
+	require.Equal(`<?xml version="1.0" encoding="UTF-8"?>`+"\n"+
+		`<kml xmlns="http://www.opengis.net/kml/2.2"><Document><name>Trips</name>`+
+		`<Placemark><name>Home &amp; Away</name><Point><coordinates>1,2</coordinates></Point></Placemark>`+
+		`<Placemark><description>&lt;b&gt;route&lt;/b&gt;</description>`+
+		`<LineString><coordinates>1,2 3,4</coordinates></LineString></Placemark>`+
+		"</Document></kml>\n", string(data))
+
+	data, err = types.KMLDocument{}.MarshalKML()
+	require.NoError(err)
+	require.Contains(string(data), `<Document></Document>`)
+
+	d.Placemarks = append(d.Placemarks, types.KMLPlacemark{Name: "nowhere"})
+	_, err = d.MarshalKML()
+	require.EqualError(err, "types.KMLDocument: placemark 2: cannot encode a geometry of type <nil> as KML")
+	d.Placemarks[2].Geometry = types.SFPoint{}
+	_, err = d.MarshalKML()
+	require.Error(err)
+}
@@ -0,0 +1,97 @@
+package null
+
+// The aggregate functions below follow the rules of their SQL counterparts;
+// null values are skipped, and the result is only null if there were no valid
+// values to aggregate -- that is, if the input is empty, or entirely null. A
+// null in the input does not make the result null, as it would for arithmetic.
+
+// SumInt64 returns the sum of the valid values of is, or a null Int64 if there
+// are none. As with Go's own arithmetic, the sum wraps on overflow.
+func SumInt64(is []Int64) Int64 {
+	var ret Int64
+	for _, i := range is {
+		if i.Valid {
+			ret.Int64 += i.Int64
+			ret.Valid = true
+		}
+	}
+	return ret
+}
+
+// AvgInt64 returns the mean of the valid values of is, as a Float64, or a null
+// Float64 if there are none.
+func AvgInt64(is []Int64) Float64 {
+	var sum float64
+	var n int
+	for _, i := range is {
+		if i.Valid {
+			sum += float64(i.Int64)
+			n++
+		}
+	}
+	if n == 0 {
+		return NullFloat64()
+	}
+	return NewFloat64(sum / float64(n))
+}
+
+// SumFloat64 returns the sum of the valid values of fs, or a null Float64 if
+// there are none.
+func SumFloat64(fs []Float64) Float64 {
+	var ret Float64
+	for _, f := range fs {
+		if f.Valid {
+			ret.Float64 += f.Float64
+			ret.Valid = true
+		}
+	}
+	return ret
+}
+
+// AvgFloat64 returns the mean of the valid values of fs, or a null Float64 if
+// there are none.
+func AvgFloat64(fs []Float64) Float64 {
+	var sum float64
+	var n int
+	for _, f := range fs {
+		if f.Valid {
+			sum += f.Float64
+			n++
+		}
+	}
+	if n == 0 {
+		return NullFloat64()
+	}
+	return NewFloat64(sum / float64(n))
+}
+
+// SumUint8 returns the sum of the valid values of us, or a null Int64 if there
+// are none. The sum is widened to an Int64, as SQL widens the sums of small
+// integer columns, so that it won't overflow.
+func SumUint8(us []Uint8) Int64 {
+	var ret Int64
+	for _, u := range us {
+		if u.Valid {
+			ret.Int64 += int64(u.Uint8)
+			ret.Valid = true
+		}
+	}
+	return ret
+}
+
+// AvgUint8 returns the mean of the valid values of us, as a Float64, or a null
+// Float64 if there are none.
+func AvgUint8(us []Uint8) Float64 {
+	var sum float64
+	var n int
+	for _, u := range us {
+		if u.Valid {
+			sum += float64(u.Uint8)
+			n++
+		}
+	}
+	if n == 0 {
+		return NullFloat64()
+	}
+	return NewFloat64(sum / float64(n))
+}
@@ -0,0 +1,52 @@
+package null_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/pyrrho/encoding/types/null"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInt64Aggregates(t *testing.T) {
+	require := require.New(t)
+
+	is := []null.Int64{null.NewInt64(1), null.NullInt64(), null.NewInt64(2), null.NewInt64(6)}
+	require.Equal(null.NewInt64(9), null.SumInt64(is))
+	require.Equal(null.NewFloat64(3), null.AvgInt64(is))
+
+	// Empty and all-null inputs aggregate to null; zeros don't.
+	for _, is := range [][]null.Int64{nil, {null.NullInt64(), null.NullInt64()}} {
+		require.Equal(null.NullInt64(), null.SumInt64(is))
+		require.Equal(null.NullFloat64(), null.AvgInt64(is))
+	}
+	require.Equal(null.NewInt64(0), null.SumInt64([]null.Int64{null.NullInt64(), null.NewInt64(0)}))
+
+	require.Equal(null.NewInt64(math.MinInt64),
+		null.SumInt64([]null.Int64{null.NewInt64(math.MaxInt64), null.NewInt64(1)}))
+}
+
+func TestFloat64Aggregates(t *testing.T) {
+	require := require.New(t)
+
+	fs := []null.Float64{null.NewFloat64(1.5), null.NullFloat64(), null.NewFloat64(-0.5)}
+	require.Equal(null.NewFloat64(1), null.SumFloat64(fs))
+	require.Equal(null.NewFloat64(0.5), null.AvgFloat64(fs))
+
+	for _, fs := range [][]null.Float64{{}, {null.NullFloat64()}} {
+		require.Equal(null.NullFloat64(), null.SumFloat64(fs))
+		require.Equal(null.NullFloat64(), null.AvgFloat64(fs))
+	}
+}
+
+func TestUint8Aggregates(t *testing.T) {
+	require := require.New(t)
+
+	// Sums are widened, so they don't overflow.
+	us := []null.Uint8{null.NewUint8(255), null.NewUint8(255), null.NullUint8(), null.NewUint8(0)}
+	require.Equal(null.NewInt64(510), null.SumUint8(us))
+	require.Equal(null.NewFloat64(170), null.AvgUint8(us))
+
+	require.Equal(null.NullInt64(), null.SumUint8(nil))
+	require.Equal(null.NullFloat64(), null.AvgUint8([]null.Uint8{null.NullUint8()}))
+}
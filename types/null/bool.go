@@ -1,9 +1,10 @@
 package null
 
 import (
+	"bytes"
 	"database/sql"
-	"encoding/json"
 	"fmt"
+	"strconv"
 )
 
 // Bool is a wrapper around the database/sql NullBool type that implements all
@@ -37,6 +38,33 @@ func NewBool(b bool) Bool {
 		}}
 }
 
+// BoolFromString constructs and returns a new Bool by parsing the string
+// pointed to by s with strconv.ParseBool, which accepts 1, t, T, TRUE, true,
+// True, 0, f, F, FALSE, false, and False. If s is nil or points to an empty
+// string, the new Bool will be null. If the string cannot be parsed, a
+// *ParseError will be returned.
+func BoolFromString(s *string) (Bool, error) {
+	if s == nil || len(*s) == 0 {
+		return NullBool(), nil
+	}
+	v, err := strconv.ParseBool(*s)
+	if err != nil {
+		return Bool{}, parseError("Bool", "BoolFromString", *s, err)
+	}
+	return NewBool(v), nil
+}
+
+// BoolFromSQL constructs and returns a new Bool initialized with the value and
+// validity of the given sql.NullBool n.
+//
+// If n is not valid, the new Bool will be null and zeroed, whatever n holds.
+func BoolFromSQL(n sql.NullBool) Bool {
+	if !n.Valid {
+		return NullBool()
+	}
+	return Bool{n}
+}
+
 // Getters and Setters
 
 // ValueOrZero returns the value of b if it is valid; otherwise, it returns the
@@ -48,18 +76,44 @@ func (b Bool) ValueOrZero() bool {
 	return false
 }
 
+// Or returns b if it is valid, or o otherwise. This is useful for layering a
+// value over a default; eg. userSetting.Or(defaultSetting).
+func (b Bool) Or(o Bool) Bool {
+	if b.Valid {
+		return b
+	}
+	return o
+}
+
 // Set modifies the value stored in b, and guarantees it is valid.
 func (b *Bool) Set(v bool) {
 	b.Bool = v
 	b.Valid = true
 }
 
+// SetPtr sets b to the value pointed to by v, and guarantees it is valid. If v
+// is nil, b will be nulled.
+func (b *Bool) SetPtr(v *bool) {
+	if v == nil {
+		b.Null()
+		return
+	}
+	b.Set(*v)
+}
+
 // Null marks b as null with no meaningful value.
 func (b *Bool) Null() {
 	b.Bool = false
 	b.Valid = false
 }
 
+// SQL returns a pointer to the sql.NullBool embedded in b, for use with APIs
+// that only accept the database/sql null types. Modifications made through the
+// returned pointer will be reflected in b.
+func (b *Bool) SQL() *sql.NullBool {
+	return &b.NullBool
+}
+
 // Interfaces
 
 // IsNil implements the pyrrho/encoding IsNiler interface. It will return true
@@ -74,10 +128,30 @@ func (b Bool) IsZero() bool {
 	return !b.Valid || !b.Bool
 }
 
+// JSONSchemaType implements the JSONSchemaTyper interface. It will return
+// "boolean", and true.
+func (b Bool) JSONSchemaType() (string, bool) {
+	return "boolean", true
+}
+
+// Scan implements the database/sql Scanner interface. It defers to the
+// embedded sql.NullBool, and wraps any failure in a *ParseError.
+func (b *Bool) Scan(src interface{}) error {
+	if b == nil {
+		return fmt.Errorf("null.Bool: Scan called on nil pointer")
+	}
+	if err := b.NullBool.Scan(src); err != nil {
+		return parseError("Bool", "Scan", src, err)
+	}
+	return nil
+}
+
 // MarshalJSON implements the encoding/json Marshaler interface. It will encode
 // b into its JSON representation if valid, or 'null' otherwise.
+//
+// Valid zero values will also encode to 'null' if ZeroAsNull is true.
 func (b Bool) MarshalJSON() ([]byte, error) {
-	if !b.Valid {
+	if !b.Valid || ZeroAsNull && b.IsZero() {
 		return []byte("null"), nil
 	}
 	if !b.Bool {
@@ -93,7 +167,11 @@ func (b Bool) MarshalJSON() ([]byte, error) {
 // The keyword 'null' will result in a null NullBool. The keywords 'true' and
 // 'false' will result in a valid NullBool containing the value you would
 // expect. The strings '"true"', '"false"', '"null"', and `""` are considered to
-// be strings -- not keywords -- and will result in an error.
+// be strings -- not keywords -- and will result in an error, unless
+// BoolAcceptStrings is true, in which case the strings '"true"' and '"1"', and
+// '"false"' and '"0"', will be accepted as their boolean equivalents. JSON
+// objects -- eg. '{"Bool":true,"Valid":true}' -- are never accepted. Strings
+// matching one of JSONNullTokens will result in a null NullBool.
 //
 // If the decode fails, the value of b will be unchanged.
 func (b *Bool) UnmarshalJSON(data []byte) error {
@@ -101,8 +179,8 @@ func (b *Bool) UnmarshalJSON(data []byte) error {
 		return fmt.Errorf("null.Bool: UnmarshalJSON called on nil pointer")
 	}
 	var j interface{}
-	if err := json.Unmarshal(data, &j); err != nil {
-		return err
+	if err := DecodeJSON(data, &j); err != nil {
+		return parseError("Bool", "UnmarshalJSON", data, err)
 	}
 	switch val := j.(type) {
 	case bool:
@@ -113,10 +191,59 @@ func (b *Bool) UnmarshalJSON(data []byte) error {
 		b.Bool = false
 		b.Valid = false
 		return nil
+	case string:
+		if BoolAcceptStrings {
+			switch val {
+			case "true", "1":
+				b.Bool = true
+				b.Valid = true
+				return nil
+			case "false", "0":
+				b.Bool = false
+				b.Valid = true
+				return nil
+			}
+		}
+		return parseError("Bool", "UnmarshalJSON", data,
+			fmt.Errorf("cannot unmarshal JSON string %q into a bool", val))
 	default:
-		return fmt.Errorf("null.Bool: cannot unmarshal JSON of type %T (%v)",
-			val, data)
+		return parseError("Bool", "UnmarshalJSON", data,
+			fmt.Errorf("cannot unmarshal JSON of type %T (%v)", val, data))
+	}
+}
+
+// MarshalText implements the encoding TextMarshaler interface. It will encode b
+// into "true" or "false" if valid, or an empty string otherwise.
+func (b Bool) MarshalText() ([]byte, error) {
+	if !b.Valid {
+		return []byte{}, nil
+	}
+	return strconv.AppendBool(nil, b.Bool), nil
+}
+
+// UnmarshalText implements the encoding TextUnmarshaler interface. It will
+// decode a given []byte into b, so long as the provided []byte -- trimmed of
+// surrounding whitespace -- is the text representation of a bool, as accepted
+// by strconv.ParseBool, or matches one of NullTextTokens, which will decode
+// into a null Bool.
+//
+// If the decode fails, the value of b will be unchanged.
+func (b *Bool) UnmarshalText(text []byte) error {
+	if b == nil {
+		return fmt.Errorf("null.Bool: UnmarshalText called on nil pointer")
 	}
+	if isNullText(text) {
+		b.Bool = false
+		b.Valid = false
+		return nil
+	}
+	v, err := strconv.ParseBool(string(bytes.TrimSpace(text)))
+	if err != nil {
+		return parseError("Bool", "UnmarshalText", text, err)
+	}
+	b.Bool = v
+	b.Valid = true
+	return nil
 }
 
 // MarshalMapValue implements the pyrrho/encoding/maps Marshaler interface. It
@@ -128,3 +255,60 @@ func (b Bool) MarshalMapValue() (interface{}, error) {
 	}
 	return nil, nil
 }
+
+// UnmarshalMapValue implements the pyrrho/encoding/maps Unmarshaler interface.
+// It will decode the bool v into b, or decode nil into a null Bool.
+//
+// If the decode fails, the value of b will be unchanged.
+func (b *Bool) UnmarshalMapValue(v interface{}) error {
+	if b == nil {
+		return fmt.Errorf("null.Bool: UnmarshalMapValue called on nil pointer")
+	}
+	switch val := v.(type) {
+	case nil:
+		b.Null()
+	case bool:
+		b.Set(val)
+	default:
+		return parseError("Bool", "UnmarshalMapValue", v,
+			fmt.Errorf("cannot unmarshal map value of type %T", v))
+	}
+	return nil
+}
+
+// MarshalBinary implements the encoding BinaryMarshaler interface. It will
+// encode b into a validity byte followed by a 1 (true) or 0 (false) byte if
+// valid, or a single zero byte otherwise.
+func (b Bool) MarshalBinary() ([]byte, error) {
+	if !b.Valid {
+		return []byte{0}, nil
+	}
+	if !b.Bool {
+		return []byte{1, 0}, nil
+	}
+	return []byte{1, 1}, nil
+}
+
+// UnmarshalBinary implements the encoding BinaryUnmarshaler interface. It will
+// decode a given []byte into b, so long as the provided []byte was produced by
+// Bool.MarshalBinary.
+//
+// If the decode fails, the value of b will be unchanged.
+func (b *Bool) UnmarshalBinary(data []byte) error {
+	if b == nil {
+		return fmt.Errorf("null.Bool: UnmarshalBinary called on nil pointer")
+	}
+	switch {
+	case len(data) == 1 && data[0] == 0:
+		b.Bool = false
+		b.Valid = false
+		return nil
+	case len(data) == 2 && data[0] == 1 && data[1] <= 1:
+		b.Bool = data[1] == 1
+		b.Valid = true
+		return nil
+	default:
+		return parseError("Bool", "UnmarshalBinary", data,
+			fmt.Errorf("cannot unmarshal binary data (%v)", data))
+	}
+}
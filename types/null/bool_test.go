@@ -1,8 +1,10 @@
 package null_test
 
 import (
+	"database/sql"
 	"database/sql/driver"
 	"encoding/json"
+	"errors"
 	"testing"
 
 	"github.com/pyrrho/encoding/maps"
@@ -228,13 +230,85 @@ func TestBoolUnmarshalJSON(t *testing.T) {
 
 	var invalid null.Bool
 	err = invalid.UnmarshalJSON([]byte(":->"))
-	if _, ok := err.(*json.SyntaxError); !ok {
+	var syntaxErr *json.SyntaxError
+	if !errors.As(err, &syntaxErr) {
 		require.FailNowf(
 			"Unexpected Error Type",
 			"expected *json.SyntaxError, not %T", err)
 	}
 }
 
+func TestBoolAcceptStrings(t *testing.T) {
+	require := require.New(t)
+	defer func(v bool) { null.BoolAcceptStrings = v }(null.BoolAcceptStrings)
+
+	strs := map[string]bool{`"true"`: true, `"1"`: true, `"false"`: false, `"0"`: false}
+	bad := []string{`""`, `"null"`, `"TRUE"`, `"yes"`, `"2"`}
+	// Object forms are rejected, as they are by Int64.
+	objects := []string{`{"Bool":true,"Valid":true}`, `{}`}
+
+	null.BoolAcceptStrings = false
+	for in := range strs {
+		b := null.NewBool(true)
+		require.Error(json.Unmarshal([]byte(in), &b), in)
+		require.Equal(null.NewBool(true), b, in)
+	}
+
+	null.BoolAcceptStrings = true
+	for in, expected := range strs {
+		var b null.Bool
+		require.NoError(json.Unmarshal([]byte(in), &b), in)
+		require.Equal(null.NewBool(expected), b, in)
+	}
+	for _, in := range append(bad, objects...) {
+		b := null.NewBool(true)
+		require.Error(json.Unmarshal([]byte(in), &b), in)
+		require.Equal(null.NewBool(true), b, in)
+	}
+	for _, in := range objects {
+		var i null.Int64
+		require.Error(json.Unmarshal([]byte(in), &i), in)
+	}
+
+	// Keywords are still accepted, and output is never quoted.
+	var b null.Bool
+	require.NoError(json.Unmarshal([]byte("false"), &b))
+	require.Equal(null.NewBool(false), b)
+	require.NoError(json.Unmarshal([]byte("null"), &b))
+	require.Equal(null.NullBool(), b)
+	data, err := json.Marshal([]null.Bool{null.NewBool(true), null.NewBool(false), null.NullBool()})
+	require.NoError(err)
+	require.Equal(`[true,false,null]`, string(data))
+}
+
+func TestBoolMarshalText(t *testing.T) {
+	require := require.New(t)
+
+	data, err := null.NewBool(true).MarshalText()
+	require.NoError(err)
+	require.Equal("true", string(data))
+	data, err = null.NewBool(false).MarshalText()
+	require.NoError(err)
+	require.Equal("false", string(data))
+	data, err = null.NullBool().MarshalText()
+	require.NoError(err)
+	require.Equal("", string(data))
+
+	// MarshalText output round-trips through UnmarshalText.
+	for _, v := range []null.Bool{null.NewBool(true), null.NewBool(false), null.NullBool()} {
+		data, err = v.MarshalText()
+		require.NoError(err)
+		var rt null.Bool
+		require.NoError(rt.UnmarshalText(data))
+		require.Equal(v, rt)
+	}
+
+	// MarshalJSON takes precedence over MarshalText in encoding/json.
+	data, err = json.Marshal(null.NewBool(true))
+	require.NoError(err)
+	require.Equal("true", string(data))
+}
+
 func TestBoolMarshalMapValue(t *testing.T) {
 	require := require.New(t)
 	type Wrapper struct{ Bool null.Bool }
@@ -267,3 +341,99 @@ func TestBoolMarshalMapValue(t *testing.T) {
 	require.NoError(err)
 	require.Equal(map[string]interface{}{"Bool": nil}, data)
 }
+
+func TestBoolMarshalBinary(t *testing.T) {
+	require := require.New(t)
+	var data []byte
+	var err error
+
+	b := null.NewBool(true)
+	data, err = b.MarshalBinary()
+	require.NoError(err)
+	require.Equal([]byte{0x01, 0x01}, data)
+	var rb null.Bool
+	err = rb.UnmarshalBinary(data)
+	require.NoError(err)
+	require.Equal(b, rb)
+
+	f := null.NewBool(false)
+	data, err = f.MarshalBinary()
+	require.NoError(err)
+	require.Equal([]byte{0x01, 0x00}, data)
+	var rf null.Bool
+	err = rf.UnmarshalBinary(data)
+	require.NoError(err)
+	require.Equal(f, rf)
+
+	nul := null.Bool{}
+	data, err = nul.MarshalBinary()
+	require.NoError(err)
+	require.Equal([]byte{0x00}, data)
+	rnul := null.NewBool(true)
+	err = rnul.UnmarshalBinary(data)
+	require.NoError(err)
+	require.Equal(nul, rnul)
+
+	var bad null.Bool
+	err = bad.UnmarshalBinary([]byte{0x01, 0x02})
+	require.Error(err)
+	err = bad.UnmarshalBinary([]byte{0x02})
+	require.Error(err)
+}
+
+func TestBoolSQL(t *testing.T) {
+	require := require.New(t)
+
+	n := sql.NullBool{Bool: true, Valid: true}
+	v := null.BoolFromSQL(n)
+	require.True(v.Valid)
+	require.Equal(n, *v.SQL())
+	require.Equal(null.BoolFromSQL(sql.NullBool{}), null.NullBool())
+
+	// SQL returns a pointer to the embedded value, so it can be scanned into
+	// directly.
+	err := v.SQL().Scan(nil)
+	require.NoError(err)
+	require.False(v.Valid)
+}
+
+func TestBoolSetPtr(t *testing.T) {
+	require := require.New(t)
+
+	v := true
+	var n null.Bool
+	n.SetPtr(&v)
+	require.Equal(null.NewBool(true), n)
+
+	n.SetPtr(nil)
+	require.False(n.Valid)
+	require.Equal(null.NullBool(), n)
+}
+
+func TestBoolFromString(t *testing.T) {
+	require := require.New(t)
+	str := func(s string) *string { return &s }
+
+	for _, s := range []string{"true", "True", "1", "t"} {
+		b, err := null.BoolFromString(str(s))
+		require.NoError(err)
+		require.Equal(null.NewBool(true), b, s)
+	}
+	b, err := null.BoolFromString(str("false"))
+	require.NoError(err)
+	require.Equal(null.NewBool(false), b)
+
+	// Nil pointers and empty strings are null.
+	b, err = null.BoolFromString(nil)
+	require.NoError(err)
+	require.Equal(null.NullBool(), b)
+	b, err = null.BoolFromString(str(""))
+	require.NoError(err)
+	require.Equal(null.NullBool(), b)
+
+	for _, bad := range []string{"yes", "2", "null"} {
+		_, err = null.BoolFromString(str(bad))
+		var pe *null.ParseError
+		require.True(errors.As(err, &pe), bad)
+	}
+}
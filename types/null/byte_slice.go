@@ -5,6 +5,8 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"math"
+	"strconv"
 )
 
 // ByteSlice is a nullable wrapper around the []byte type. It implements all of
@@ -102,6 +104,16 @@ func (b ByteSlice) ValueOrZero() []byte {
 	return b.ByteSlice
 }
 
+// Or returns b if it is valid, or o otherwise. This is useful for layering a
+// value over a default; eg. userSetting.Or(defaultSetting). The underlying
+// []byte is not copied.
+func (b ByteSlice) Or(o ByteSlice) ByteSlice {
+	if b.Valid {
+		return b
+	}
+	return o
+}
+
 // Set copies the given []byte v into b. If v is of length zero, b will be
 // nulled.
 func (b *ByteSlice) Set(v []byte) {
@@ -128,6 +140,16 @@ func (b *ByteSlice) SetStr(v string) {
 	b.Valid = true
 }
 
+// SetPtr sets b to the value pointed to by v, and guarantees it is valid. If v
+// is nil, b will be nulled.
+func (b *ByteSlice) SetPtr(v *[]byte) {
+	if v == nil {
+		b.Null()
+		return
+	}
+	b.Set(*v)
+}
+
 // Null marks b as null with no meaningful value.
 func (b *ByteSlice) Null() {
 	b.ByteSlice = nil
@@ -148,6 +170,16 @@ func (b ByteSlice) IsZero() bool {
 	return !b.Valid || len(b.ByteSlice) == 0
 }
 
+// JSONSchemaType implements the JSONSchemaTyper interface. It will return
+// "string", and true, as MarshalJSON produces base64 strings -- or "array" if
+// ByteSliceAsArray is set.
+func (b ByteSlice) JSONSchemaType() (string, bool) {
+	if ByteSliceAsArray {
+		return "array", true
+	}
+	return "string", true
+}
+
 // Value implements the database/sql/driver Valuer interface. It will base64
 // encode valid values prior to returning them.
 func (b ByteSlice) Value() (driver.Value, error) {
@@ -183,7 +215,7 @@ func (b *ByteSlice) Scan(src interface{}) error {
 		tmp := make([]byte, base64.StdEncoding.DecodedLen(len(val)))
 		n, err := base64.StdEncoding.Decode(tmp, val)
 		if err != nil {
-			return err
+			return parseError("ByteSlice", "Scan", src, err)
 		}
 		b.ByteSlice = tmp[:n]
 		b.Valid = true
@@ -191,35 +223,52 @@ func (b *ByteSlice) Scan(src interface{}) error {
 	case string:
 		tmp, err := base64.StdEncoding.DecodeString(val)
 		if err != nil {
-			return err
+			return parseError("ByteSlice", "Scan", src, err)
 		}
 		b.ByteSlice = tmp
 		b.Valid = true
 		return nil
 	default:
-		return fmt.Errorf("null.ByteSlice: cannot scan type %T (%v)",
-			val, src)
+		return parseError("ByteSlice", "Scan", src,
+			fmt.Errorf("cannot scan type %T (%v)", val, src))
 	}
 }
 
 // MarshalJSON implements the encoding/json Marshaler interface. It will encode
-// b into its base64 representation if valid, or 'null' otherwise.
+// b into its base64 representation if valid, or 'null' otherwise. If
+// ByteSliceAsArray is true, valid values will instead be encoded as an array
+// of numbers.
+//
+// Valid zero values will also encode to 'null' if ZeroAsNull is true.
 func (b ByteSlice) MarshalJSON() ([]byte, error) {
-	if !b.Valid {
+	if !b.Valid || ZeroAsNull && b.IsZero() {
 		return []byte("null"), nil
 	}
+	if ByteSliceAsArray {
+		ret := make([]byte, 0, 2+4*len(b.ByteSlice))
+		ret = append(ret, '[')
+		for i, c := range b.ByteSlice {
+			if i > 0 {
+				ret = append(ret, ',')
+			}
+			ret = strconv.AppendUint(ret, uint64(c), 10)
+		}
+		return append(ret, ']'), nil
+	}
 	// Because we're passing a []byte into json.Marshal, the json package will
 	// handle any base64 decoding that needs to happen.
 	return json.Marshal(b.ByteSlice)
 }
 
 // UnmarshalJSON implements the encoding/json Unmarshaler interface. It will
-// decode a given []byte into b, so long as the provided []byte is
-// a valid base64 encoded string or a null.
+// decode a given []byte into b, so long as the provided []byte is a valid
+// base64 encoded string, an array of integers between 0 and 255, or a null.
 //
-// An empty string will result in a valid-but-empty ByteSlice. The keyword
-// 'null' will result in a null ByteSlice. The string '"null"' is considered
-// to be a string -- not a keyword -- and will result in base64 decoded garbage.
+// An empty string or array will result in a valid-but-empty ByteSlice. The
+// keyword 'null' will result in a null ByteSlice. The string '"null"' is
+// considered to be a string -- not a keyword -- and will result in base64
+// decoded garbage, unless it matches one of JSONNullTokens, in which case it
+// will result in a null ByteSlice.
 //
 // If the decode fails, the value of b will be unchanged.
 func (b *ByteSlice) UnmarshalJSON(data []byte) error {
@@ -227,8 +276,8 @@ func (b *ByteSlice) UnmarshalJSON(data []byte) error {
 		return fmt.Errorf("null.ByteSlice: UnmarshalJSON called on nil pointer")
 	}
 	var j interface{}
-	if err := json.Unmarshal(data, &j); err != nil {
-		return err
+	if err := DecodeJSON(data, &j); err != nil {
+		return parseError("ByteSlice", "UnmarshalJSON", data, err)
 	}
 	switch val := j.(type) {
 	case nil:
@@ -248,14 +297,27 @@ func (b *ByteSlice) UnmarshalJSON(data []byte) error {
 		var tmp []byte
 		err := json.Unmarshal(data, &tmp)
 		if err != nil {
-			return err
+			return parseError("ByteSlice", "UnmarshalJSON", data, err)
+		}
+		b.ByteSlice = tmp
+		b.Valid = true
+		return nil
+	case []interface{}:
+		tmp := make([]byte, len(val))
+		for i, e := range val {
+			n, ok := e.(float64)
+			if !ok || n != math.Trunc(n) || n < 0 || n > math.MaxUint8 {
+				return parseError("ByteSlice", "UnmarshalJSON", data,
+					fmt.Errorf("element %d (%v) is not a byte", i, e))
+			}
+			tmp[i] = byte(n)
 		}
 		b.ByteSlice = tmp
 		b.Valid = true
 		return nil
 	default:
-		return fmt.Errorf("null.ByteSlice: cannot unmarshal JSON of type %T (%v)",
-			val, data)
+		return parseError("ByteSlice", "UnmarshalJSON", data,
+			fmt.Errorf("cannot unmarshal JSON of type %T (%v)", val, data))
 	}
 }
 
@@ -273,3 +335,71 @@ func (b ByteSlice) MarshalMapValue() (interface{}, error) {
 	base64.StdEncoding.Encode(enc, b.ByteSlice)
 	return enc, nil
 }
+
+// UnmarshalMapValue implements the pyrrho/encoding/maps Unmarshaler interface.
+// It will decode the base64 encoded []byte or string v into b, or decode nil
+// into a null ByteSlice.
+//
+// If the decode fails, the value of b will be unchanged.
+func (b *ByteSlice) UnmarshalMapValue(v interface{}) error {
+	if b == nil {
+		return fmt.Errorf("null.ByteSlice: UnmarshalMapValue called on nil pointer")
+	}
+	var enc []byte
+	switch val := v.(type) {
+	case nil:
+		b.Null()
+		return nil
+	case []byte:
+		enc = val
+	case string:
+		enc = []byte(val)
+	default:
+		return parseError("ByteSlice", "UnmarshalMapValue", v,
+			fmt.Errorf("cannot unmarshal map value of type %T", v))
+	}
+	dec := make([]byte, base64.StdEncoding.DecodedLen(len(enc)))
+	n, err := base64.StdEncoding.Decode(dec, enc)
+	if err != nil {
+		return parseError("ByteSlice", "UnmarshalMapValue", v, err)
+	}
+	b.Set(dec[:n])
+	return nil
+}
+
+// MarshalBinary implements the encoding BinaryMarshaler interface. It will
+// encode b into a validity byte followed by its raw -- not base64 encoded --
+// contents if valid, or a single zero byte otherwise.
+func (b ByteSlice) MarshalBinary() ([]byte, error) {
+	if !b.Valid {
+		return []byte{0}, nil
+	}
+	ret := make([]byte, 1, len(b.ByteSlice)+1)
+	ret[0] = 1
+	return append(ret, b.ByteSlice...), nil
+}
+
+// UnmarshalBinary implements the encoding BinaryUnmarshaler interface. It will
+// decode a given []byte into b, so long as the provided []byte was produced by
+// ByteSlice.MarshalBinary. A validity byte with no trailing data will result in
+// a valid-but-empty ByteSlice.
+//
+// If the decode fails, the value of b will be unchanged.
+func (b *ByteSlice) UnmarshalBinary(data []byte) error {
+	if b == nil {
+		return fmt.Errorf("null.ByteSlice: UnmarshalBinary called on nil pointer")
+	}
+	switch {
+	case len(data) == 1 && data[0] == 0:
+		b.ByteSlice = nil
+		b.Valid = false
+		return nil
+	case len(data) >= 1 && data[0] == 1:
+		b.ByteSlice = append([]byte{}, data[1:]...)
+		b.Valid = true
+		return nil
+	default:
+		return parseError("ByteSlice", "UnmarshalBinary", data,
+			fmt.Errorf("cannot unmarshal binary data (%v)", data))
+	}
+}
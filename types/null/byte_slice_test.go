@@ -2,6 +2,7 @@ package null_test
 
 import (
 	"encoding/json"
+	"errors"
 	"testing"
 
 	"github.com/pyrrho/encoding/maps"
@@ -282,13 +283,46 @@ func TestByteSliceUnmarshalJSON(t *testing.T) {
 
 	var invalid null.ByteSlice
 	err = invalid.UnmarshalJSON([]byte(":->"))
-	if _, ok := err.(*json.SyntaxError); !ok {
+	var syntaxErr *json.SyntaxError
+	if !errors.As(err, &syntaxErr) {
 		require.FailNowf(
 			"Unexpected Error Type",
 			"expected *json.SyntaxError, not %T", err)
 	}
 }
 
+func TestByteSliceJSONArray(t *testing.T) {
+	require := require.New(t)
+	defer func(v bool) { null.ByteSliceAsArray = v }(null.ByteSliceAsArray)
+
+	// Arrays are accepted regardless of ByteSliceAsArray.
+	var bs null.ByteSlice
+	require.NoError(json.Unmarshal([]byte(`[104, 105, 0, 255]`), &bs))
+	require.Equal(null.NewByteSlice([]byte{'h', 'i', 0, 255}), bs)
+	require.NoError(json.Unmarshal([]byte(`[]`), &bs))
+	require.Equal(null.NewByteSlice([]byte{}), bs)
+	require.True(bs.Valid)
+	require.NoError(json.Unmarshal([]byte(`null`), &bs))
+	require.False(bs.Valid)
+
+	bs = null.NewByteSliceStr("hi")
+	for _, bad := range []string{`[256]`, `[-1]`, `[1.5]`, `["h"]`, `[[1]]`, `[null]`} {
+		require.Error(json.Unmarshal([]byte(bad), &bs), bad)
+		require.Equal(null.NewByteSliceStr("hi"), bs, bad)
+	}
+
+	null.ByteSliceAsArray = true
+	data, err := json.Marshal(null.NewByteSlice([]byte{'h', 'i', 0, 255}))
+	require.NoError(err)
+	require.Equal(`[104,105,0,255]`, string(data))
+	data, err = json.Marshal(null.NewByteSlice([]byte{}))
+	require.NoError(err)
+	require.Equal(`[]`, string(data))
+	data, err = json.Marshal(null.NullByteSlice())
+	require.NoError(err)
+	require.Equal(`null`, string(data))
+}
+
 func TestByteSliceMarshalMapValue(t *testing.T) {
 	require := require.New(t)
 	type Wrapper struct{ Slice null.ByteSlice }
@@ -321,3 +355,53 @@ func TestByteSliceMarshalMapValue(t *testing.T) {
 	require.NoError(err)
 	require.Equal(map[string]interface{}{"Slice": nil}, data)
 }
+
+func TestByteSliceMarshalBinary(t *testing.T) {
+	require := require.New(t)
+	var data []byte
+	var err error
+
+	b := null.NewByteSliceStr("DAICON V")
+	data, err = b.MarshalBinary()
+	require.NoError(err)
+	require.Equal(append([]byte{0x01}, "DAICON V"...), data)
+	var rb null.ByteSlice
+	err = rb.UnmarshalBinary(data)
+	require.NoError(err)
+	require.Equal(b, rb)
+
+	empty := null.NewByteSlice([]byte{})
+	data, err = empty.MarshalBinary()
+	require.NoError(err)
+	require.Equal([]byte{0x01}, data)
+	var rempty null.ByteSlice
+	err = rempty.UnmarshalBinary(data)
+	require.NoError(err)
+	require.Equal(empty, rempty)
+
+	nul := null.ByteSlice{}
+	data, err = nul.MarshalBinary()
+	require.NoError(err)
+	require.Equal([]byte{0x00}, data)
+	rnul := null.NewByteSliceStr("DAICON V")
+	err = rnul.UnmarshalBinary(data)
+	require.NoError(err)
+	require.Equal(nul, rnul)
+
+	var bad null.ByteSlice
+	err = bad.UnmarshalBinary([]byte{})
+	require.Error(err)
+}
+
+func TestByteSliceSetPtr(t *testing.T) {
+	require := require.New(t)
+
+	v := []byte("DAICON V")
+	var n null.ByteSlice
+	n.SetPtr(&v)
+	require.Equal(null.NewByteSlice([]byte("DAICON V")), n)
+
+	n.SetPtr(nil)
+	require.False(n.Valid)
+	require.Equal(null.NullByteSlice(), n)
+}
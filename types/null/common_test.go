@@ -1,14 +1,74 @@
 package null_test
 
 import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
 	"encoding/json"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/pyrrho/encoding/types/null"
 	"github.com/stretchr/testify/require"
 )
 
-// This file performs no meaningful tests; it's an aggregate for shared state
-// and helper functions that are used in the package's test suite.
+// This file is an aggregate for shared state and helper functions that are used
+// in the package's test suite, and for tests of behavior that is common to
+// every type in the package.
+
+// rowsDriver is a minimal database/sql driver, registered as
+// "null_test_rows", whose queries are a comma separated list of column names,
+// followed by a row of comma separated values for each line. Values are
+// integers, NULLs, or strings.
+type rowsDriver struct{}
+type rowsConn struct{}
+type rowsStmt struct{ query string }
+type rowsRows struct {
+	cols []string
+	rows []string
+}
+
+func (rowsDriver) Open(string) (driver.Conn, error) { return rowsConn{}, nil }
+
+func (rowsConn) Prepare(q string) (driver.Stmt, error) { return rowsStmt{q}, nil }
+func (rowsConn) Close() error                          { return nil }
+func (rowsConn) Begin() (driver.Tx, error)             { return nil, driver.ErrSkip }
+
+func (rowsStmt) Close() error                               { return nil }
+func (rowsStmt) NumInput() int                              { return 0 }
+func (rowsStmt) Exec([]driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+func (s rowsStmt) Query([]driver.Value) (driver.Rows, error) {
+	lines := strings.Split(s.query, "\n")
+	return &rowsRows{strings.Split(lines[0], ","), lines[1:]}, nil
+}
+func (r *rowsRows) Columns() []string { return r.cols }
+func (*rowsRows) Close() error        { return nil }
+
+func (r *rowsRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	vals := strings.Split(r.rows[0], ",")
+	r.rows = r.rows[1:]
+	for i, v := range vals {
+		if v == "NULL" {
+			dest[i] = nil
+		} else if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			dest[i] = n
+		} else {
+			dest[i] = v
+		}
+	}
+	return nil
+}
+
+func init() {
+	sql.Register("null_test_rows", rowsDriver{})
+}
 
 // Descriptive Tests
 // -----------------
@@ -41,3 +101,424 @@ func TestJSONUnmarshalErrors(t *testing.T) {
 	err = json.Unmarshal([]byte(`""`), &is)
 	require.Error(err)
 }
+
+// Shared Tests
+// ------------
+// These test behavior that every type in this package is expected to share.
+
+func TestUnmarshalJSONRejectsTrailingData(t *testing.T) {
+	require := require.New(t)
+
+	// Every UnmarshalJSON implementation in this package parses its input with
+	// encoding/json, which requires that the input be exactly one well-formed
+	// JSON value. Trailing garbage and multiple values are both errors.
+	cases := []struct {
+		name  string
+		dst   func() json.Unmarshaler
+		valid string
+	}{
+		{"Bool", func() json.Unmarshaler { return &null.Bool{} }, `true`},
+		{"ByteSlice", func() json.Unmarshaler { return &null.ByteSlice{} }, `"REFJQ09OIFY="`},
+		{"Float64", func() json.Unmarshaler { return &null.Float64{} }, `1.5`},
+		{"Int64", func() json.Unmarshaler { return &null.Int64{} }, `42`},
+		{"Int64Slice", func() json.Unmarshaler { return &null.Int64Slice{} }, `[1,2,3]`},
+		{"RawJSON", func() json.Unmarshaler { return &null.RawJSON{} }, `{"a":1}`},
+		{"SFPoint", func() json.Unmarshaler { return &null.SFPoint{} }, `{"type":"Point","coordinates":[1.2,2.3]}`},
+		{"SFPolygon", func() json.Unmarshaler { return &null.SFPolygon{} }, `{"type":"Polygon","coordinates":[[[30,10],[40,40],[20,40],[30,10]]]}`},
+		{"String", func() json.Unmarshaler { return &null.String{} }, `"foo"`},
+		{"Time", func() json.Unmarshaler { return &null.Time{} }, `"2012-12-21T21:21:21Z"`},
+		{"Uint8", func() json.Unmarshaler { return &null.Uint8{} }, `42`},
+	}
+	for _, c := range cases {
+		require.NoError(c.dst().UnmarshalJSON([]byte(c.valid)), c.name)
+		require.NoError(c.dst().UnmarshalJSON([]byte(" "+c.valid+"\n")), c.name)
+		require.NoError(c.dst().UnmarshalJSON([]byte("null")), c.name)
+
+		for _, bad := range []string{
+			c.valid + "abc",
+			c.valid + ",",
+			c.valid + " " + c.valid,
+			c.valid + " null",
+			"null " + c.valid,
+			"null,",
+			"null null",
+		} {
+			require.Error(c.dst().UnmarshalJSON([]byte(bad)), "%s: %s", c.name, bad)
+		}
+	}
+}
+
+// binaryStore mimics a key-value store, like Redis, that persists values
+// through the encoding BinaryMarshaler and BinaryUnmarshaler interfaces. The
+// stored bytes are copied, as they would be when sent over the wire.
+type binaryStore map[string][]byte
+
+func (s binaryStore) Set(key string, v encoding.BinaryMarshaler) error {
+	data, err := v.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	s[key] = append([]byte(nil), data...)
+	return nil
+}
+
+func (s binaryStore) Get(key string, dst encoding.BinaryUnmarshaler) error {
+	return dst.UnmarshalBinary(s[key])
+}
+
+func TestBinaryStoreRoundTrip(t *testing.T) {
+	require := require.New(t)
+
+	timeValue := time.Date(2012, 12, 21, 21, 21, 21, 0, time.UTC)
+	ring := [][2]float64{{30, 10}, {40, 40}, {20, 40}, {30, 10}}
+	square := [][2]float64{{0, 0}, {1, 0}, {1, 1}, {0, 0}}
+	cases := []struct {
+		name  string
+		valid encoding.BinaryMarshaler
+		null  encoding.BinaryMarshaler
+		// dst returns a pointer to a valid value distinct from valid, to be
+		// overwritten by each Get.
+		dst func() encoding.BinaryUnmarshaler
+	}{
+		{"Bool", null.NewBool(false), null.NullBool(),
+			func() encoding.BinaryUnmarshaler { v := null.NewBool(true); return &v }},
+		{"ByteSlice", null.NewByteSlice([]byte{0, 1, 2}), null.NullByteSlice(),
+			func() encoding.BinaryUnmarshaler { v := null.NewByteSliceStr("old"); return &v }},
+		{"Float64", null.NewFloat64(-1.5), null.NullFloat64(),
+			func() encoding.BinaryUnmarshaler { v := null.NewFloat64(42); return &v }},
+		{"Int64", null.NewInt64(-42), null.NullInt64(),
+			func() encoding.BinaryUnmarshaler { v := null.NewInt64(7); return &v }},
+		{"Int64Slice", null.NewInt64Slice([]int64{1, 2, 3}), null.NullInt64Slice(),
+			func() encoding.BinaryUnmarshaler { v := null.NewInt64Slice([]int64{7}); return &v }},
+		{"RawJSON", null.NewJSONStr(`{"a":1}`), null.NullJSON(),
+			func() encoding.BinaryUnmarshaler { v := null.NewJSONStr(`true`); return &v }},
+		{"SFPoint", null.NewSFPointXY(1.2, 2.3), null.NullSFPoint(),
+			func() encoding.BinaryUnmarshaler { v := null.NewSFPointXY(4, 5); return &v }},
+		{"SFPolygon", null.NewSFPolygonXY(ring), null.NullSFPolygon(),
+			func() encoding.BinaryUnmarshaler { v := null.NewSFPolygonXY(square); return &v }},
+		{"String", null.NewString(""), null.NullString(),
+			func() encoding.BinaryUnmarshaler { v := null.NewString("old"); return &v }},
+		{"Time", null.NewTime(timeValue), null.NullTime(),
+			func() encoding.BinaryUnmarshaler { v := null.NewTime(timeValue.Add(time.Hour)); return &v }},
+		{"Uint8", null.NewUint8(0), null.NullUint8(),
+			func() encoding.BinaryUnmarshaler { v := null.NewUint8(255); return &v }},
+	}
+
+	deref := func(v encoding.BinaryUnmarshaler) interface{} {
+		return reflect.ValueOf(v).Elem().Interface()
+	}
+	store := binaryStore{}
+	for _, c := range cases {
+		// Valid values, including valid zero values, round-trip exactly, and
+		// replace whatever the destination previously held.
+		require.NoError(store.Set(c.name, c.valid), c.name)
+		got := c.dst()
+		require.NoError(store.Get(c.name, got), c.name)
+		require.Equal(c.valid, deref(got), c.name)
+
+		// Null values round-trip as null, rather than as a zero value or as a
+		// placeholder string.
+		require.NoError(store.Set(c.name, c.null), c.name)
+		got = c.dst()
+		require.NoError(store.Get(c.name, got), c.name)
+		require.Equal(c.null, deref(got), c.name)
+
+		// Missing keys are an error, and leave the destination untouched.
+		got = c.dst()
+		require.Error(store.Get("missing", got), c.name)
+		require.Equal(deref(c.dst()), deref(got), c.name)
+	}
+}
+
+func TestInvalidIsZeroed(t *testing.T) {
+	require := require.New(t)
+
+	timeValue := time.Date(2012, 12, 21, 21, 21, 21, 0, time.UTC)
+	ring := [][2]float64{{30, 10}, {40, 40}, {20, 40}, {30, 10}}
+	cases := []struct {
+		name string
+		// valid returns a pointer to a valid, non-zero value, to be mutated
+		// into a null one.
+		valid func() interface{}
+		null  interface{}
+	}{
+		{"Bool", func() interface{} { v := null.NewBool(true); return &v }, null.NullBool()},
+		{"ByteSlice", func() interface{} { v := null.NewByteSliceStr("old"); return &v }, null.NullByteSlice()},
+		{"Float64", func() interface{} { v := null.NewFloat64(1.5); return &v }, null.NullFloat64()},
+		{"Int64", func() interface{} { v := null.NewInt64(42); return &v }, null.NullInt64()},
+		{"Int64Slice", func() interface{} { v := null.NewInt64Slice([]int64{7}); return &v }, null.NullInt64Slice()},
+		{"RawJSON", func() interface{} { v := null.NewJSONStr(`true`); return &v }, null.NullJSON()},
+		{"SFPoint", func() interface{} { v := null.NewSFPointXY(1.2, 2.3); return &v }, null.NullSFPoint()},
+		{"SFPolygon", func() interface{} { v := null.NewSFPolygonXY(ring); return &v }, null.NullSFPolygon()},
+		{"String", func() interface{} { v := null.NewString("old"); return &v }, null.NullString()},
+		{"Time", func() interface{} { v := null.NewTime(timeValue); return &v }, null.NullTime()},
+		{"Uint8", func() interface{} { v := null.NewUint8(255); return &v }, null.NullUint8()},
+	}
+
+	// Every operation that produces a null value also zeroes the value it
+	// holds, so that the null value is indistinguishable from NullX(). Each
+	// mutation reports whether it's supported by v.
+	mutations := map[string]func(v interface{}) (bool, error){
+		"Null": func(v interface{}) (bool, error) {
+			v.(interface{ Null() }).Null()
+			return true, nil
+		},
+		"SetPtr(nil)": func(v interface{}) (bool, error) {
+			m := reflect.ValueOf(v).MethodByName("SetPtr")
+			if !m.IsValid() {
+				return false, nil
+			}
+			m.Call([]reflect.Value{reflect.Zero(m.Type().In(0))})
+			return true, nil
+		},
+		"Scan(nil)": func(v interface{}) (bool, error) {
+			return true, v.(interface{ Scan(interface{}) error }).Scan(nil)
+		},
+		"UnmarshalJSON(null)": func(v interface{}) (bool, error) {
+			return true, v.(json.Unmarshaler).UnmarshalJSON([]byte("null"))
+		},
+		"UnmarshalBinary": func(v interface{}) (bool, error) {
+			return true, v.(encoding.BinaryUnmarshaler).UnmarshalBinary([]byte{0})
+		},
+		"UnmarshalText": func(v interface{}) (bool, error) {
+			u, ok := v.(encoding.TextUnmarshaler)
+			if !ok {
+				return false, nil
+			}
+			return true, u.UnmarshalText(nil)
+		},
+	}
+	for _, c := range cases {
+		for name, mutate := range mutations {
+			v := c.valid()
+			ok, err := mutate(v)
+			require.NoError(err, "%s: %s", c.name, name)
+			if !ok {
+				continue
+			}
+			require.Equal(c.null, reflect.ValueOf(v).Elem().Interface(), "%s: %s", c.name, name)
+			require.True(v.(interface{ IsNil() bool }).IsNil(), "%s: %s", c.name, name)
+		}
+	}
+
+	// Invalid database/sql values have their stale values dropped.
+	require.Equal(null.NullBool(), null.BoolFromSQL(sql.NullBool{Bool: true}))
+	require.Equal(null.NullFloat64(), null.Float64FromSQL(sql.NullFloat64{Float64: 1.5}))
+	require.Equal(null.NullInt64(), null.Int64FromSQL(sql.NullInt64{Int64: 42}))
+	require.Equal(null.NullString(), null.StringFromSQL(sql.NullString{String: "old"}))
+	require.Equal(null.NullTime(), null.TimeFromSQL(sql.NullTime{Time: timeValue}))
+	require.Equal(null.NullUint8(), null.Uint8FromSQL(sql.NullByte{Byte: 255}))
+}
+
+func TestZeroAsNull(t *testing.T) {
+	require := require.New(t)
+
+	zeroes := []json.Marshaler{
+		null.NewBool(false),
+		null.NewByteSlice([]byte{}),
+		null.NewFloat64(0),
+		null.NewInt64(0),
+		null.NewInt64Slice([]int64{}),
+		null.NewString(""),
+		null.NewTime(time.Time{}),
+		null.NewUint8(0),
+	}
+	nonZeroes := []json.Marshaler{
+		null.NewBool(true),
+		null.NewByteSlice([]byte{0}),
+		null.NewFloat64(-0.5),
+		null.NewInt64(1),
+		null.NewInt64Slice([]int64{0}),
+		null.NewString(" "),
+		null.NewTime(time.Date(2012, 12, 21, 21, 21, 21, 0, time.UTC)),
+		null.NewUint8(1),
+	}
+
+	// By default, valid zero values are emitted as-is.
+	for _, z := range zeroes {
+		data, err := z.MarshalJSON()
+		require.NoError(err)
+		require.NotEqual("null", string(data), "%T", z)
+	}
+
+	null.ZeroAsNull = true
+	defer func() { null.ZeroAsNull = false }()
+
+	for _, z := range zeroes {
+		data, err := z.MarshalJSON()
+		require.NoError(err)
+		require.Equal("null", string(data), "%T", z)
+	}
+	for _, nz := range nonZeroes {
+		data, err := nz.MarshalJSON()
+		require.NoError(err)
+		require.NotEqual("null", string(data), "%T", nz)
+	}
+
+	// The distinction between valid-zero and null is lost on a round trip.
+	data, err := json.Marshal(struct{ I null.Int64 }{null.NewInt64(0)})
+	require.NoError(err)
+	require.Equal(`{"I":null}`, string(data))
+	var rt struct{ I null.Int64 }
+	require.NoError(json.Unmarshal(data, &rt))
+	require.False(rt.I.Valid)
+
+	// Unaffected types keep their valid zero values.
+	data, err = null.NewSFPointXY(0, 0).MarshalJSON()
+	require.NoError(err)
+	require.NotEqual("null", string(data))
+}
+
+func TestJSONNullTokens(t *testing.T) {
+	require := require.New(t)
+
+	type nullable interface {
+		json.Unmarshaler
+		IsNil() bool
+	}
+	fresh := func() []nullable {
+		return []nullable{
+			&null.Bool{}, &null.ByteSlice{}, &null.Float64{}, &null.Int64{},
+			&null.Int64Slice{}, &null.String{}, &null.Time{}, &null.Uint8{},
+		}
+	}
+
+	// By default, "null" is a string like any other, and only String accepts
+	// it as a valid value.
+	for _, v := range fresh() {
+		err := v.UnmarshalJSON([]byte(`"null"`))
+		if _, ok := v.(*null.String); ok {
+			require.NoError(err)
+			require.False(v.IsNil())
+		} else if _, ok := v.(*null.ByteSlice); !ok {
+			require.Error(err, "%T", v)
+		}
+	}
+
+	defer func(v []string) { null.JSONNullTokens = v }(null.JSONNullTokens)
+	null.JSONNullTokens = []string{"null", "nil"}
+
+	for _, input := range []string{`"null"`, `" NIL "`, `null`} {
+		for _, v := range fresh() {
+			require.NoError(v.UnmarshalJSON([]byte(input)), "%T %s", v, input)
+			require.True(v.IsNil(), "%T %s", v, input)
+		}
+	}
+
+	// Other strings, and numbers, are unaffected.
+	var s null.String
+	require.NoError(json.Unmarshal([]byte(`"nothing"`), &s))
+	require.Equal(null.NewString("nothing"), s)
+	var i null.Int64
+	require.Error(json.Unmarshal([]byte(`"none"`), &i))
+	require.NoError(json.Unmarshal([]byte(`0`), &i))
+	require.Equal(null.NewInt64(0), i)
+}
+
+func TestDecodeJSON(t *testing.T) {
+	require := require.New(t)
+
+	var p *int
+	require.NoError(null.DecodeJSON([]byte(`42`), &p))
+	require.Equal(42, *p)
+	require.NoError(null.DecodeJSON([]byte(` null `), &p))
+	require.Nil(p)
+
+	// Non-pointer values are left unchanged by nulls.
+	n := 7
+	require.NoError(null.DecodeJSON([]byte(`null`), &n))
+	require.Equal(7, n)
+
+	// JSONNullTokens are applied, as they are by the built-in types.
+	var s *string
+	require.NoError(null.DecodeJSON([]byte(`"nil"`), &s))
+	require.Equal("nil", *s)
+	defer func(v []string) { null.JSONNullTokens = v }(null.JSONNullTokens)
+	null.JSONNullTokens = []string{"nil"}
+	require.NoError(null.DecodeJSON([]byte(`"nil"`), &s))
+	require.Nil(s)
+	require.Error(null.DecodeJSON([]byte(`"nil" 1`), &s))
+	require.Error(null.DecodeJSON([]byte(`nil`), &s))
+}
+
+func TestJSONSchemaType(t *testing.T) {
+	require := require.New(t)
+
+	cases := []struct {
+		v   null.JSONSchemaTyper
+		typ string
+	}{
+		{null.Bool{}, "boolean"},
+		{null.ByteSlice{}, "string"},
+		{null.Float64{}, "number"},
+		{null.Int64{}, "integer"},
+		{null.Int64Slice{}, "array"},
+		{null.RawJSON{}, ""},
+		{null.SFPoint{}, "object"},
+		{null.SFPolygon{}, "object"},
+		{null.String{}, "string"},
+		{null.Time{}, "string"},
+		{null.Uint8{}, "integer"},
+		{null.Optional[null.Int64]{}, "integer"},
+	}
+	for _, c := range cases {
+		typ, nullable := c.v.JSONSchemaType()
+		require.Equal(c.typ, typ, "%T", c.v)
+		require.True(nullable, "%T", c.v)
+	}
+
+	// Optionals of other types can't describe themselves.
+	typ, nullable := null.Optional[string]{}.JSONSchemaType()
+	require.Equal("", typ)
+	require.False(nullable)
+
+	// Settings that change the output change the schema.
+	defer func(v bool) { null.ByteSliceAsArray = v }(null.ByteSliceAsArray)
+	defer func(v null.UnixMode) { null.TimeUnixMode = v }(null.TimeUnixMode)
+	null.ByteSliceAsArray = true
+	null.TimeUnixMode = null.UnixSeconds
+	typ, _ = null.ByteSlice{}.JSONSchemaType()
+	require.Equal("array", typ)
+	typ, _ = null.Time{}.JSONSchemaType()
+	require.Equal("integer", typ)
+}
+
+func TestOr(t *testing.T) {
+	require := require.New(t)
+
+	timeValue := time.Date(2012, 12, 21, 21, 21, 21, 0, time.UTC)
+	ring := [][2]float64{{30, 10}, {40, 40}, {20, 40}, {30, 10}}
+	square := [][2]float64{{0, 0}, {1, 0}, {1, 1}, {0, 0}}
+	cases := []struct {
+		valid, other, null interface{}
+	}{
+		{null.NewBool(false), null.NewBool(true), null.NullBool()},
+		{null.NewByteSlice([]byte{0, 1, 2}), null.NewByteSliceStr("other"), null.NullByteSlice()},
+		{null.NewFloat64(0), null.NewFloat64(-1.5), null.NullFloat64()},
+		{null.NewInt64(0), null.NewInt64(42), null.NullInt64()},
+		{null.NewInt64Slice([]int64{1, 2, 3}), null.NewInt64Slice([]int64{7}), null.NullInt64Slice()},
+		{null.NewJSONStr(`{"a":1}`), null.NewJSONStr(`true`), null.NullJSON()},
+		{null.NewSFPointXY(1.2, 2.3), null.NewSFPointXY(4, 5), null.NullSFPoint()},
+		{null.NewSFPolygonXY(ring), null.NewSFPolygonXY(square), null.NullSFPolygon()},
+		{null.NewString(""), null.NewString("other"), null.NullString()},
+		{null.NewTime(timeValue), null.NewTime(timeValue.Add(time.Hour)), null.NullTime()},
+		{null.NewUint8(0), null.NewUint8(255), null.NullUint8()},
+	}
+
+	or := func(a, b interface{}) interface{} {
+		return reflect.ValueOf(a).MethodByName("Or").
+			Call([]reflect.Value{reflect.ValueOf(b)})[0].Interface()
+	}
+	for _, c := range cases {
+		// A valid receiver wins, even if it holds a zero value ...
+		require.Equal(c.valid, or(c.valid, c.other), "%T", c.valid)
+		require.Equal(c.valid, or(c.valid, c.null), "%T", c.valid)
+		// ... otherwise the argument is returned, whether or not it's valid.
+		require.Equal(c.other, or(c.null, c.other), "%T", c.valid)
+		require.Equal(c.null, or(c.null, c.null), "%T", c.valid)
+	}
+
+	user, def := null.NullInt64(), null.NewInt64(10)
+	require.Zero(testing.AllocsPerRun(100, func() { user = user.Or(def) }))
+	require.Equal(int64(10), user.Int64)
+}
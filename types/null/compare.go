@@ -0,0 +1,30 @@
+package null
+
+// NullsFirst controls where null values are ordered relative to valid values
+// by the Compare methods of this package's types. If true, null values will
+// sort before all valid values (SQL's NULLS FIRST), otherwise they will sort
+// after all valid values (SQL's NULLS LAST). Two null values are always
+// considered equal.
+var NullsFirst = false
+
+// compareValidity returns the ordering of two values with the given validity,
+// as determined by NullsFirst, and true if either value is null. If both are
+// valid, it returns (0, false) and the caller is expected to compare values.
+func compareValidity(aValid, bValid bool) (int, bool) {
+	switch {
+	case aValid && bValid:
+		return 0, false
+	case !aValid && !bValid:
+		return 0, true
+	case !aValid:
+		if NullsFirst {
+			return -1, true
+		}
+		return 1, true
+	default:
+		if NullsFirst {
+			return 1, true
+		}
+		return -1, true
+	}
+}
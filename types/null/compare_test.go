@@ -0,0 +1,49 @@
+package null_test
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/pyrrho/encoding/types/null"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNullsFirst(t *testing.T) {
+	require := require.New(t)
+	defer func(v bool) { null.NullsFirst = v }(null.NullsFirst)
+
+	values := []null.Int64{
+		null.NewInt64(3),
+		null.NullInt64(),
+		null.NewInt64(-1),
+		null.NullInt64(),
+		null.NewInt64(2),
+	}
+	sortValues := func() {
+		sort.SliceStable(values, func(i, j int) bool {
+			return values[i].Compare(values[j]) < 0
+		})
+	}
+
+	// By default, nulls are ordered last ...
+	null.NullsFirst = false
+	sortValues()
+	require.Equal([]null.Int64{
+		null.NewInt64(-1),
+		null.NewInt64(2),
+		null.NewInt64(3),
+		null.NullInt64(),
+		null.NullInt64(),
+	}, values)
+
+	// ... but they can be moved to the front.
+	null.NullsFirst = true
+	sortValues()
+	require.Equal([]null.Int64{
+		null.NullInt64(),
+		null.NullInt64(),
+		null.NewInt64(-1),
+		null.NewInt64(2),
+		null.NewInt64(3),
+	}, values)
+}
@@ -3,13 +3,32 @@ Package null defines a number of nullable types that bridge the packages
 database/sql, encoding, encoding/json, and encoding/maps. Many are simple
 wrappers around atabase/sql types (sql.NullString, sql.NullInt64, etc.), but all
 implement all of the following interfaces,
- - IsNiler         from pyrrho/encoding       --  IsNil() bool
- - IsZeroer        from pyrrho/encoding       --  IsZero() bool
- - Valuer          from database/sql/driver   --  Value() (driver.Value, error)
- - Scanner         from database/sql          --  Scan(src interface{}) error
- - Marshaler       from encoding/json         --  MarshalJSON() ([]byte, error)
- - Unmarshaler     from encoding/json         --  UnmarshalJSON(data []byte) error
- - Marshaler       from pyrrho/encoding/maps  --  MarshalMap() (map[string]interface{}, error)
- - Unmarshaler     from pyrrho/encoding/maps  --  [Pending maps.Unmarshal features]
+ - IsNiler           from pyrrho/encoding       --  IsNil() bool
+ - IsZeroer          from pyrrho/encoding       --  IsZero() bool
+ - Valuer            from database/sql/driver   --  Value() (driver.Value, error)
+ - Scanner           from database/sql          --  Scan(src interface{}) error
+ - Marshaler         from encoding/json         --  MarshalJSON() ([]byte, error)
+ - Unmarshaler       from encoding/json         --  UnmarshalJSON(data []byte) error
+ - BinaryMarshaler   from encoding              --  MarshalBinary() ([]byte, error)
+ - BinaryUnmarshaler from encoding              --  UnmarshalBinary(data []byte) error
+ - Marshaler         from pyrrho/encoding/maps  --  MarshalMap() (map[string]interface{}, error)
+ - Unmarshaler       from pyrrho/encoding/maps  --  UnmarshalMapValue(v interface{}) error
+
+Bool, Float64, Int64, Time, and Uint8 additionally implement,
+ - TextUnmarshaler   from encoding              --  UnmarshalText(text []byte) error
+so that they can be decoded from sources like CSV files and environment
+variables. Inputs matching one of NullTextTokens decode into null values. Bool
+also implements,
+ - TextMarshaler     from encoding              --  MarshalText() ([]byte, error)
+encoding null values as an empty string.
+
+Key-value stores that serialize values through the encoding interfaces -- such
+as go-redis, which prefers BinaryMarshaler -- should use MarshalBinary and
+UnmarshalBinary. The binary encoding is a validity byte followed by the value,
+so null values are stored as a single zero byte and round-trip as null, rather
+than as a zero value or a placeholder string like "<nil>".
+
+Optional wraps any of these types to add a third state -- absent -- for decoding
+partial documents, such as the bodies of HTTP PATCH requests.
 */
 package null
@@ -0,0 +1,86 @@
+package null
+
+import "fmt"
+
+// ParseError is returned by the Scan and Unmarshal methods of the null types
+// when the given input cannot be decoded. It records which type and method
+// failed, the offending input, and the underlying cause, so that failures can
+// be detected with errors.As and reported uniformly.
+type ParseError struct {
+	// Type is the name of the null type being decoded into; e.g. "Int64".
+	Type string
+	// Func is the name of the method that failed; e.g. "Scan" or
+	// "UnmarshalJSON".
+	Func string
+	// Input is the value that could not be decoded; the src given to Scan, or
+	// a copy of the []byte given to an Unmarshal method.
+	Input interface{}
+	// Err is the underlying cause of the failure.
+	Err error
+}
+
+// DebugScan controls whether the errors returned by the null types' Scan
+// methods describe the value handed over by the database driver. When true, the
+// message of a Scan *ParseError will include the Go type of its Input, and its
+// value, truncated to debugScanMaxLen bytes; eg.
+//
+//	null.Int64: cannot scan []uint8 "abc" into Int64: <cause>
+//
+// This is off by default, as column values may well be sensitive, and error
+// messages tend to end up in logs.
+var DebugScan = false
+
+// debugScanMaxLen is the number of bytes of a Scan input DebugScan will include
+// in an error message.
+const debugScanMaxLen = 32
+
+// Error implements the error interface.
+func (e *ParseError) Error() string {
+	if DebugScan && e.Func == "Scan" {
+		return fmt.Sprintf("null.%s: cannot scan %T %s into %s: %v",
+			e.Type, e.Input, debugScanValue(e.Input), e.Type, e.Err)
+	}
+	return fmt.Sprintf("null.%s: %v", e.Type, e.Err)
+}
+
+// Unwrap returns the underlying cause of e.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// parseError constructs a *ParseError. []byte inputs are copied, as callers
+// (encoding/json in particular) may reuse their buffers.
+func parseError(typ, fn string, input interface{}, err error) error {
+	if b, ok := input.([]byte); ok {
+		input = append([]byte(nil), b...)
+	}
+	return &ParseError{
+		Type:  typ,
+		Func:  fn,
+		Input: input,
+		Err:   err,
+	}
+}
+
+// debugScanValue formats v for inclusion in a DebugScan error message. Strings
+// and []bytes are quoted, and all values are truncated to debugScanMaxLen
+// bytes.
+func debugScanValue(v interface{}) string {
+	var s string
+	switch v := v.(type) {
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	default:
+		s = fmt.Sprintf("%v", v)
+		if len(s) > debugScanMaxLen {
+			s = s[:debugScanMaxLen] + "..."
+		}
+		return s
+	}
+	if len(s) > debugScanMaxLen {
+		return fmt.Sprintf("%q...", s[:debugScanMaxLen])
+	}
+	return fmt.Sprintf("%q", s)
+}
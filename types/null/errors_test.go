@@ -0,0 +1,132 @@
+package null_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/pyrrho/encoding/types/null"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseErrorUnmarshalJSON(t *testing.T) {
+	require := require.New(t)
+
+	for _, tc := range []struct {
+		typ  string
+		dst  json.Unmarshaler
+		data string
+	}{
+		{"Bool", &null.Bool{}, `"true"`},
+		{"ByteSlice", &null.ByteSlice{}, `42`},
+		{"Float64", &null.Float64{}, `"1.5"`},
+		{"Int64", &null.Int64{}, `1.5`},
+		{"Int64Slice", &null.Int64Slice{}, `[null]`},
+		{"RawJSON", &null.RawJSON{}, `:->`},
+		{"SFPoint", &null.SFPoint{}, `{"type":"Bogus"}`},
+		{"SFPolygon", &null.SFPolygon{}, `{"type":"Bogus"}`},
+		{"String", &null.String{}, `42`},
+		{"Time", &null.Time{}, `"yesterday"`},
+		{"Uint8", &null.Uint8{}, `256`},
+	} {
+		err := tc.dst.UnmarshalJSON([]byte(tc.data))
+		var pe *null.ParseError
+		require.True(errors.As(err, &pe), "%s: %T", tc.typ, err)
+		require.Equal(tc.typ, pe.Type)
+		require.Equal("UnmarshalJSON", pe.Func)
+		require.Equal([]byte(tc.data), pe.Input)
+		require.NotNil(pe.Unwrap(), tc.typ)
+		require.Contains(err.Error(), "null."+tc.typ+": ")
+	}
+}
+
+func TestParseErrorScan(t *testing.T) {
+	require := require.New(t)
+
+	type scanner interface {
+		Scan(interface{}) error
+	}
+	for _, tc := range []struct {
+		typ string
+		dst scanner
+		src interface{}
+	}{
+		{"Bool", &null.Bool{}, "maybe"},
+		{"ByteSlice", &null.ByteSlice{}, "!!!"},
+		{"Float64", &null.Float64{}, "one"},
+		{"Int64", &null.Int64{}, "one"},
+		{"Int64Slice", &null.Int64Slice{}, "{1,NULL}"},
+		{"RawJSON", &null.RawJSON{}, 42},
+		{"SFPoint", &null.SFPoint{}, 42},
+		{"SFPolygon", &null.SFPolygon{}, 42},
+		{"String", &null.String{}, struct{}{}},
+		{"Time", &null.Time{}, 42},
+		{"Uint8", &null.Uint8{}, 256},
+	} {
+		err := tc.dst.Scan(tc.src)
+		var pe *null.ParseError
+		require.True(errors.As(err, &pe), "%s: %T", tc.typ, err)
+		require.Equal(tc.typ, pe.Type)
+		require.Equal("Scan", pe.Func)
+		require.NotNil(pe.Unwrap(), tc.typ)
+	}
+}
+
+func TestParseErrorUnwrap(t *testing.T) {
+	require := require.New(t)
+
+	// The underlying cause is reachable through errors.As ...
+	var b null.ByteSlice
+	err := b.Scan("!!!")
+	var b64Err base64.CorruptInputError
+	require.True(errors.As(err, &b64Err))
+
+	// ... including when nested inside of a larger decode.
+	var doc struct {
+		Name  string
+		Count null.Int64
+	}
+	err = json.Unmarshal([]byte(`{"Name":"n","Count":"three"}`), &doc)
+	var pe *null.ParseError
+	require.True(errors.As(err, &pe))
+	require.Equal("Int64", pe.Type)
+	require.Equal([]byte(`"three"`), pe.Input)
+
+	// Input is a copy, and is unaffected by later changes to the source.
+	var i null.Int64
+	data := []byte(`1.5`)
+	err = i.UnmarshalJSON(data)
+	require.True(errors.As(err, &pe))
+	data[0] = '9'
+	require.Equal([]byte(`1.5`), pe.Input)
+}
+
+func TestDebugScan(t *testing.T) {
+	require := require.New(t)
+	defer func(v bool) { null.DebugScan = v }(null.DebugScan)
+
+	var i null.Int64
+	err := i.Scan([]byte("abc"))
+	require.Error(err)
+
+	null.DebugScan = false
+	require.NotContains(err.Error(), "cannot scan")
+
+	null.DebugScan = true
+	require.Contains(err.Error(), `null.Int64: cannot scan []uint8 "abc" into Int64: `)
+
+	// Long values are truncated.
+	err = i.Scan(strings.Repeat("x", 100))
+	require.Contains(err.Error(), `cannot scan string "`+strings.Repeat("x", 32)+`"... into Int64`)
+
+	// Non-string inputs are formatted with their default format.
+	var u null.Uint8
+	err = u.Scan(int64(256))
+	require.Contains(err.Error(), "cannot scan int64 256 into Uint8: ")
+
+	// Only Scan errors are affected.
+	err = i.UnmarshalJSON([]byte(`"abc"`))
+	require.NotContains(err.Error(), "cannot scan")
+}
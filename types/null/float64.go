@@ -1,7 +1,9 @@
 package null
 
 import (
+	"bytes"
 	"database/sql"
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
 	"math"
@@ -9,6 +11,23 @@ import (
 	"strconv"
 )
 
+// FloatFormatting describes how Float64.MarshalJSON formats valid values. Verb
+// and Precision have the same meaning as the fmt and prec arguments of
+// strconv.FormatFloat, but only the 'e', 'E', 'f', 'g', and 'G' verbs -- those
+// that produce valid JSON numbers -- are supported.
+type FloatFormatting struct {
+	Verb      byte
+	Precision int
+}
+
+// FloatFormat is the FloatFormatting used by Float64.MarshalJSON. It defaults
+// to the shortest decimal representation that round-trips ('f', -1).
+//
+// Formatting is done with strconv, and is independent of the environment's
+// locale; the decimal separator is always '.'. Likewise, per the JSON spec,
+// UnmarshalJSON will only accept '.' separated numbers.
+var FloatFormat = FloatFormatting{Verb: 'f', Precision: -1}
+
 // Float64 is a wrapper around the database/sql NullFloat64 type that implements
 // all of the pyrrho/encoding/types interfaces detailed in the package comments
 // that sql.NullFloat64 doesn't implement out of the box.
@@ -40,6 +59,32 @@ func NewFloat64(f float64) Float64 {
 		}}
 }
 
+// Float64FromString constructs and returns a new Float64 by parsing the string
+// pointed to by s as a floating point number. If s is nil or points to an empty
+// string, the new Float64 will be null. If the string cannot be parsed, a
+// *ParseError will be returned.
+func Float64FromString(s *string) (Float64, error) {
+	if s == nil || len(*s) == 0 {
+		return NullFloat64(), nil
+	}
+	v, err := strconv.ParseFloat(*s, 64)
+	if err != nil {
+		return Float64{}, parseError("Float64", "Float64FromString", *s, err)
+	}
+	return NewFloat64(v), nil
+}
+
+// Float64FromSQL constructs and returns a new Float64 initialized with the
+// value and validity of the given sql.NullFloat64 n.
+//
+// If n is not valid, the new Float64 will be null and zeroed, whatever n holds.
+func Float64FromSQL(n sql.NullFloat64) Float64 {
+	if !n.Valid {
+		return NullFloat64()
+	}
+	return Float64{n}
+}
+
 // Getters and Setters
 
 // ValueOrZero returns the value of f if it is valid; otherwise it returns the
@@ -51,18 +96,44 @@ func (f Float64) ValueOrZero() float64 {
 	return f.Float64
 }
 
+// Or returns f if it is valid, or o otherwise. This is useful for layering a
+// value over a default; eg. userSetting.Or(defaultSetting).
+func (f Float64) Or(o Float64) Float64 {
+	if f.Valid {
+		return f
+	}
+	return o
+}
+
 // Set modifies the value stored in f, and guarantees it is valid.
 func (f *Float64) Set(v float64) {
 	f.Float64 = v
 	f.Valid = true
 }
 
+// SetPtr sets f to the value pointed to by v, and guarantees it is valid. If v
+// is nil, f will be nulled.
+func (f *Float64) SetPtr(v *float64) {
+	if v == nil {
+		f.Null()
+		return
+	}
+	f.Set(*v)
+}
+
 // Null marks f as null with no meaningful value.
 func (f *Float64) Null() {
 	f.Float64 = 0.0
 	f.Valid = false
 }
 
+// SQL returns a pointer to the sql.NullFloat64 embedded in f, for use with APIs
+// that only accept the database/sql null types. Modifications made through the
+// returned pointer will be reflected in f.
+func (f *Float64) SQL() *sql.NullFloat64 {
+	return &f.NullFloat64
+}
+
 // Interface
 
 // IsNil implements the pyrrho/encoding IsNiler interface. It will return true
@@ -77,12 +148,33 @@ func (f Float64) IsZero() bool {
 	return !f.Valid || f.Float64 == 0.0
 }
 
+// JSONSchemaType implements the JSONSchemaTyper interface. It will return
+// "number", and true.
+func (f Float64) JSONSchemaType() (string, bool) {
+	return "number", true
+}
+
+// Scan implements the database/sql Scanner interface. It defers to the
+// embedded sql.NullFloat64, and wraps any failure in a *ParseError.
+func (f *Float64) Scan(src interface{}) error {
+	if f == nil {
+		return fmt.Errorf("null.Float64: Scan called on nil pointer")
+	}
+	if err := f.NullFloat64.Scan(src); err != nil {
+		return parseError("Float64", "Scan", src, err)
+	}
+	return nil
+}
+
 // MarshalJSON implements the encoding/json Marshaler interface. It will attempt
-// to encode f into its JSON representation if valid. If the contained value is
-// +/-INF or NaN, a json.UnsupportedValueError will be returned. If f is not
-// valid, it will encode to 'null'.
+// to encode f into its JSON representation, formatted according to FloatFormat,
+// if valid. If the contained value is +/-INF or NaN, a
+// json.UnsupportedValueError will be returned. If f is not valid, it will
+// encode to 'null'.
+//
+// Valid zero values will also encode to 'null' if ZeroAsNull is true.
 func (f Float64) MarshalJSON() ([]byte, error) {
-	if !f.Valid {
+	if !f.Valid || ZeroAsNull && f.IsZero() {
 		return []byte("null"), nil
 	}
 	if math.IsInf(f.Float64, 0) || math.IsNaN(f.Float64) {
@@ -91,13 +183,18 @@ func (f Float64) MarshalJSON() ([]byte, error) {
 			Str:   strconv.FormatFloat(f.Float64, 'g', -1, 64),
 		}
 	}
-	return []byte(strconv.FormatFloat(f.Float64, 'f', -1, 64)), nil
+	switch FloatFormat.Verb {
+	case 'e', 'E', 'f', 'g', 'G':
+	default:
+		return nil, fmt.Errorf("null.Float64: unsupported FloatFormat verb %q", FloatFormat.Verb)
+	}
+	return strconv.AppendFloat(nil, f.Float64, FloatFormat.Verb, FloatFormat.Precision, 64), nil
 }
 
 // UnmarshalJSON implements the encoding/json Unmarshaler interface. It will
 // decode a given []byte into f, so long as the provided []byte is a valid JSON
 // representation of a float or null. The 'null' keyword will decode into a null
-// Float64.
+// Float64, as will strings matching one of JSONNullTokens.
 //
 // If the decode fails, the value of f will be unchanged.
 func (f *Float64) UnmarshalJSON(data []byte) error {
@@ -105,8 +202,8 @@ func (f *Float64) UnmarshalJSON(data []byte) error {
 		return fmt.Errorf("null.Float64: UnmarshalJSON called on nil pointer")
 	}
 	var j interface{}
-	if err := json.Unmarshal(data, &j); err != nil {
-		return err
+	if err := DecodeJSON(data, &j); err != nil {
+		return parseError("Float64", "UnmarshalJSON", data, err)
 	}
 	switch val := j.(type) {
 	case float64:
@@ -117,12 +214,39 @@ func (f *Float64) UnmarshalJSON(data []byte) error {
 		f.Float64 = 0
 		f.Valid = false
 		return nil
+	case string:
+		return parseError("Float64", "UnmarshalJSON", data,
+			fmt.Errorf("cannot unmarshal JSON string %q into a float", val))
 	default:
-		return fmt.Errorf("null.Float64: cannot unmarshal JSON of type %T (%v)",
-			val, data)
+		return parseError("Float64", "UnmarshalJSON", data,
+			fmt.Errorf("cannot unmarshal JSON of type %T (%v)", val, data))
 	}
 }
 
+// UnmarshalText implements the encoding TextUnmarshaler interface. It will
+// decode a given []byte into f, so long as the provided []byte -- trimmed of
+// surrounding whitespace -- is the text representation of a float, or matches
+// one of NullTextTokens, which will decode into a null Float64.
+//
+// If the decode fails, the value of f will be unchanged.
+func (f *Float64) UnmarshalText(text []byte) error {
+	if f == nil {
+		return fmt.Errorf("null.Float64: UnmarshalText called on nil pointer")
+	}
+	if isNullText(text) {
+		f.Float64 = 0
+		f.Valid = false
+		return nil
+	}
+	v, err := strconv.ParseFloat(string(bytes.TrimSpace(text)), 64)
+	if err != nil {
+		return parseError("Float64", "UnmarshalText", text, err)
+	}
+	f.Float64 = v
+	f.Valid = true
+	return nil
+}
+
 // MarshalMapValue implements the pyrrho/encoding/maps Marshaler interface. It
 // will encode f into its interface{} representation for use in a
 // map[string]interface{} if valid, or return nil otherwise.
@@ -132,3 +256,81 @@ func (f Float64) MarshalMapValue() (interface{}, error) {
 	}
 	return nil, nil
 }
+
+// UnmarshalMapValue implements the pyrrho/encoding/maps Unmarshaler interface.
+// It will decode the float or integer v into f, or decode nil into a null
+// Float64.
+//
+// If the decode fails, the value of f will be unchanged.
+func (f *Float64) UnmarshalMapValue(v interface{}) error {
+	if f == nil {
+		return fmt.Errorf("null.Float64: UnmarshalMapValue called on nil pointer")
+	}
+	if v == nil {
+		f.Null()
+		return nil
+	}
+	val, err := mapValueFloat64(v)
+	if err != nil {
+		return parseError("Float64", "UnmarshalMapValue", v, err)
+	}
+	f.Set(val)
+	return nil
+}
+
+// MarshalBinary implements the encoding BinaryMarshaler interface. It will
+// encode f into a validity byte followed by the 8 byte little-endian IEEE 754
+// representation of its value if valid, or a single zero byte otherwise.
+func (f Float64) MarshalBinary() ([]byte, error) {
+	if !f.Valid {
+		return []byte{0}, nil
+	}
+	ret := make([]byte, 9)
+	ret[0] = 1
+	binary.LittleEndian.PutUint64(ret[1:], math.Float64bits(f.Float64))
+	return ret, nil
+}
+
+// UnmarshalBinary implements the encoding BinaryUnmarshaler interface. It will
+// decode a given []byte into f, so long as the provided []byte was produced by
+// Float64.MarshalBinary.
+//
+// If the decode fails, the value of f will be unchanged.
+func (f *Float64) UnmarshalBinary(data []byte) error {
+	if f == nil {
+		return fmt.Errorf("null.Float64: UnmarshalBinary called on nil pointer")
+	}
+	switch {
+	case len(data) == 1 && data[0] == 0:
+		f.Float64 = 0
+		f.Valid = false
+		return nil
+	case len(data) == 9 && data[0] == 1:
+		f.Float64 = math.Float64frombits(binary.LittleEndian.Uint64(data[1:]))
+		f.Valid = true
+		return nil
+	default:
+		return parseError("Float64", "UnmarshalBinary", data,
+			fmt.Errorf("cannot unmarshal binary data (%v)", data))
+	}
+}
+
+// Compare returns -1 if f is ordered before o, 1 if f is ordered after o, and 0
+// if they're equal. Null values are ordered according to NullsFirst. Valid NaN
+// values are ordered before all other valid values, and are equal to each
+// other.
+func (f Float64) Compare(o Float64) int {
+	if c, ok := compareValidity(f.Valid, o.Valid); ok {
+		return c
+	}
+	fNaN, oNaN := math.IsNaN(f.Float64), math.IsNaN(o.Float64)
+	switch {
+	case fNaN && oNaN:
+		return 0
+	case fNaN || f.Float64 < o.Float64:
+		return -1
+	case oNaN || f.Float64 > o.Float64:
+		return 1
+	}
+	return 0
+}
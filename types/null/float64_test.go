@@ -1,8 +1,10 @@
 package null_test
 
 import (
+	"database/sql"
 	"database/sql/driver"
 	"encoding/json"
+	"errors"
 	"math"
 	"testing"
 
@@ -205,6 +207,58 @@ func TestFloat64MarshalJSON(t *testing.T) {
 	require.Error(err)
 }
 
+func TestFloat64FloatFormat(t *testing.T) {
+	require := require.New(t)
+	var data []byte
+	var err error
+
+	defer func(ff null.FloatFormatting) { null.FloatFormat = ff }(null.FloatFormat)
+
+	// Formatting ignores the environment's locale.
+	t.Setenv("LC_ALL", "de_DE.UTF-8")
+	t.Setenv("LC_NUMERIC", "de_DE.UTF-8")
+	t.Setenv("LANG", "de_DE.UTF-8")
+
+	f := null.NewFloat64(1234.5)
+	data, err = json.Marshal(f)
+	require.NoError(err)
+	require.Equal("1234.5", string(data))
+
+	null.FloatFormat = null.FloatFormatting{Verb: 'g', Precision: 3}
+	data, err = json.Marshal(f)
+	require.NoError(err)
+	require.Equal("1.23e+03", string(data))
+
+	null.FloatFormat = null.FloatFormatting{Verb: 'f', Precision: 2}
+	data, err = json.Marshal(null.NewFloat64(0.125))
+	require.NoError(err)
+	require.Equal("0.12", string(data))
+
+	null.FloatFormat = null.FloatFormatting{Verb: 'E', Precision: -1}
+	data, err = json.Marshal(f)
+	require.NoError(err)
+	require.Equal("1.2345E+03", string(data))
+	var rt null.Float64
+	err = json.Unmarshal(data, &rt)
+	require.NoError(err)
+	require.Equal(f, rt)
+
+	// Nulls are unaffected, and verbs that don't produce JSON numbers are
+	// rejected.
+	null.FloatFormat = null.FloatFormatting{Verb: 'x', Precision: -1}
+	data, err = json.Marshal(null.Float64{})
+	require.NoError(err)
+	require.Equal("null", string(data))
+	_, err = json.Marshal(f)
+	require.Error(err)
+
+	// Only '.' is accepted as a decimal separator.
+	err = json.Unmarshal([]byte("1234,5"), &rt)
+	require.Error(err)
+	err = json.Unmarshal([]byte(`"1234,5"`), &rt)
+	require.Error(err)
+}
+
 func TestFloat64UnmarshalJSON(t *testing.T) {
 	require := require.New(t)
 	var err error
@@ -266,7 +320,8 @@ func TestFloat64UnmarshalJSON(t *testing.T) {
 
 	var invalid null.Float64
 	err = invalid.UnmarshalJSON([]byte(":->"))
-	if _, ok := err.(*json.SyntaxError); !ok {
+	var syntaxErr *json.SyntaxError
+	if !errors.As(err, &syntaxErr) {
 		require.FailNowf(
 			"Unexpected Error Type",
 			"expected *json.SyntaxError, not %T", err)
@@ -305,3 +360,112 @@ func TestFloat64MarshalMapValue(t *testing.T) {
 	require.NoError(err)
 	require.Equal(map[string]interface{}{"Float64": nil}, data)
 }
+
+func TestFloat64MarshalBinary(t *testing.T) {
+	require := require.New(t)
+	var data []byte
+	var err error
+
+	f := null.NewFloat64(1.2345)
+	data, err = f.MarshalBinary()
+	require.NoError(err)
+	require.Len(data, 9)
+	var rf null.Float64
+	err = rf.UnmarshalBinary(data)
+	require.NoError(err)
+	require.Equal(f, rf)
+
+	// Unlike JSON, the binary encoding can represent +/-INF and NaN.
+	inf := null.NewFloat64(math.Inf(-1))
+	data, err = inf.MarshalBinary()
+	require.NoError(err)
+	var rinf null.Float64
+	err = rinf.UnmarshalBinary(data)
+	require.NoError(err)
+	require.Equal(inf, rinf)
+
+	nul := null.Float64{}
+	data, err = nul.MarshalBinary()
+	require.NoError(err)
+	require.Equal([]byte{0x00}, data)
+	rnul := null.NewFloat64(1.2345)
+	err = rnul.UnmarshalBinary(data)
+	require.NoError(err)
+	require.Equal(nul, rnul)
+
+	var bad null.Float64
+	err = bad.UnmarshalBinary([]byte{})
+	require.Error(err)
+	err = bad.UnmarshalBinary([]byte{0x01, 0x02, 0x03})
+	require.Error(err)
+}
+
+func TestFloat64Compare(t *testing.T) {
+	require := require.New(t)
+
+	require.Equal(-1, null.NewFloat64(-1.5).Compare(null.NewFloat64(1.5)))
+	require.Equal(1, null.NewFloat64(1.5).Compare(null.NewFloat64(-1.5)))
+	require.Equal(0, null.NewFloat64(1.5).Compare(null.NewFloat64(1.5)))
+	require.Equal(0, null.NullFloat64().Compare(null.NullFloat64()))
+	require.Equal(1, null.NullFloat64().Compare(null.NewFloat64(0)))
+	require.Equal(-1, null.NewFloat64(0).Compare(null.NullFloat64()))
+
+	// NaNs are ordered before all other valid values.
+	nan := null.NewFloat64(math.NaN())
+	require.Equal(0, nan.Compare(nan))
+	require.Equal(-1, nan.Compare(null.NewFloat64(math.Inf(-1))))
+	require.Equal(1, null.NewFloat64(math.Inf(-1)).Compare(nan))
+	require.Equal(-1, nan.Compare(null.NullFloat64()))
+}
+
+func TestFloat64SQL(t *testing.T) {
+	require := require.New(t)
+
+	n := sql.NullFloat64{Float64: 1.2345, Valid: true}
+	v := null.Float64FromSQL(n)
+	require.True(v.Valid)
+	require.Equal(n, *v.SQL())
+	require.Equal(null.Float64FromSQL(sql.NullFloat64{}), null.NullFloat64())
+
+	// SQL returns a pointer to the embedded value, so it can be scanned into
+	// directly.
+	err := v.SQL().Scan(nil)
+	require.NoError(err)
+	require.False(v.Valid)
+}
+
+func TestFloat64SetPtr(t *testing.T) {
+	require := require.New(t)
+
+	v := 1.2345
+	var n null.Float64
+	n.SetPtr(&v)
+	require.Equal(null.NewFloat64(1.2345), n)
+
+	n.SetPtr(nil)
+	require.False(n.Valid)
+	require.Equal(null.NullFloat64(), n)
+}
+
+func TestFloat64FromString(t *testing.T) {
+	require := require.New(t)
+	str := func(s string) *string { return &s }
+
+	f, err := null.Float64FromString(str("-1.5e3"))
+	require.NoError(err)
+	require.Equal(null.NewFloat64(-1500), f)
+
+	// Nil pointers and empty strings are null.
+	f, err = null.Float64FromString(nil)
+	require.NoError(err)
+	require.Equal(null.NullFloat64(), f)
+	f, err = null.Float64FromString(str(""))
+	require.NoError(err)
+	require.Equal(null.NullFloat64(), f)
+
+	for _, bad := range []string{"abc", "1,5", "1.5.5"} {
+		_, err = null.Float64FromString(str(bad))
+		var pe *null.ParseError
+		require.True(errors.As(err, &pe), bad)
+	}
+}
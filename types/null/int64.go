@@ -1,7 +1,9 @@
 package null
 
 import (
+	"bytes"
 	"database/sql"
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
 	"strconv"
@@ -38,6 +40,32 @@ func NewInt64(i int64) Int64 {
 		}}
 }
 
+// Int64FromString constructs and returns a new Int64 by parsing the string
+// pointed to by s as a base 10 integer. If s is nil or points to an empty
+// string, the new Int64 will be null. If the string cannot be parsed, a
+// *ParseError will be returned.
+func Int64FromString(s *string) (Int64, error) {
+	if s == nil || len(*s) == 0 {
+		return NullInt64(), nil
+	}
+	v, err := strconv.ParseInt(*s, 10, 64)
+	if err != nil {
+		return Int64{}, parseError("Int64", "Int64FromString", *s, err)
+	}
+	return NewInt64(v), nil
+}
+
+// Int64FromSQL constructs and returns a new Int64 initialized with the value
+// and validity of the given sql.NullInt64 n.
+//
+// If n is not valid, the new Int64 will be null and zeroed, whatever n holds.
+func Int64FromSQL(n sql.NullInt64) Int64 {
+	if !n.Valid {
+		return NullInt64()
+	}
+	return Int64{n}
+}
+
 // Getters and Setters
 
 // ValueOrZero returns the value of i if it is valid; otherwise it returns the
@@ -49,18 +77,44 @@ func (i Int64) ValueOrZero() int64 {
 	return i.Int64
 }
 
+// Or returns i if it is valid, or o otherwise. This is useful for layering a
+// value over a default; eg. userSetting.Or(defaultSetting).
+func (i Int64) Or(o Int64) Int64 {
+	if i.Valid {
+		return i
+	}
+	return o
+}
+
 // Set modifies the value stored in i, and guarantees it is valid.
 func (i *Int64) Set(v int64) {
 	i.Int64 = v
 	i.Valid = true
 }
 
+// SetPtr sets i to the value pointed to by v, and guarantees it is valid. If v
+// is nil, i will be nulled.
+func (i *Int64) SetPtr(v *int64) {
+	if v == nil {
+		i.Null()
+		return
+	}
+	i.Set(*v)
+}
+
 // Null marks i as null with no meaningful value.
 func (i *Int64) Null() {
 	i.Int64 = 0
 	i.Valid = false
 }
 
+// SQL returns a pointer to the sql.NullInt64 embedded in i, for use with APIs
+// that only accept the database/sql null types. Modifications made through the
+// returned pointer will be reflected in i.
+func (i *Int64) SQL() *sql.NullInt64 {
+	return &i.NullInt64
+}
+
 // Interfaces
 
 // IsNil implements the pyrrho/encoding IsNiler interface. It will return true
@@ -75,10 +129,30 @@ func (i Int64) IsZero() bool {
 	return !i.Valid || i.Int64 == 0
 }
 
+// JSONSchemaType implements the JSONSchemaTyper interface. It will return
+// "integer", and true.
+func (i Int64) JSONSchemaType() (string, bool) {
+	return "integer", true
+}
+
+// Scan implements the database/sql Scanner interface. It defers to the
+// embedded sql.NullInt64, and wraps any failure in a *ParseError.
+func (i *Int64) Scan(src interface{}) error {
+	if i == nil {
+		return fmt.Errorf("null.Int64: Scan called on nil pointer")
+	}
+	if err := i.NullInt64.Scan(src); err != nil {
+		return parseError("Int64", "Scan", src, err)
+	}
+	return nil
+}
+
 // MarshalJSON implements the encoding/json Marshaler interface. It will encode
 // i into its JSON representation if valid, or 'null' otherwise.
+//
+// Valid zero values will also encode to 'null' if ZeroAsNull is true.
 func (i Int64) MarshalJSON() ([]byte, error) {
-	if !i.Valid {
+	if !i.Valid || ZeroAsNull && i.IsZero() {
 		return []byte("null"), nil
 	}
 	return []byte(strconv.FormatInt(i.Int64, 10)), nil
@@ -86,16 +160,30 @@ func (i Int64) MarshalJSON() ([]byte, error) {
 
 // UnmarshalJSON implements the encoding/json Unmarshaler interface. It will
 // decode a given []byte into i, so long as the provided []byte is a valid JSON
-// representation of an int. The 'null' keyword will decode into a null Int64.
+// representation of an int. The 'null' keyword will decode into a null Int64,
+// as will strings matching one of JSONNullTokens.
 //
 // If the decode fails, the value of i will be unchanged.
 func (i *Int64) UnmarshalJSON(data []byte) error {
 	if i == nil {
 		return fmt.Errorf("null.Int64: UnmarshalJSON called on nil pointer")
 	}
+	// Integers and nulls make up almost all input, so they're recognized
+	// without a trip through encoding/json. Anything else, including malformed
+	// input, takes the general path below, so errors are reported unchanged.
+	if v, ok := parseJSONInt(data); ok {
+		i.Int64 = v
+		i.Valid = true
+		return nil
+	}
+	if isJSONNull(data) {
+		i.Int64 = 0
+		i.Valid = false
+		return nil
+	}
 	var j interface{}
-	if err := json.Unmarshal(data, &j); err != nil {
-		return err
+	if err := DecodeJSON(data, &j); err != nil {
+		return parseError("Int64", "UnmarshalJSON", data, err)
 	}
 	switch val := j.(type) {
 	case float64:
@@ -105,7 +193,7 @@ func (i *Int64) UnmarshalJSON(data []byte) error {
 		var tmp int64
 		err := json.Unmarshal(data, &tmp)
 		if err != nil {
-			return err
+			return parseError("Int64", "UnmarshalJSON", data, err)
 		}
 		i.Int64 = tmp
 		i.Valid = true
@@ -114,10 +202,37 @@ func (i *Int64) UnmarshalJSON(data []byte) error {
 		i.Int64 = 0
 		i.Valid = false
 		return nil
+	case string:
+		return parseError("Int64", "UnmarshalJSON", data,
+			fmt.Errorf("cannot unmarshal JSON string %q into an int", val))
 	default:
-		return fmt.Errorf("null.Int64: cannot unmarshal JSON of type %T (%v)",
-			val, data)
+		return parseError("Int64", "UnmarshalJSON", data,
+			fmt.Errorf("cannot unmarshal JSON of type %T (%v)", val, data))
+	}
+}
+
+// UnmarshalText implements the encoding TextUnmarshaler interface. It will
+// decode a given []byte into i, so long as the provided []byte -- trimmed of
+// surrounding whitespace -- is the text representation of an integer, or
+// matches one of NullTextTokens, which will decode into a null Int64.
+//
+// If the decode fails, the value of i will be unchanged.
+func (i *Int64) UnmarshalText(text []byte) error {
+	if i == nil {
+		return fmt.Errorf("null.Int64: UnmarshalText called on nil pointer")
+	}
+	if isNullText(text) {
+		i.Int64 = 0
+		i.Valid = false
+		return nil
 	}
+	v, err := strconv.ParseInt(string(bytes.TrimSpace(text)), 10, 64)
+	if err != nil {
+		return parseError("Int64", "UnmarshalText", text, err)
+	}
+	i.Int64 = v
+	i.Valid = true
+	return nil
 }
 
 // MarshalMapValue implements the pyrrho/encoding/maps Marshaler interface. It
@@ -129,3 +244,76 @@ func (i Int64) MarshalMapValue() (interface{}, error) {
 	}
 	return nil, nil
 }
+
+// UnmarshalMapValue implements the pyrrho/encoding/maps Unmarshaler interface.
+// It will decode the integer v -- or a float holding a whole number, as
+// encoding/json produces -- into i, or decode nil into a null Int64.
+//
+// If the decode fails, the value of i will be unchanged.
+func (i *Int64) UnmarshalMapValue(v interface{}) error {
+	if i == nil {
+		return fmt.Errorf("null.Int64: UnmarshalMapValue called on nil pointer")
+	}
+	if v == nil {
+		i.Null()
+		return nil
+	}
+	val, err := mapValueInt64(v)
+	if err != nil {
+		return parseError("Int64", "UnmarshalMapValue", v, err)
+	}
+	i.Set(val)
+	return nil
+}
+
+// MarshalBinary implements the encoding BinaryMarshaler interface. It will
+// encode i into a validity byte followed by the 8 byte little-endian
+// representation of its value if valid, or a single zero byte otherwise.
+func (i Int64) MarshalBinary() ([]byte, error) {
+	if !i.Valid {
+		return []byte{0}, nil
+	}
+	ret := make([]byte, 9)
+	ret[0] = 1
+	binary.LittleEndian.PutUint64(ret[1:], uint64(i.Int64))
+	return ret, nil
+}
+
+// UnmarshalBinary implements the encoding BinaryUnmarshaler interface. It will
+// decode a given []byte into i, so long as the provided []byte was produced by
+// Int64.MarshalBinary.
+//
+// If the decode fails, the value of i will be unchanged.
+func (i *Int64) UnmarshalBinary(data []byte) error {
+	if i == nil {
+		return fmt.Errorf("null.Int64: UnmarshalBinary called on nil pointer")
+	}
+	switch {
+	case len(data) == 1 && data[0] == 0:
+		i.Int64 = 0
+		i.Valid = false
+		return nil
+	case len(data) == 9 && data[0] == 1:
+		i.Int64 = int64(binary.LittleEndian.Uint64(data[1:]))
+		i.Valid = true
+		return nil
+	default:
+		return parseError("Int64", "UnmarshalBinary", data,
+			fmt.Errorf("cannot unmarshal binary data (%v)", data))
+	}
+}
+
+// Compare returns -1 if i is ordered before o, 1 if i is ordered after o, and 0
+// if they're equal. Null values are ordered according to NullsFirst.
+func (i Int64) Compare(o Int64) int {
+	if c, ok := compareValidity(i.Valid, o.Valid); ok {
+		return c
+	}
+	switch {
+	case i.Int64 < o.Int64:
+		return -1
+	case i.Int64 > o.Int64:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,342 @@
+package null
+
+import (
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Int64Slice is a nullable wrapper around the []int64 type, intended for use
+// with Postgres int[] and bigint[] columns. It implements all of the
+// pyrrho/encoding/types interfaces detailed in the package comments. Like
+// ByteSlice, this type makes a distinction between nil and valid-but-empty
+// slices; a NULL column will result in a null Int64Slice, while an empty array
+// ('{}') will result in a valid Int64Slice of length zero.
+//
+// Database interactions (Value and Scan) will convert to and from the Postgres
+// array literal representation ('{1,2,3}'). JSON interactions (MarshalJSON and
+// UnmarshalJSON) will convert to and from a JSON array of integers.
+type Int64Slice struct {
+	Int64Slice []int64
+	Valid      bool
+}
+
+// Constructors
+
+// NullInt64Slice constructs and returns a new null Int64Slice.
+func NullInt64Slice() Int64Slice {
+	return Int64Slice{
+		Int64Slice: nil,
+		Valid:      false,
+	}
+}
+
+// NewInt64Slice constructs and returns a new Int64Slice based on the given
+// []int64 s. If s is nil the new Int64Slice will be null. Otherwise a new,
+// valid Int64Slice will be initialized with a copy of s.
+func NewInt64Slice(s []int64) Int64Slice {
+	if s == nil {
+		return NullInt64Slice()
+	}
+	return Int64Slice{
+		Int64Slice: append([]int64{}, s...),
+		Valid:      true,
+	}
+}
+
+// Getters and Setters
+
+// ValueOrZero returns the value of s if it is valid; otherwise, it returns an
+// empty []int64.
+func (s Int64Slice) ValueOrZero() []int64 {
+	if !s.Valid {
+		return []int64{}
+	}
+	return s.Int64Slice
+}
+
+// Or returns s if it is valid, or o otherwise. This is useful for layering a
+// value over a default; eg. userSetting.Or(defaultSetting). The underlying
+// []int64 is not copied.
+func (s Int64Slice) Or(o Int64Slice) Int64Slice {
+	if s.Valid {
+		return s
+	}
+	return o
+}
+
+// Set copies the given []int64 v into s. If v is nil, s will be nulled.
+func (s *Int64Slice) Set(v []int64) {
+	if v == nil {
+		s.Int64Slice = nil
+		s.Valid = false
+		return
+	}
+	s.Int64Slice = append([]int64{}, v...)
+	s.Valid = true
+}
+
+// SetPtr sets s to the value pointed to by v, and guarantees it is valid. If v
+// is nil, s will be nulled.
+func (s *Int64Slice) SetPtr(v *[]int64) {
+	if v == nil {
+		s.Null()
+		return
+	}
+	s.Set(*v)
+}
+
+// Null marks s as null with no meaningful value.
+func (s *Int64Slice) Null() {
+	s.Int64Slice = nil
+	s.Valid = false
+}
+
+// Interfaces
+
+// IsNil implements the pyrrho/encoding IsNiler interface. It will return true
+// if s is null.
+func (s Int64Slice) IsNil() bool {
+	return !s.Valid
+}
+
+// IsZero implements the pyrrho/encoding IsZeroer interface. It will return true
+// if s is null or if its value is of length zero.
+func (s Int64Slice) IsZero() bool {
+	return !s.Valid || len(s.Int64Slice) == 0
+}
+
+// JSONSchemaType implements the JSONSchemaTyper interface. It will return
+// "array", and true. The elements of the array are integers.
+func (s Int64Slice) JSONSchemaType() (string, bool) {
+	return "array", true
+}
+
+// Value implements the database/sql/driver Valuer interface. It will encode
+// valid values as a Postgres array literal string (eg. '{1,2,3}'), or return
+// nil otherwise.
+func (s Int64Slice) Value() (driver.Value, error) {
+	if !s.Valid {
+		return nil, nil
+	}
+	ret := make([]byte, 0, 2+len(s.Int64Slice)*4)
+	ret = append(ret, '{')
+	for i, v := range s.Int64Slice {
+		if i > 0 {
+			ret = append(ret, ',')
+		}
+		ret = strconv.AppendInt(ret, v, 10)
+	}
+	ret = append(ret, '}')
+	return string(ret), nil
+}
+
+// Scan implements the database/sql Scanner interface. It will receive a value
+// from an SQL database and assign it to s, so long as the provided data is nil,
+// an []int64, or a []byte or string containing a one-dimensional Postgres array
+// literal of integers. Arrays containing NULL elements cannot be represented by
+// an []int64, and will result in an error.
+//
+// If the scan fails, the value of s will be unchanged.
+func (s *Int64Slice) Scan(src interface{}) error {
+	if s == nil {
+		return fmt.Errorf("null.Int64Slice: Scan called on nil pointer")
+	}
+	switch val := src.(type) {
+	case nil:
+		s.Int64Slice = nil
+		s.Valid = false
+		return nil
+	case []int64:
+		s.Set(val)
+		return nil
+	case []byte:
+		return s.scanArrayLiteral(string(val))
+	case string:
+		return s.scanArrayLiteral(val)
+	default:
+		return parseError("Int64Slice", "Scan", src,
+			fmt.Errorf("cannot scan type %T (%v)", val, src))
+	}
+}
+
+func (s *Int64Slice) scanArrayLiteral(lit string) error {
+	lit = strings.TrimSpace(lit)
+	if len(lit) < 2 || lit[0] != '{' || lit[len(lit)-1] != '}' {
+		return parseError("Int64Slice", "Scan", lit,
+			fmt.Errorf("cannot scan array literal %q", lit))
+	}
+	body := strings.TrimSpace(lit[1 : len(lit)-1])
+	if len(body) == 0 {
+		s.Int64Slice = []int64{}
+		s.Valid = true
+		return nil
+	}
+	elems := strings.Split(body, ",")
+	tmp := make([]int64, len(elems))
+	for i, e := range elems {
+		e = strings.Trim(strings.TrimSpace(e), `"`)
+		if strings.EqualFold(e, "NULL") {
+			return parseError("Int64Slice", "Scan", lit,
+				fmt.Errorf("cannot scan NULL element %d of array literal %q", i, lit))
+		}
+		v, err := strconv.ParseInt(e, 10, 64)
+		if err != nil {
+			return parseError("Int64Slice", "Scan", lit,
+				fmt.Errorf("cannot scan array literal %q (%v)", lit, err))
+		}
+		tmp[i] = v
+	}
+	s.Int64Slice = tmp
+	s.Valid = true
+	return nil
+}
+
+// MarshalJSON implements the encoding/json Marshaler interface. It will encode
+// s into a JSON array if valid, or 'null' otherwise. A valid-but-empty
+// Int64Slice will be encoded as '[]'.
+//
+// Valid zero values will also encode to 'null' if ZeroAsNull is true.
+func (s Int64Slice) MarshalJSON() ([]byte, error) {
+	if !s.Valid || ZeroAsNull && s.IsZero() {
+		return []byte("null"), nil
+	}
+	if s.Int64Slice == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(s.Int64Slice)
+}
+
+// UnmarshalJSON implements the encoding/json Unmarshaler interface. It will
+// decode a given []byte into s, so long as the provided []byte is a valid JSON
+// array of integers, or the 'null' keyword. An empty array ('[]') will result
+// in a valid-but-empty Int64Slice. Strings matching one of JSONNullTokens will
+// result in a null Int64Slice.
+//
+// If the decode fails, the value of s will be unchanged.
+func (s *Int64Slice) UnmarshalJSON(data []byte) error {
+	if s == nil {
+		return fmt.Errorf("null.Int64Slice: UnmarshalJSON called on nil pointer")
+	}
+	var j interface{}
+	if err := DecodeJSON(data, &j); err != nil {
+		return parseError("Int64Slice", "UnmarshalJSON", data, err)
+	}
+	switch val := j.(type) {
+	case []interface{}:
+		for idx, e := range val {
+			if e == nil {
+				return parseError("Int64Slice", "UnmarshalJSON", data,
+					fmt.Errorf("cannot unmarshal null element %d", idx))
+			}
+		}
+		// Perform a second unmarshal, this time into an []int64, to let the
+		// JSON parser reject non-integer elements.
+		tmp := []int64{}
+		if err := json.Unmarshal(data, &tmp); err != nil {
+			return parseError("Int64Slice", "UnmarshalJSON", data, err)
+		}
+		s.Int64Slice = tmp
+		s.Valid = true
+		return nil
+	case nil:
+		s.Int64Slice = nil
+		s.Valid = false
+		return nil
+	case string:
+		return parseError("Int64Slice", "UnmarshalJSON", data,
+			fmt.Errorf("cannot unmarshal JSON string %q into an []int64", val))
+	default:
+		return parseError("Int64Slice", "UnmarshalJSON", data,
+			fmt.Errorf("cannot unmarshal JSON of type %T (%v)", val, data))
+	}
+}
+
+// MarshalMapValue implements the pyrrho/encoding/maps Marshaler interface. It
+// will encode s into its []int64 representation for use in a
+// map[string]interface{} if valid, or return nil otherwise.
+func (s Int64Slice) MarshalMapValue() (interface{}, error) {
+	if !s.Valid {
+		return nil, nil
+	}
+	return s.ValueOrZero(), nil
+}
+
+// UnmarshalMapValue implements the pyrrho/encoding/maps Unmarshaler interface.
+// It will decode the []int64 v, or a []interface{} holding only integers, into
+// s, or decode nil into a null Int64Slice. The values are copied.
+//
+// If the decode fails, the value of s will be unchanged.
+func (s *Int64Slice) UnmarshalMapValue(v interface{}) error {
+	if s == nil {
+		return fmt.Errorf("null.Int64Slice: UnmarshalMapValue called on nil pointer")
+	}
+	switch val := v.(type) {
+	case nil:
+		s.Null()
+	case []int64:
+		s.Set(append([]int64{}, val...))
+	case []interface{}:
+		tmp := make([]int64, len(val))
+		for n, e := range val {
+			i, err := mapValueInt64(e)
+			if err != nil {
+				return parseError("Int64Slice", "UnmarshalMapValue", v,
+					fmt.Errorf("element %d: %v", n, err))
+			}
+			tmp[n] = i
+		}
+		s.Set(tmp)
+	default:
+		return parseError("Int64Slice", "UnmarshalMapValue", v,
+			fmt.Errorf("cannot unmarshal map value of type %T", v))
+	}
+	return nil
+}
+
+// MarshalBinary implements the encoding BinaryMarshaler interface. It will
+// encode s into a validity byte followed by the 8 byte little-endian
+// representation of each of its elements if valid, or a single zero byte
+// otherwise.
+func (s Int64Slice) MarshalBinary() ([]byte, error) {
+	if !s.Valid {
+		return []byte{0}, nil
+	}
+	ret := make([]byte, 1+8*len(s.Int64Slice))
+	ret[0] = 1
+	for i, v := range s.Int64Slice {
+		binary.LittleEndian.PutUint64(ret[1+8*i:], uint64(v))
+	}
+	return ret, nil
+}
+
+// UnmarshalBinary implements the encoding BinaryUnmarshaler interface. It will
+// decode a given []byte into s, so long as the provided []byte was produced by
+// Int64Slice.MarshalBinary.
+//
+// If the decode fails, the value of s will be unchanged.
+func (s *Int64Slice) UnmarshalBinary(data []byte) error {
+	if s == nil {
+		return fmt.Errorf("null.Int64Slice: UnmarshalBinary called on nil pointer")
+	}
+	switch {
+	case len(data) == 1 && data[0] == 0:
+		s.Int64Slice = nil
+		s.Valid = false
+		return nil
+	case len(data) >= 1 && data[0] == 1 && (len(data)-1)%8 == 0:
+		tmp := make([]int64, (len(data)-1)/8)
+		for i := range tmp {
+			tmp[i] = int64(binary.LittleEndian.Uint64(data[1+8*i:]))
+		}
+		s.Int64Slice = tmp
+		s.Valid = true
+		return nil
+	default:
+		return parseError("Int64Slice", "UnmarshalBinary", data,
+			fmt.Errorf("cannot unmarshal binary data (%v)", data))
+	}
+}
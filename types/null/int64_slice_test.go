@@ -0,0 +1,299 @@
+package null_test
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"testing"
+
+	"github.com/pyrrho/encoding/maps"
+	"github.com/pyrrho/encoding/types/null"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInt64SliceCtors(t *testing.T) {
+	require := require.New(t)
+
+	// null.NullInt64Slice() returns a new null null.Int64Slice.
+	// This is equivalent to null.Int64Slice{}.
+	nul := null.NullInt64Slice()
+	require.False(nul.Valid)
+	require.Equal(null.Int64Slice{}, nul)
+
+	// null.NewInt64Slice constructs a new, valid, possibly zero-length
+	// null.Int64Slice with a copy of the given slice ...
+	src := []int64{1, 2, 3}
+	s := null.NewInt64Slice(src)
+	require.True(s.Valid)
+	require.Equal([]int64{1, 2, 3}, s.Int64Slice)
+	src[0] = 42
+	require.Equal([]int64{1, 2, 3}, s.Int64Slice)
+
+	empty := null.NewInt64Slice([]int64{})
+	require.True(empty.Valid)
+	require.Equal([]int64{}, empty.Int64Slice)
+
+	// ... unless a nil slice is passed in.
+	nul2 := null.NewInt64Slice(nil)
+	require.False(nul2.Valid)
+}
+
+func TestInt64SliceValueOrZero(t *testing.T) {
+	require := require.New(t)
+
+	s := null.NewInt64Slice([]int64{1, 2})
+	require.Equal([]int64{1, 2}, s.ValueOrZero())
+
+	nul := null.Int64Slice{}
+	require.Equal([]int64{}, nul.ValueOrZero())
+}
+
+func TestInt64SliceSet(t *testing.T) {
+	require := require.New(t)
+
+	s := null.Int64Slice{}
+	s.Set([]int64{1, 2})
+	require.True(s.Valid)
+	require.Equal([]int64{1, 2}, s.Int64Slice)
+
+	s.Set([]int64{})
+	require.True(s.Valid)
+	require.Equal([]int64{}, s.Int64Slice)
+
+	s.Set(nil)
+	require.False(s.Valid)
+}
+
+func TestInt64SliceNull(t *testing.T) {
+	require := require.New(t)
+
+	s := null.NewInt64Slice([]int64{1, 2})
+	s.Null()
+	require.False(s.Valid)
+	require.Nil(s.Int64Slice)
+}
+
+func TestInt64SliceIsNil(t *testing.T) {
+	require := require.New(t)
+
+	require.False(null.NewInt64Slice([]int64{1}).IsNil())
+	require.False(null.NewInt64Slice([]int64{}).IsNil())
+	require.True(null.Int64Slice{}.IsNil())
+}
+
+func TestInt64SliceIsZero(t *testing.T) {
+	require := require.New(t)
+
+	require.False(null.NewInt64Slice([]int64{0}).IsZero())
+	require.True(null.NewInt64Slice([]int64{}).IsZero())
+	require.True(null.Int64Slice{}.IsZero())
+}
+
+func TestInt64SliceSQLValue(t *testing.T) {
+	require := require.New(t)
+	var val driver.Value
+	var err error
+
+	s := null.NewInt64Slice([]int64{1, -2, 3})
+	val, err = s.Value()
+	require.NoError(err)
+	require.Equal("{1,-2,3}", val)
+
+	empty := null.NewInt64Slice([]int64{})
+	val, err = empty.Value()
+	require.NoError(err)
+	require.Equal("{}", val)
+
+	nul := null.Int64Slice{}
+	val, err = nul.Value()
+	require.NoError(err)
+	require.Equal(nil, val)
+}
+
+func TestInt64SliceSQLScan(t *testing.T) {
+	require := require.New(t)
+	var err error
+
+	var s null.Int64Slice
+	err = s.Scan([]byte("{1,-2,3}"))
+	require.NoError(err)
+	require.True(s.Valid)
+	require.Equal([]int64{1, -2, 3}, s.Int64Slice)
+
+	// Whitespace and quoted elements are tolerated.
+	var str null.Int64Slice
+	err = str.Scan(` { 1, "2" ,3 } `)
+	require.NoError(err)
+	require.True(str.Valid)
+	require.Equal([]int64{1, 2, 3}, str.Int64Slice)
+
+	var direct null.Int64Slice
+	err = direct.Scan([]int64{4, 5})
+	require.NoError(err)
+	require.Equal([]int64{4, 5}, direct.Int64Slice)
+
+	// Empty arrays and NULLs are distinct.
+	var empty null.Int64Slice
+	err = empty.Scan("{}")
+	require.NoError(err)
+	require.True(empty.Valid)
+	require.Equal([]int64{}, empty.Int64Slice)
+
+	nul := null.NewInt64Slice([]int64{1})
+	err = nul.Scan(nil)
+	require.NoError(err)
+	require.False(nul.Valid)
+	require.Nil(nul.Int64Slice)
+
+	// Values round-trip through Scan.
+	val, err := null.NewInt64Slice([]int64{7, 8, 9}).Value()
+	require.NoError(err)
+	var rt null.Int64Slice
+	err = rt.Scan(val)
+	require.NoError(err)
+	require.Equal(null.NewInt64Slice([]int64{7, 8, 9}), rt)
+
+	// Failed scans leave the value unchanged.
+	bad := null.NewInt64Slice([]int64{1})
+	for _, src := range []interface{}{
+		"1,2,3",
+		"{1,2",
+		"{1,NULL,3}",
+		"{1.5}",
+		"{{1,2},{3,4}}",
+		"{1,,2}",
+		12345,
+		true,
+	} {
+		err = bad.Scan(src)
+		require.Error(err, "%v", src)
+		require.Equal(null.NewInt64Slice([]int64{1}), bad)
+	}
+}
+
+func TestInt64SliceMarshalJSON(t *testing.T) {
+	require := require.New(t)
+	var data []byte
+	var err error
+
+	s := null.NewInt64Slice([]int64{1, 2, 3})
+	data, err = json.Marshal(s)
+	require.NoError(err)
+	require.Equal("[1,2,3]", string(data))
+
+	empty := null.NewInt64Slice([]int64{})
+	data, err = json.Marshal(empty)
+	require.NoError(err)
+	require.Equal("[]", string(data))
+
+	nul := null.Int64Slice{}
+	data, err = json.Marshal(nul)
+	require.NoError(err)
+	require.Equal("null", string(data))
+
+	wrapper := struct{ S null.Int64Slice }{s}
+	data, err = json.Marshal(wrapper)
+	require.NoError(err)
+	require.Equal(`{"S":[1,2,3]}`, string(data))
+}
+
+func TestInt64SliceUnmarshalJSON(t *testing.T) {
+	require := require.New(t)
+	var err error
+
+	var s null.Int64Slice
+	err = json.Unmarshal([]byte("[1,2,3]"), &s)
+	require.NoError(err)
+	require.True(s.Valid)
+	require.Equal([]int64{1, 2, 3}, s.Int64Slice)
+
+	var empty null.Int64Slice
+	err = json.Unmarshal([]byte("[]"), &empty)
+	require.NoError(err)
+	require.True(empty.Valid)
+	require.Equal([]int64{}, empty.Int64Slice)
+
+	nul := null.NewInt64Slice([]int64{1})
+	err = json.Unmarshal([]byte("null"), &nul)
+	require.NoError(err)
+	require.False(nul.Valid)
+
+	bad := null.NewInt64Slice([]int64{1})
+	for _, data := range []string{`[1.5]`, `["1"]`, `[null]`, `{}`, `1`, `"[1]"`} {
+		err = json.Unmarshal([]byte(data), &bad)
+		require.Error(err, data)
+		require.Equal(null.NewInt64Slice([]int64{1}), bad)
+	}
+}
+
+func TestInt64SliceMarshalMapValue(t *testing.T) {
+	require := require.New(t)
+	type Wrapper struct{ Int64Slice null.Int64Slice }
+	var data map[string]interface{}
+	var err error
+
+	data, err = maps.Marshal(Wrapper{null.NewInt64Slice([]int64{1, 2})})
+	require.NoError(err)
+	require.Equal(map[string]interface{}{"Int64Slice": []int64{1, 2}}, data)
+
+	data, err = maps.Marshal(Wrapper{null.Int64Slice{}})
+	require.NoError(err)
+	require.Equal(map[string]interface{}{"Int64Slice": nil}, data)
+}
+
+func TestInt64SliceMarshalBinary(t *testing.T) {
+	require := require.New(t)
+	var data []byte
+	var err error
+
+	s := null.NewInt64Slice([]int64{1, -1})
+	data, err = s.MarshalBinary()
+	require.NoError(err)
+	require.Equal([]byte{
+		0x01,
+		0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+	}, data)
+	var rs null.Int64Slice
+	err = rs.UnmarshalBinary(data)
+	require.NoError(err)
+	require.Equal(s, rs)
+
+	empty := null.NewInt64Slice([]int64{})
+	data, err = empty.MarshalBinary()
+	require.NoError(err)
+	require.Equal([]byte{0x01}, data)
+	var rempty null.Int64Slice
+	err = rempty.UnmarshalBinary(data)
+	require.NoError(err)
+	require.Equal(empty, rempty)
+
+	nul := null.Int64Slice{}
+	data, err = nul.MarshalBinary()
+	require.NoError(err)
+	require.Equal([]byte{0x00}, data)
+	rnul := null.NewInt64Slice([]int64{1})
+	err = rnul.UnmarshalBinary(data)
+	require.NoError(err)
+	require.Equal(nul, rnul)
+
+	var bad null.Int64Slice
+	err = bad.UnmarshalBinary(nil)
+	require.Error(err)
+	err = bad.UnmarshalBinary([]byte{0x01, 0x02})
+	require.Error(err)
+	err = bad.UnmarshalBinary([]byte{0x00, 0x00})
+	require.Error(err)
+}
+
+func TestInt64SliceSetPtr(t *testing.T) {
+	require := require.New(t)
+
+	v := []int64{1, 2, 3}
+	var n null.Int64Slice
+	n.SetPtr(&v)
+	require.Equal(null.NewInt64Slice([]int64{1, 2, 3}), n)
+
+	n.SetPtr(nil)
+	require.False(n.Valid)
+	require.Equal(null.NullInt64Slice(), n)
+}
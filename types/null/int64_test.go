@@ -1,8 +1,10 @@
 package null_test
 
 import (
+	"database/sql"
 	"database/sql/driver"
 	"encoding/json"
+	"errors"
 	"math"
 	"strconv"
 	"testing"
@@ -222,7 +224,8 @@ func TestInt64UnmarshalJSON(t *testing.T) {
 
 	var invalid null.Int64
 	err = invalid.UnmarshalJSON([]byte(":->"))
-	if _, ok := err.(*json.SyntaxError); !ok {
+	var syntaxErr *json.SyntaxError
+	if !errors.As(err, &syntaxErr) {
 		require.FailNowf(
 			"Unexpected Error Type",
 			"expected *json.SyntaxError, not %T", err)
@@ -247,6 +250,57 @@ func TestInt64UnmarshalJSONOverflow(t *testing.T) {
 	require.Error(err)
 }
 
+func TestInt64UnmarshalJSONFastPath(t *testing.T) {
+	require := require.New(t)
+
+	// Whatever path UnmarshalJSON takes, it should agree with encoding/json.
+	inputs := []string{
+		"0", "-0", "7", " 7 ", "\n-42\t", "01", "-01", "-", "--1", "+1", "1e3",
+		"1.0", "9223372036854775807", "-9223372036854775808",
+		"9223372036854775808", "null", " null ", "nul", "nullx", "true", "",
+	}
+	for _, in := range inputs {
+		var expected *int64
+		expectedErr := json.Unmarshal([]byte(in), &expected)
+
+		i := null.NewInt64(99)
+		err := i.UnmarshalJSON([]byte(in))
+		if expectedErr != nil {
+			require.Error(err, "%q", in)
+			require.Equal(null.NewInt64(99), i, "%q", in)
+			continue
+		}
+		require.NoError(err, "%q", in)
+		var want null.Int64
+		want.SetPtr(expected)
+		require.Equal(want, i, "%q", in)
+	}
+}
+
+func BenchmarkInt64UnmarshalJSON(b *testing.B) {
+	data := []byte("[")
+	for n := 0; n < 1000; n++ {
+		if n > 0 {
+			data = append(data, ',')
+		}
+		if n%10 == 0 {
+			data = append(data, "null"...)
+		} else {
+			data = strconv.AppendInt(data, int64(n)*-7919, 10)
+		}
+	}
+	data = append(data, ']')
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	var dst []null.Int64
+	for n := 0; n < b.N; n++ {
+		if err := json.Unmarshal(data, &dst); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 func TestInt64MarshalMapValue(t *testing.T) {
 	require := require.New(t)
 	type Wrapper struct{ Int64 null.Int64 }
@@ -279,3 +333,107 @@ func TestInt64MarshalMapValue(t *testing.T) {
 	require.NoError(err)
 	require.Equal(map[string]interface{}{"Int64": nil}, data)
 }
+
+func TestInt64MarshalBinary(t *testing.T) {
+	require := require.New(t)
+	var data []byte
+	var err error
+
+	i := null.NewInt64(-12345)
+	data, err = i.MarshalBinary()
+	require.NoError(err)
+	require.Equal([]byte{0x01, 0xc7, 0xcf, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, data)
+	var ri null.Int64
+	err = ri.UnmarshalBinary(data)
+	require.NoError(err)
+	require.Equal(i, ri)
+
+	z := null.NewInt64(0)
+	data, err = z.MarshalBinary()
+	require.NoError(err)
+	var rz null.Int64
+	err = rz.UnmarshalBinary(data)
+	require.NoError(err)
+	require.Equal(z, rz)
+
+	nul := null.Int64{}
+	data, err = nul.MarshalBinary()
+	require.NoError(err)
+	require.Equal([]byte{0x00}, data)
+	rnul := null.NewInt64(12345)
+	err = rnul.UnmarshalBinary(data)
+	require.NoError(err)
+	require.Equal(nul, rnul)
+
+	var bad null.Int64
+	err = bad.UnmarshalBinary(nil)
+	require.Error(err)
+	err = bad.UnmarshalBinary([]byte{0x01, 0x02})
+	require.Error(err)
+	err = bad.UnmarshalBinary([]byte{0x00, 0x00})
+	require.Error(err)
+}
+
+func TestInt64Compare(t *testing.T) {
+	require := require.New(t)
+
+	require.Equal(-1, null.NewInt64(-1).Compare(null.NewInt64(1)))
+	require.Equal(1, null.NewInt64(1).Compare(null.NewInt64(-1)))
+	require.Equal(0, null.NewInt64(1).Compare(null.NewInt64(1)))
+	require.Equal(0, null.NullInt64().Compare(null.NullInt64()))
+	require.Equal(1, null.NullInt64().Compare(null.NewInt64(0)))
+	require.Equal(-1, null.NewInt64(0).Compare(null.NullInt64()))
+}
+
+func TestInt64SQL(t *testing.T) {
+	require := require.New(t)
+
+	n := sql.NullInt64{Int64: int64(12345), Valid: true}
+	v := null.Int64FromSQL(n)
+	require.True(v.Valid)
+	require.Equal(n, *v.SQL())
+	require.Equal(null.Int64FromSQL(sql.NullInt64{}), null.NullInt64())
+
+	// SQL returns a pointer to the embedded value, so it can be scanned into
+	// directly.
+	err := v.SQL().Scan(nil)
+	require.NoError(err)
+	require.False(v.Valid)
+}
+
+func TestInt64SetPtr(t *testing.T) {
+	require := require.New(t)
+
+	v := int64(12345)
+	var n null.Int64
+	n.SetPtr(&v)
+	require.Equal(null.NewInt64(12345), n)
+
+	n.SetPtr(nil)
+	require.False(n.Valid)
+	require.Equal(null.NullInt64(), n)
+}
+
+func TestInt64FromString(t *testing.T) {
+	require := require.New(t)
+	str := func(s string) *string { return &s }
+
+	i, err := null.Int64FromString(str("-42"))
+	require.NoError(err)
+	require.Equal(null.NewInt64(-42), i)
+
+	// Nil pointers and empty strings are null.
+	i, err = null.Int64FromString(nil)
+	require.NoError(err)
+	require.Equal(null.NullInt64(), i)
+	i, err = null.Int64FromString(str(""))
+	require.NoError(err)
+	require.Equal(null.NullInt64(), i)
+
+	for _, bad := range []string{"abc", "1.5", " 1", "9223372036854775808"} {
+		_, err = null.Int64FromString(str(bad))
+		var pe *null.ParseError
+		require.True(errors.As(err, &pe), bad)
+		require.Equal(bad, pe.Input)
+	}
+}
@@ -0,0 +1,59 @@
+package null
+
+import (
+	"database/sql"
+)
+
+// Int64s constructs and returns a new []Int64, with one valid Int64
+// initialized with each of the given values.
+func Int64s(vs ...int64) []Int64 {
+	ret := make([]Int64, len(vs))
+	for i, v := range vs {
+		ret[i] = NewInt64(v)
+	}
+	return ret
+}
+
+// Int64sFromPtrs constructs and returns a new []Int64 from the given []*int64
+// ps. Each nil pointer will result in a null Int64, and each non-nil pointer
+// will result in a valid Int64 initialized with the pointed-to value.
+func Int64sFromPtrs(ps []*int64) []Int64 {
+	ret := make([]Int64, len(ps))
+	for i, p := range ps {
+		if p != nil {
+			ret[i] = NewInt64(*p)
+		}
+	}
+	return ret
+}
+
+// Int64sToPtrs is the inverse of Int64sFromPtrs. It returns a new []*int64 with
+// a nil pointer for each null Int64 in is, and a pointer to a copy of the
+// value of each valid Int64.
+func Int64sToPtrs(is []Int64) []*int64 {
+	ret := make([]*int64, len(is))
+	for i := range is {
+		if is[i].Valid {
+			v := is[i].Int64
+			ret[i] = &v
+		}
+	}
+	return ret
+}
+
+// ScanInt64s scans the single column of each remaining row in rows into a
+// new Int64, and appends those values to *dst. NULL columns will result in
+// null Int64s. Scanning stops at the first error, in which case the values
+// scanned so far will still be appended.
+//
+// As with any *sql.Rows, it is the caller's responsibility to Close rows.
+func ScanInt64s(rows *sql.Rows, dst *[]Int64) error {
+	for rows.Next() {
+		var i Int64
+		if err := rows.Scan(&i); err != nil {
+			return err
+		}
+		*dst = append(*dst, i)
+	}
+	return rows.Err()
+}
@@ -0,0 +1,67 @@
+package null_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/pyrrho/encoding/types/null"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInt64s(t *testing.T) {
+	require := require.New(t)
+
+	require.Equal([]null.Int64{null.NewInt64(1), null.NewInt64(0), null.NewInt64(-1)},
+		null.Int64s(1, 0, -1))
+	require.Equal([]null.Int64{}, null.Int64s())
+}
+
+func TestInt64sPtrs(t *testing.T) {
+	require := require.New(t)
+
+	one, zero := int64(1), int64(0)
+	is := null.Int64sFromPtrs([]*int64{&one, nil, &zero})
+	require.Equal([]null.Int64{null.NewInt64(1), null.NullInt64(), null.NewInt64(0)}, is)
+
+	// The returned values don't alias the given pointers.
+	one = 42
+	require.Equal(int64(1), is[0].Int64)
+
+	ps := null.Int64sToPtrs(is)
+	require.Len(ps, 3)
+	require.Equal(int64(1), *ps[0])
+	require.Nil(ps[1])
+	require.Equal(int64(0), *ps[2])
+	require.Equal(is, null.Int64sFromPtrs(ps))
+}
+
+func TestScanInt64s(t *testing.T) {
+	require := require.New(t)
+
+	db, err := sql.Open("null_test_rows", "")
+	require.NoError(err)
+	defer db.Close()
+
+	rows, err := db.Query("v\n1\nNULL\n-3\n0")
+	require.NoError(err)
+	defer rows.Close()
+	dst := null.Int64s(42)
+	err = null.ScanInt64s(rows, &dst)
+	require.NoError(err)
+	require.Equal([]null.Int64{
+		null.NewInt64(42),
+		null.NewInt64(1),
+		null.NullInt64(),
+		null.NewInt64(-3),
+		null.NewInt64(0),
+	}, dst)
+
+	// Scan errors stop the scan, but keep what was scanned.
+	rows, err = db.Query("v\n1\nhello\n3")
+	require.NoError(err)
+	defer rows.Close()
+	dst = nil
+	err = null.ScanInt64s(rows, &dst)
+	require.Error(err)
+	require.Equal([]null.Int64{null.NewInt64(1)}, dst)
+}
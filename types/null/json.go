@@ -0,0 +1,146 @@
+package null
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// ZeroAsNull causes the MarshalJSON methods of Bool, ByteSlice, Float64, Int64,
+// Int64Slice, String, Time, and Uint8 to encode valid values for which IsZero
+// returns true -- false, 0, "", empty slices, and the zero time.Time -- as the
+// JSON 'null' keyword, exactly as if they were null. RawJSON and the SF
+// geometry types are unaffected, as is UnmarshalJSON.
+//
+// Enabling this option loses the distinction between null and valid-but-zero
+// values on output; a valid zero value that is marshalled and then unmarshalled
+// will come back null. It is intended for interoperating with APIs that treat
+// zero values as absent, and should otherwise be left false.
+var ZeroAsNull = false
+
+// BoolAcceptStrings causes Bool.UnmarshalJSON to accept the JSON strings "true"
+// and "1" as true, and "false" and "0" as false, in addition to the boolean
+// keywords. This is intended for lenient decoding of APIs that quote their
+// booleans; by default, strings are an error. Bool.MarshalJSON always emits
+// the unquoted keywords, regardless of this setting.
+var BoolAcceptStrings = false
+
+// ByteSliceAsArray causes ByteSlice.MarshalJSON to encode valid values as a
+// JSON array of numbers -- eg. [104,105] -- rather than as a base64 string, for
+// interoperating with systems that represent binary data that way.
+// ByteSlice.UnmarshalJSON accepts both forms, regardless of this setting.
+var ByteSliceAsArray = false
+
+// JSONNullTokens is a set of JSON strings that the UnmarshalJSON methods of
+// this package's types will decode into a null value, as if they had been the
+// 'null' keyword; eg. []string{"null", "nil"} for producers that quote their
+// nulls. Like NullTextTokens, both the string and the tokens have surrounding
+// whitespace trimmed, and are compared case-insensitively. Only string inputs
+// are consulted -- the number 0 is never a null -- and the JSON 'null' keyword
+// is always accepted. Empty by default, so that every string is decoded (or
+// rejected) as usual.
+var JSONNullTokens []string
+
+// JSONSchemaTyper is implemented by each of this package's types, so that tools
+// which generate JSON Schema or OpenAPI documents can describe them without
+// special-casing each type by name. JSONSchemaType returns the JSON Schema
+// "type" of the values MarshalJSON produces -- eg. "integer" for Int64 -- and
+// whether 'null' may be produced in their place; eg. {"type": "integer",
+// "nullable": true}. An empty type means values of any type may be produced.
+//
+// Settings that change the shape of MarshalJSON's output, like TimeUnixMode and
+// ByteSliceAsArray, are reflected in the result.
+type JSONSchemaTyper interface {
+	JSONSchemaType() (typ string, nullable bool)
+}
+
+// trimJSONSpace returns data without the leading and trailing whitespace JSON
+// permits around a value; spaces, tabs, carriage returns, and newlines.
+func trimJSONSpace(data []byte) []byte {
+	isSpace := func(c byte) bool {
+		return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+	}
+	for len(data) > 0 && isSpace(data[0]) {
+		data = data[1:]
+	}
+	for len(data) > 0 && isSpace(data[len(data)-1]) {
+		data = data[:len(data)-1]
+	}
+	return data
+}
+
+// parseJSONInt parses data as a JSON integer literal -- an optional minus sign,
+// and digits without leading zeros -- that fits in an int64, without the
+// overhead of encoding/json. It returns false if data is anything else, in
+// which case callers should fall back to encoding/json, which will either
+// accept the value or describe why it can't.
+func parseJSONInt(data []byte) (int64, bool) {
+	data = trimJSONSpace(data)
+	digits := data
+	if len(digits) > 0 && digits[0] == '-' {
+		digits = digits[1:]
+	}
+	if len(digits) == 0 || (digits[0] == '0' && len(digits) > 1) {
+		return 0, false
+	}
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+	}
+	v, err := strconv.ParseInt(string(data), 10, 64)
+	return v, err == nil
+}
+
+// DecodeJSON decodes the JSON encoded data into the value pointed to by dst, as
+// json.Unmarshal does, but with this package's leniency settings applied. It's
+// the decoder the UnmarshalJSON methods of this package's types are built on,
+// and is exported so that nullable types defined elsewhere can accept exactly
+// the same inputs.
+//
+// Inputs that should be treated as null -- the JSON 'null' keyword, and
+// strings matching one of JSONNullTokens -- are decoded as 'null' is by
+// encoding/json; pointers, interfaces, maps, and slices are set to nil, and
+// other values are left unchanged. Decoding into a pointer, or an interface{},
+// is therefore the simplest way to tell whether a value was null.
+func DecodeJSON(data []byte, dst interface{}) error {
+	if isJSONNull(data) || isJSONNullToken(data) {
+		return json.Unmarshal([]byte("null"), dst)
+	}
+	return json.Unmarshal(data, dst)
+}
+
+// isJSONNullToken returns true if data is a JSON string matching one of
+// JSONNullTokens.
+func isJSONNullToken(data []byte) bool {
+	data = trimJSONSpace(data)
+	if len(JSONNullTokens) == 0 || len(data) == 0 || data[0] != '"' {
+		return false
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return false
+	}
+	s = strings.TrimSpace(s)
+	for _, tok := range JSONNullTokens {
+		if strings.EqualFold(s, strings.TrimSpace(tok)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isJSONNumber returns true if s is a single, valid JSON number.
+func isJSONNumber(s string) bool {
+	if s == "" || s[0] != '-' && (s[0] < '0' || s[0] > '9') {
+		return false
+	}
+	return json.Valid([]byte(s))
+}
+
+// isJSONNull returns true if data, trimmed of surrounding whitespace, is the
+// JSON 'null' keyword.
+func isJSONNull(data []byte) bool {
+	return bytes.Equal(trimJSONSpace(data), []byte("null"))
+}
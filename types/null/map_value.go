@@ -0,0 +1,60 @@
+package null
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// The UnmarshalMapValue methods of this package's types are the inverses of
+// their MarshalMapValue methods. Each accepts the value its MarshalMapValue
+// method would have produced, nil (which decodes into a null value), and --
+// where there's no loss of information -- the other representations a value
+// may have picked up on its way through a map; eg. a float64 holding a whole
+// number, as encoding/json produces, for an Int64.
+
+// isNullMapValue returns true if v is one of the values MarshalMapValue methods
+// produce for null values; nil, or the JSON 'null' keyword.
+func isNullMapValue(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	b, ok := v.([]byte)
+	return ok && bytes.Equal(b, []byte("null"))
+}
+
+// mapValueInt64 converts the integer, or whole float, v into an int64.
+func mapValueInt64(v interface{}) (int64, error) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if u := rv.Uint(); u <= math.MaxInt64 {
+			return int64(u), nil
+		}
+		return 0, fmt.Errorf("value %v overflows int64", v)
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+			return 0, fmt.Errorf("value %v is not representable as an int64", v)
+		}
+		return int64(f), nil
+	}
+	return 0, fmt.Errorf("cannot unmarshal map value of type %T", v)
+}
+
+// mapValueFloat64 converts the integer or float v into a float64.
+func mapValueFloat64(v interface{}) (float64, error) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
+	}
+	return 0, fmt.Errorf("cannot unmarshal map value of type %T", v)
+}
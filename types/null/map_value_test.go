@@ -0,0 +1,125 @@
+package null_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pyrrho/encoding/maps"
+	"github.com/pyrrho/encoding/types"
+	"github.com/pyrrho/encoding/types/null"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshalMapValueRoundTrip(t *testing.T) {
+	require := require.New(t)
+
+	timeValue := time.Date(2012, 12, 21, 21, 21, 21, 0, time.UTC)
+	ring := [][2]float64{{30, 10}, {40, 40}, {20, 40}, {30, 10}}
+	square := [][2]float64{{0, 0}, {1, 0}, {1, 1}, {0, 0}}
+	cases := []struct {
+		name  string
+		valid maps.Marshaler
+		null  maps.Marshaler
+		// dst returns a pointer to a valid value distinct from valid, to be
+		// overwritten by each UnmarshalMapValue.
+		dst func() maps.Unmarshaler
+	}{
+		{"Bool", null.NewBool(false), null.NullBool(),
+			func() maps.Unmarshaler { v := null.NewBool(true); return &v }},
+		{"ByteSlice", null.NewByteSlice([]byte{0, 1, 2}), null.NullByteSlice(),
+			func() maps.Unmarshaler { v := null.NewByteSliceStr("old"); return &v }},
+		{"Float64", null.NewFloat64(-1.5), null.NullFloat64(),
+			func() maps.Unmarshaler { v := null.NewFloat64(42); return &v }},
+		{"Int64", null.NewInt64(-42), null.NullInt64(),
+			func() maps.Unmarshaler { v := null.NewInt64(7); return &v }},
+		{"Int64Slice", null.NewInt64Slice([]int64{1, 2, 3}), null.NullInt64Slice(),
+			func() maps.Unmarshaler { v := null.NewInt64Slice([]int64{7}); return &v }},
+		{"RawJSON", null.NewJSONStr(`{"a":1}`), null.NullJSON(),
+			func() maps.Unmarshaler { v := null.NewJSONStr(`true`); return &v }},
+		{"SFPoint", null.NewSFPointXY(1.2, 2.3), null.NullSFPoint(),
+			func() maps.Unmarshaler { v := null.NewSFPointXY(4, 5); return &v }},
+		{"SFPolygon", null.NewSFPolygonXY(ring), null.NullSFPolygon(),
+			func() maps.Unmarshaler { v := null.NewSFPolygonXY(square); return &v }},
+		{"String", null.NewString(""), null.NullString(),
+			func() maps.Unmarshaler { v := null.NewString("old"); return &v }},
+		{"Time", null.NewTime(timeValue), null.NullTime(),
+			func() maps.Unmarshaler { v := null.NewTime(timeValue.Add(time.Hour)); return &v }},
+		{"Uint8", null.NewUint8(0), null.NullUint8(),
+			func() maps.Unmarshaler { v := null.NewUint8(255); return &v }},
+	}
+
+	deref := func(v maps.Unmarshaler) interface{} {
+		return reflect.ValueOf(v).Elem().Interface()
+	}
+	for _, c := range cases {
+		for _, src := range []maps.Marshaler{c.valid, c.null} {
+			mv, err := src.MarshalMapValue()
+			require.NoError(err, c.name)
+			got := c.dst()
+			require.NoError(got.UnmarshalMapValue(mv), c.name)
+			require.Equal(src, deref(got), c.name)
+		}
+
+		// Plain nils always decode into null values.
+		got := c.dst()
+		require.NoError(got.UnmarshalMapValue(nil), c.name)
+		require.Equal(c.null, deref(got), c.name)
+
+		// Values of the wrong type are an error, and leave the destination
+		// untouched.
+		got = c.dst()
+		err := got.UnmarshalMapValue(make(chan int))
+		var pe *null.ParseError
+		require.True(errors.As(err, &pe), "%s: %T", c.name, err)
+		require.Equal("UnmarshalMapValue", pe.Func)
+		require.Equal(deref(c.dst()), deref(got), c.name)
+	}
+}
+
+func TestUnmarshalMapValueConversions(t *testing.T) {
+	require := require.New(t)
+
+	// Numbers that have passed through encoding/json arrive as float64s.
+	var i null.Int64
+	require.NoError(i.UnmarshalMapValue(float64(42)))
+	require.Equal(null.NewInt64(42), i)
+	require.NoError(i.UnmarshalMapValue(uint8(7)))
+	require.Equal(null.NewInt64(7), i)
+	require.Error(i.UnmarshalMapValue(1.5))
+	require.Error(i.UnmarshalMapValue(uint64(1 << 63)))
+	require.Error(i.UnmarshalMapValue("42"))
+
+	var u null.Uint8
+	require.NoError(u.UnmarshalMapValue(float64(255)))
+	require.Equal(null.NewUint8(255), u)
+	require.Error(u.UnmarshalMapValue(256))
+	require.Error(u.UnmarshalMapValue(-1))
+
+	var f null.Float64
+	require.NoError(f.UnmarshalMapValue(int64(3)))
+	require.Equal(null.NewFloat64(3), f)
+
+	var s null.Int64Slice
+	require.NoError(s.UnmarshalMapValue([]interface{}{float64(1), int64(2)}))
+	require.Equal(null.NewInt64Slice([]int64{1, 2}), s)
+	require.Error(s.UnmarshalMapValue([]interface{}{1, "two"}))
+
+	var b null.ByteSlice
+	require.NoError(b.UnmarshalMapValue("REFJQ09OIFY="))
+	require.Equal(null.NewByteSliceStr("DAICON V"), b)
+	require.Error(b.UnmarshalMapValue("!!!"))
+
+	var tm null.Time
+	require.NoError(tm.UnmarshalMapValue("2012-12-21T21:21:21Z"))
+	require.Equal(null.NewTime(time.Date(2012, 12, 21, 21, 21, 21, 0, time.UTC)), tm)
+
+	// GeoJSON maps decode into geometries.
+	var p null.SFPoint
+	require.NoError(p.UnmarshalMapValue(map[string]interface{}{
+		"type":        "Point",
+		"coordinates": []interface{}{1.2, 2.3},
+	}))
+	require.Equal(null.NewSFPoint(types.NewSFPointXY(1.2, 2.3)), p)
+}
@@ -0,0 +1,115 @@
+package null
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Optional adds a third state to a nullable value, for decoding the partial
+// documents sent by HTTP PATCH requests (eg. RFC 7396 JSON Merge Patches),
+// which must distinguish a member that is absent, from one that is present and
+// null, from one that is present with a value. T is expected to be one of this
+// package's nullable types -- eg. Optional[Int64] -- which track the latter two
+// states themselves.
+//
+// encoding/json only calls UnmarshalJSON for members that appear in the
+// document, so Present will be true exactly when a member was sent, and Value
+// will hold whatever it held, 'null' included. Fields should be declared as a
+// plain Optional, not as a pointer to one; encoding/json decodes 'null' into a
+// pointer field by setting it to nil, which is indistinguishable from absence.
+//
+//	type UserPatch struct {
+//	    Name null.Optional[null.String] `json:"name,omitzero"`
+//	    Age  null.Optional[null.Int64]  `json:"age,omitzero"`
+//	}
+//
+// IsZero returns true for absent Optionals, so the "omitzero" option of
+// encoding/json (as of Go 1.24), and the "omitZero" option of
+// pyrrho/encoding/maps, will leave them out when marshalling.
+type Optional[T any] struct {
+	Present bool
+	Value   T
+}
+
+// Constructors
+
+// NewOptional constructs and returns a new Optional that is present, and holds
+// v. The zero Optional is absent.
+func NewOptional[T any](v T) Optional[T] {
+	return Optional[T]{Present: true, Value: v}
+}
+
+// Interfaces
+
+// IsNil implements the pyrrho/encoding IsNiler interface. It will return true
+// if o is absent, or if its Value implements IsNiler and is nil.
+func (o Optional[T]) IsNil() bool {
+	if !o.Present {
+		return true
+	}
+	if n, ok := any(o.Value).(interface{ IsNil() bool }); ok {
+		return n.IsNil()
+	}
+	return false
+}
+
+// IsZero implements the pyrrho/encoding IsZeroer interface. It will return true
+// if o is absent. Present Optionals are never zero, whatever their Value, so
+// that an explicit null or zero survives omission rules.
+func (o Optional[T]) IsZero() bool {
+	return !o.Present
+}
+
+// JSONSchemaType implements the JSONSchemaTyper interface. It will return the
+// JSON Schema type of o's Value, if T implements JSONSchemaTyper, or an empty
+// type and false otherwise. Absent Optionals are omitted rather than encoded,
+// so they don't affect the result.
+func (o Optional[T]) JSONSchemaType() (string, bool) {
+	if s, ok := any(o.Value).(JSONSchemaTyper); ok {
+		return s.JSONSchemaType()
+	}
+	return "", false
+}
+
+// MarshalJSON implements the encoding/json Marshaler interface. It will encode
+// the Value of o if o is present, or the JSON 'null' keyword if not; absent
+// Optionals should be omitted with "omitzero" rather than marshalled.
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if !o.Present {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.Value)
+}
+
+// UnmarshalJSON implements the encoding/json Unmarshaler interface. It will
+// decode data into the Value of o, and mark o as present. 'null' is decoded
+// into Value like any other input.
+//
+// If the decode fails, o will be unchanged.
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	if o == nil {
+		return fmt.Errorf("null.Optional: UnmarshalJSON called on nil pointer")
+	}
+	v := o.Value
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	o.Value = v
+	o.Present = true
+	return nil
+}
+
+// MarshalMapValue implements the pyrrho/encoding/maps Marshaler interface. It
+// will return the map value of o's Value -- by way of its own MarshalMapValue
+// method, if it has one -- if o is present, or nil otherwise.
+func (o Optional[T]) MarshalMapValue() (interface{}, error) {
+	if !o.Present {
+		return nil, nil
+	}
+	if m, ok := any(o.Value).(interface {
+		MarshalMapValue() (interface{}, error)
+	}); ok {
+		return m.MarshalMapValue()
+	}
+	return o.Value, nil
+}
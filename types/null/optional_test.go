@@ -0,0 +1,59 @@
+package null_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pyrrho/encoding/maps"
+	"github.com/pyrrho/encoding/types/null"
+	"github.com/stretchr/testify/require"
+)
+
+type OptionalPatch struct {
+	Name null.Optional[null.String] `json:"name" map:"name,omitZero"`
+	Age  null.Optional[null.Int64]  `json:"age" map:"age,omitZero"`
+	Note null.Optional[string]      `json:"note" map:"note,omitZero"`
+}
+
+func TestOptionalUnmarshalJSON(t *testing.T) {
+	require := require.New(t)
+
+	var p OptionalPatch
+	require.NoError(json.Unmarshal([]byte(`{"name": "Ada", "age": null}`), &p))
+	require.Equal(null.NewOptional(null.NewString("Ada")), p.Name)
+	require.Equal(null.NewOptional(null.NullInt64()), p.Age)
+	require.False(p.Note.Present)
+
+	require.False(p.Name.IsNil())
+	require.True(p.Age.IsNil())
+	require.False(p.Age.IsZero())
+	require.True(p.Note.IsNil())
+	require.True(p.Note.IsZero())
+
+	// Failed decodes leave the Optional unchanged.
+	err := json.Unmarshal([]byte(`{"age": "twelve"}`), &p)
+	require.Error(err)
+	require.Equal(null.NewOptional(null.NullInt64()), p.Age)
+}
+
+func TestOptionalMarshal(t *testing.T) {
+	require := require.New(t)
+
+	p := OptionalPatch{
+		Age:  null.NewOptional(null.NullInt64()),
+		Note: null.NewOptional("hi"),
+	}
+	data, err := json.Marshal(p)
+	require.NoError(err)
+	require.JSONEq(`{"name": null, "age": null, "note": "hi"}`, string(data))
+
+	// Absent Optionals are omitted by "omitZero", but present nulls are not.
+	m, err := maps.Marshal(p)
+	require.NoError(err)
+	require.Equal(map[string]interface{}{"age": nil, "note": "hi"}, m)
+
+	p.Age = null.NewOptional(null.NewInt64(36))
+	m, err = maps.Marshal(p)
+	require.NoError(err)
+	require.Equal(map[string]interface{}{"age": int64(36), "note": "hi"}, m)
+}
@@ -0,0 +1,128 @@
+package null
+
+import (
+	"fmt"
+	"reflect"
+)
+
+var packagePath = reflect.TypeOf(Int64{}).PkgPath()
+
+// ToPointers copies the fields of the struct, or pointer-to-struct, src into
+// the struct pointed to by dst, converting each of this package's nullable
+// types into a pointer to its underlying type; eg. an Int64 field into a *int64
+// field. Null values become nil pointers, and valid values pointers to copies
+// of their values. This is intended to bridge structs built on these types and
+// libraries that represent optional values as pointers.
+//
+// Fields are matched by name. Fields of dst with no counterpart in src are left
+// untouched. Other fields are copied as-is, and nested structs of differing
+// types are converted field by field, in turn. An error will be returned if a
+// field of src can't be assigned to its counterpart in dst, in which case dst
+// may have been partially modified.
+func ToPointers(dst, src interface{}) error {
+	return convertPointers("ToPointers", dst, src, toPointer)
+}
+
+// FromPointers is the inverse of ToPointers. It copies the fields of the
+// struct, or pointer-to-struct, src into the struct pointed to by dst,
+// converting pointers into the corresponding nullable type of dst's field; eg.
+// a *int64 field into an Int64 field. Nil pointers become null values. The same
+// matching rules, and caveats, as ToPointers apply.
+func FromPointers(dst, src interface{}) error {
+	return convertPointers("FromPointers", dst, src, fromPointer)
+}
+
+// convertPointers validates dst and src, then copies src into dst, using conv
+// to convert individual fields.
+func convertPointers(fn string, dst, src interface{}, conv func(d, s reflect.Value) bool) error {
+	dv := reflect.ValueOf(dst)
+	if dv.Kind() != reflect.Ptr || dv.IsNil() || dv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("null.%s: dst must be a non-nil pointer to a struct, not %T", fn, dst)
+	}
+	sv := reflect.ValueOf(src)
+	if sv.Kind() == reflect.Ptr && !sv.IsNil() {
+		sv = sv.Elem()
+	}
+	if sv.Kind() != reflect.Struct {
+		return fmt.Errorf("null.%s: src must be a struct or a non-nil pointer to one, not %T", fn, src)
+	}
+	if err := copyFields(dv.Elem(), sv, conv); err != nil {
+		return fmt.Errorf("null.%s: %v", fn, err)
+	}
+	return nil
+}
+
+func copyFields(d, s reflect.Value, conv func(d, s reflect.Value) bool) error {
+	for i := 0; i < d.NumField(); i++ {
+		df := d.Type().Field(i)
+		if df.PkgPath != "" {
+			continue // unexported
+		}
+		sf, ok := s.Type().FieldByName(df.Name)
+		if !ok || sf.PkgPath != "" {
+			continue
+		}
+		sfv, err := s.FieldByIndexErr(sf.Index)
+		if err != nil {
+			continue // promoted through a nil embedded pointer
+		}
+		dfv := d.Field(i)
+		switch {
+		case conv(dfv, sfv):
+		case sfv.Type().AssignableTo(dfv.Type()):
+			dfv.Set(sfv)
+		case dfv.Kind() == reflect.Struct && sfv.Kind() == reflect.Struct:
+			if err := copyFields(dfv, sfv, conv); err != nil {
+				return fmt.Errorf("%s.%v", df.Name, err)
+			}
+		default:
+			return fmt.Errorf("cannot assign field %s of type %s to a %s", df.Name, sfv.Type(), dfv.Type())
+		}
+	}
+	return nil
+}
+
+// toPointer sets d to a pointer to the value of the nullable s, and returns
+// true, if s is one of this package's types, and d a pointer to its underlying
+// type.
+func toPointer(d, s reflect.Value) bool {
+	vt, ok := nullValueType(s.Type())
+	if !ok || d.Type() != reflect.PtrTo(vt) {
+		return false
+	}
+	if s.Interface().(interface{ IsNil() bool }).IsNil() {
+		d.Set(reflect.Zero(d.Type()))
+		return true
+	}
+	p := reflect.New(vt)
+	p.Elem().Set(s.MethodByName("ValueOrZero").Call(nil)[0])
+	d.Set(p)
+	return true
+}
+
+// fromPointer sets the nullable d from the pointer s, and returns true, if d is
+// one of this package's types, and s a pointer to its underlying type.
+func fromPointer(d, s reflect.Value) bool {
+	vt, ok := nullValueType(d.Type())
+	if !ok || s.Type() != reflect.PtrTo(vt) {
+		return false
+	}
+	d.Addr().MethodByName("SetPtr").Call([]reflect.Value{s})
+	return true
+}
+
+// nullValueType returns the underlying type of t -- eg. int64 for Int64 -- if t
+// is one of this package's nullable types.
+func nullValueType(t reflect.Type) (reflect.Type, bool) {
+	if t.Kind() != reflect.Struct || t.PkgPath() != packagePath {
+		return nil, false
+	}
+	m, ok := t.MethodByName("ValueOrZero")
+	if !ok || m.Type.NumOut() != 1 {
+		return nil, false
+	}
+	if _, ok := reflect.PtrTo(t).MethodByName("SetPtr"); !ok {
+		return nil, false
+	}
+	return m.Type.Out(0), true
+}
@@ -0,0 +1,110 @@
+package null_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pyrrho/encoding/types/null"
+	"github.com/stretchr/testify/require"
+)
+
+type PointersAddress struct {
+	Street null.String
+	Zip    null.Int64
+}
+
+type PointersModel struct {
+	ID       int64
+	Name     null.String
+	Age      null.Int64
+	Score    null.Float64
+	Active   null.Bool
+	Born     null.Time
+	Avatar   null.ByteSlice
+	Tags     []string
+	Address  PointersAddress
+	Internal string
+	private  null.String
+}
+
+type PointersAddressDTO struct {
+	Street *string
+	Zip    *int64
+}
+
+type PointersDTO struct {
+	ID      int64
+	Name    *string
+	Age     *int64
+	Score   *float64
+	Active  *bool
+	Born    *time.Time
+	Avatar  *[]byte
+	Tags    []string
+	Address PointersAddressDTO
+	Extra   string
+}
+
+func TestToPointers(t *testing.T) {
+	require := require.New(t)
+
+	born := time.Date(2012, 12, 21, 21, 21, 21, 0, time.UTC)
+	m := PointersModel{
+		ID:      7,
+		Name:    null.NewString("Ada"),
+		Age:     null.NullInt64(),
+		Score:   null.NewFloat64(0),
+		Active:  null.NewBool(false),
+		Born:    null.NewTime(born),
+		Avatar:  null.NullByteSlice(),
+		Tags:    []string{"a"},
+		Address: PointersAddress{null.NewString("1 Main St"), null.NullInt64()},
+	}
+	dto := PointersDTO{Extra: "untouched", Age: new(int64)}
+	require.NoError(null.ToPointers(&dto, m))
+
+	require.Equal(int64(7), dto.ID)
+	require.Equal("Ada", *dto.Name)
+	require.Nil(dto.Age)
+	require.Equal(0.0, *dto.Score)
+	require.False(*dto.Active)
+	require.Equal(born, *dto.Born)
+	require.Nil(dto.Avatar)
+	require.Equal([]string{"a"}, dto.Tags)
+	require.Equal("1 Main St", *dto.Address.Street)
+	require.Nil(dto.Address.Zip)
+	require.Equal("untouched", dto.Extra)
+
+	// The pointers refer to copies, not to the source's values.
+	*dto.Name = "Grace"
+	require.Equal("Ada", m.Name.String)
+
+	// And back again.
+	var back PointersModel
+	require.NoError(null.FromPointers(&back, &dto))
+	require.Equal(PointersModel{
+		ID:      7,
+		Name:    null.NewString("Grace"),
+		Score:   null.NewFloat64(0),
+		Active:  null.NewBool(false),
+		Born:    null.NewTime(born),
+		Tags:    []string{"a"},
+		Address: PointersAddress{Street: null.NewString("1 Main St")},
+	}, back)
+}
+
+func TestPointersErrors(t *testing.T) {
+	require := require.New(t)
+
+	var dto PointersDTO
+	require.Error(null.ToPointers(dto, PointersModel{}))
+	require.Error(null.ToPointers((*PointersDTO)(nil), PointersModel{}))
+	require.Error(null.ToPointers(&dto, 42))
+	require.Error(null.FromPointers(&dto, (*PointersModel)(nil)))
+
+	// Mismatched types are reported by name.
+	var wrong struct{ Age *string }
+	err := null.ToPointers(&wrong, PointersModel{})
+	require.Error(err)
+	require.Contains(err.Error(), "Age")
+}
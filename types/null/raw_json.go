@@ -63,6 +63,16 @@ func (j RawJSON) ValueOrZero() types.RawJSON {
 	return j.JSON
 }
 
+// Or returns j if it is valid, or o otherwise. This is useful for layering a
+// value over a default; eg. userSetting.Or(defaultSetting). The underlying JSON
+// is not copied.
+func (j RawJSON) Or(o RawJSON) RawJSON {
+	if j.Valid {
+		return j
+	}
+	return o
+}
+
 // Set copies the given types.RawJSON value into j. If the given value is of
 // length 0, j will be nulled.
 func (j *RawJSON) Set(v types.RawJSON) {
@@ -88,6 +98,16 @@ func (j *RawJSON) SetStr(v string) {
 
 }
 
+// SetPtr sets j to the value pointed to by v, and guarantees it is valid. If v
+// is nil, j will be nulled.
+func (j *RawJSON) SetPtr(v *types.RawJSON) {
+	if v == nil {
+		j.Null()
+		return
+	}
+	j.Set(*v)
+}
+
 // Null will set j to null; j.Valid will be false, and j.JSON will contain no
 // meaningful value.
 func (j *RawJSON) Null() {
@@ -112,16 +132,29 @@ func (j RawJSON) IsZero() bool {
 	return j.JSON.IsZero()
 }
 
+// JSONSchemaType implements the JSONSchemaTyper interface. It will return an
+// empty type, and true, as a RawJSON may hold a JSON value of any type.
+func (j RawJSON) JSONSchemaType() (string, bool) {
+	return "", true
+}
+
 // Value implements the database/sql/driver Valuer interface. It will return the
 // value of j as a driver.Value. If j is valid, this function will first
 // validate the contained JSON returning either any encouted parsing errors, or
 // a []byte as a driver.Value. If j is null, nil will be returned, and no
 // validation will occur.
+//
+// The returned []byte is a copy of j's JSON, so drivers that hold on to it
+// won't see later changes to j, nor make any of their own.
 func (j RawJSON) Value() (driver.Value, error) {
 	if !j.Valid {
 		return nil, nil
 	}
-	return j.JSON.Value()
+	v, err := j.JSON.Value()
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), v.([]byte)...), nil
 }
 
 // Scan implements the database/sql Scanner interface. It expects to receive a
@@ -129,6 +162,11 @@ func (j RawJSON) Value() (driver.Value, error) {
 // database. A zero-length string or []byte, or a nil will be considered NULL,
 // and j will be nulled, otherwise the the value will be assigned to j. Scan
 // will not validate the incoming JSON.
+//
+// Drivers may reuse the []byte they pass to Scan once it returns -- as
+// sql.RawBytes are, for instance -- so it's copied into a newly allocated
+// buffer, rather than kept, or copied into j's existing buffer, which may be
+// shared with copies of j, or with values previously returned by Value.
 func (j *RawJSON) Scan(src interface{}) error {
 	if j == nil {
 		return fmt.Errorf("null.RawJSON: Scan called on nil pointer")
@@ -144,7 +182,7 @@ func (j *RawJSON) Scan(src interface{}) error {
 			j.Valid = false
 			return nil
 		}
-		j.JSON.Set(x)
+		j.JSON = types.NewJSON(x)
 		j.Valid = true
 		return nil
 	case string:
@@ -153,11 +191,12 @@ func (j *RawJSON) Scan(src interface{}) error {
 			j.Valid = false
 			return nil
 		}
-		j.JSON.SetStr(x)
+		j.JSON = types.NewJSONStr(x)
 		j.Valid = true
 		return nil
 	default:
-		return fmt.Errorf("null.RawJSON: cannot scan type %T (%v)", src, src)
+		return parseError("RawJSON", "Scan", src,
+			fmt.Errorf("cannot scan type %T (%v)", src, src))
 	}
 }
 
@@ -183,7 +222,7 @@ func (j *RawJSON) UnmarshalJSON(data []byte) error {
 	}
 	var k interface{}
 	if err := json.Unmarshal(data, &k); err != nil {
-		return err
+		return parseError("RawJSON", "UnmarshalJSON", data, err)
 	}
 	if k == nil {
 		j.JSON = nil
@@ -205,3 +244,60 @@ func (j RawJSON) MarshalMapValue() (interface{}, error) {
 	}
 	return j.JSON.MarshalMapValue()
 }
+
+// UnmarshalMapValue implements the pyrrho/encoding/maps Unmarshaler interface.
+// It will encode v into j by passing it through json.Marshal, or decode nil or
+// the JSON 'null' keyword into a null RawJSON.
+//
+// If the decode fails, the value of j will be unchanged.
+func (j *RawJSON) UnmarshalMapValue(v interface{}) error {
+	if j == nil {
+		return fmt.Errorf("null.RawJSON: UnmarshalMapValue called on nil pointer")
+	}
+	if isNullMapValue(v) {
+		j.Null()
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return parseError("RawJSON", "UnmarshalMapValue", v, err)
+	}
+	j.Set(data)
+	return nil
+}
+
+// MarshalBinary implements the encoding BinaryMarshaler interface. It will
+// encode j into a validity byte followed by the contained JSON if valid, or a
+// single zero byte otherwise. The contained JSON will not be validated.
+func (j RawJSON) MarshalBinary() ([]byte, error) {
+	if !j.Valid {
+		return []byte{0}, nil
+	}
+	ret := make([]byte, 1, len(j.JSON)+1)
+	ret[0] = 1
+	return append(ret, j.JSON...), nil
+}
+
+// UnmarshalBinary implements the encoding BinaryUnmarshaler interface. It will
+// decode a given []byte into j, so long as the provided []byte was produced by
+// RawJSON.MarshalBinary. The decoded JSON will not be validated.
+//
+// If the decode fails, the value of j will be unchanged.
+func (j *RawJSON) UnmarshalBinary(data []byte) error {
+	if j == nil {
+		return fmt.Errorf("null.RawJSON: UnmarshalBinary called on nil pointer")
+	}
+	switch {
+	case len(data) == 1 && data[0] == 0:
+		j.JSON = nil
+		j.Valid = false
+		return nil
+	case len(data) > 1 && data[0] == 1:
+		j.JSON.Set(data[1:])
+		j.Valid = true
+		return nil
+	default:
+		return parseError("RawJSON", "UnmarshalBinary", data,
+			fmt.Errorf("cannot unmarshal binary data (%v)", data))
+	}
+}
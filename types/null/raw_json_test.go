@@ -214,6 +214,32 @@ func TestRawJSONSQLValue(t *testing.T) {
 	require.Error(err)
 }
 
+func TestRawJSONSQLBufferLifetimes(t *testing.T) {
+	require := require.New(t)
+
+	// Mutating the driver's buffer after Scan doesn't affect the scanned value.
+	src := []byte(`{"a":1}`)
+	var j null.RawJSON
+	require.NoError(j.Scan(src))
+	copy(src, `{"b":2}`)
+	require.EqualValues(`{"a":1}`, j.JSON)
+
+	// Values returned by Value, and copies of j, are unaffected by later
+	// Scans, and by changes to the returned value.
+	val, err := j.Value()
+	require.NoError(err)
+	k := j
+	require.NoError(j.Scan([]byte(`[0,0]`)))
+	require.EqualValues(`{"a":1}`, val)
+	require.EqualValues(`{"a":1}`, k.JSON)
+	val.([]byte)[1] = 'x'
+	require.EqualValues(`{"a":1}`, k.JSON)
+
+	// SQL NULL results in a null RawJSON, whatever j held before.
+	require.NoError(j.Scan(nil))
+	require.Equal(null.NullJSON(), j)
+}
+
 func TestRawJSONSQLScan(t *testing.T) {
 	require := require.New(t)
 	var err error
@@ -444,3 +470,44 @@ func TestRawJSONMarshalMapValue(t *testing.T) {
 	// This error should include information on the malformed object.
 	require.Contains(err.Error(), "invalid character 'b'")
 }
+
+func TestRawJSONMarshalBinary(t *testing.T) {
+	require := require.New(t)
+	var data []byte
+	var err error
+
+	j := null.NewJSONStr(`{"foo":"bar"}`)
+	data, err = j.MarshalBinary()
+	require.NoError(err)
+	require.Equal(append([]byte{0x01}, `{"foo":"bar"}`...), data)
+	var rj null.RawJSON
+	err = rj.UnmarshalBinary(data)
+	require.NoError(err)
+	require.Equal(j, rj)
+
+	nul := null.RawJSON{}
+	data, err = nul.MarshalBinary()
+	require.NoError(err)
+	require.Equal([]byte{0x00}, data)
+	rnul := null.NewJSONStr(`{"foo":"bar"}`)
+	err = rnul.UnmarshalBinary(data)
+	require.NoError(err)
+	require.Equal(nul, rnul)
+
+	var bad null.RawJSON
+	err = bad.UnmarshalBinary([]byte{0x01})
+	require.Error(err)
+}
+
+func TestRawJSONSetPtr(t *testing.T) {
+	require := require.New(t)
+
+	v := types.RawJSON(`{"a":1}`)
+	var n null.RawJSON
+	n.SetPtr(&v)
+	require.Equal(null.NewJSONStr(`{"a":1}`), n)
+
+	n.SetPtr(nil)
+	require.False(n.Valid)
+	require.Equal(null.NullJSON(), n)
+}
@@ -0,0 +1,124 @@
+package null
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+// RetainedFloat64 is a Float64 that remembers the exact text it was decoded
+// from by UnmarshalJSON or UnmarshalText, and re-emits it verbatim from
+// MarshalJSON -- "1.50" rather than "1.5" -- so that documents round-trip
+// byte-for-byte where a Float64 would reformat them; eg. when a signature or
+// checksum is computed over them. It otherwise behaves exactly as a Float64,
+// and is meant to be used in place of one only where that fidelity is needed.
+//
+// The retained text is dropped by Set, SetPtr, and Null, and replaced by each
+// decode. If the value is changed in any other way -- by Scan, by assigning to
+// the Float64 field, or through SQL -- the text is ignored once it no longer
+// parses to the current value. Note that, because the text is part of the
+// value, two RetainedFloat64s decoded from "1.5" and "1.50" are not ==.
+type RetainedFloat64 struct {
+	Float64
+
+	text string
+}
+
+// Constructors
+
+// NullRetainedFloat64 constructs and returns a new null RetainedFloat64.
+func NullRetainedFloat64() RetainedFloat64 {
+	return RetainedFloat64{Float64: NullFloat64()}
+}
+
+// NewRetainedFloat64 constructs and returns a new valid RetainedFloat64
+// initialized with the value f, and no retained text.
+func NewRetainedFloat64(f float64) RetainedFloat64 {
+	return RetainedFloat64{Float64: NewFloat64(f)}
+}
+
+// Getters and Setters
+
+// Text returns the text r was decoded from, and true, if r still holds the
+// value that text describes. Otherwise, it returns an empty string and false.
+func (r RetainedFloat64) Text() (string, bool) {
+	if !r.Valid || r.text == "" {
+		return "", false
+	}
+	if v, err := strconv.ParseFloat(r.text, 64); err != nil || v != r.Float64.Float64 {
+		return "", false
+	}
+	return r.text, true
+}
+
+// Set modifies the value stored in r, guarantees it is valid, and drops any
+// retained text.
+func (r *RetainedFloat64) Set(v float64) {
+	r.Float64.Set(v)
+	r.text = ""
+}
+
+// SetPtr modifies the value stored in r as Float64.SetPtr does, and drops any
+// retained text.
+func (r *RetainedFloat64) SetPtr(v *float64) {
+	r.Float64.SetPtr(v)
+	r.text = ""
+}
+
+// Null marks r as null, and drops any retained text.
+func (r *RetainedFloat64) Null() {
+	r.Float64.Null()
+	r.text = ""
+}
+
+// Interfaces
+
+// MarshalJSON implements the encoding/json Marshaler interface. It will encode
+// the text r was decoded from, so long as it's still current, and is a valid
+// JSON number. Otherwise -- and for null values, or zero values if ZeroAsNull
+// is true -- r is encoded as Float64.MarshalJSON would encode it.
+func (r RetainedFloat64) MarshalJSON() ([]byte, error) {
+	if !ZeroAsNull || !r.IsZero() {
+		if t, ok := r.Text(); ok && isJSONNumber(t) {
+			return []byte(t), nil
+		}
+	}
+	return r.Float64.MarshalJSON()
+}
+
+// UnmarshalJSON implements the encoding/json Unmarshaler interface. It decodes
+// data as Float64.UnmarshalJSON does, and retains the text of valid numbers.
+//
+// If the decode fails, the value of r will be unchanged.
+func (r *RetainedFloat64) UnmarshalJSON(data []byte) error {
+	if r == nil {
+		return fmt.Errorf("null.RetainedFloat64: UnmarshalJSON called on nil pointer")
+	}
+	if err := r.Float64.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	r.text = ""
+	if r.Valid {
+		r.text = string(trimJSONSpace(data))
+	}
+	return nil
+}
+
+// UnmarshalText implements the encoding TextUnmarshaler interface. It decodes
+// text as Float64.UnmarshalText does, and retains the text -- trimmed of
+// surrounding whitespace -- of valid numbers.
+//
+// If the decode fails, the value of r will be unchanged.
+func (r *RetainedFloat64) UnmarshalText(text []byte) error {
+	if r == nil {
+		return fmt.Errorf("null.RetainedFloat64: UnmarshalText called on nil pointer")
+	}
+	if err := r.Float64.UnmarshalText(text); err != nil {
+		return err
+	}
+	r.text = ""
+	if r.Valid {
+		r.text = string(bytes.TrimSpace(text))
+	}
+	return nil
+}
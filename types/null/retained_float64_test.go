@@ -0,0 +1,100 @@
+package null_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pyrrho/encoding/types/null"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetainedFloat64Ctors(t *testing.T) {
+	require := require.New(t)
+
+	require.Equal(null.NewFloat64(1.5), null.NewRetainedFloat64(1.5).Float64)
+	require.Equal(null.NullFloat64(), null.NullRetainedFloat64().Float64)
+	_, ok := null.NewRetainedFloat64(1.5).Text()
+	require.False(ok)
+}
+
+func TestRetainedFloat64JSON(t *testing.T) {
+	require := require.New(t)
+
+	var r null.RetainedFloat64
+	require.NoError(json.Unmarshal([]byte(" 1.50 "), &r))
+	text, ok := r.Text()
+	require.True(ok)
+	require.Equal("1.50", text)
+	require.Equal(null.NewFloat64(1.5), r.Float64)
+	data, err := json.Marshal(r)
+	require.NoError(err)
+	require.Equal("1.50", string(data))
+
+	require.NoError(r.UnmarshalText([]byte("1e2")))
+	data, err = json.Marshal(r)
+	require.NoError(err)
+	require.Equal("1e2", string(data))
+
+	// Text that isn't a JSON number is kept, but not encoded.
+	require.NoError(r.UnmarshalText([]byte("+7")))
+	text, _ = r.Text()
+	require.Equal("+7", text)
+	data, err = json.Marshal(r)
+	require.NoError(err)
+	require.Equal("7", string(data))
+
+	// Failed decodes leave r unchanged.
+	require.Error(json.Unmarshal([]byte(`"two"`), &r))
+	text, _ = r.Text()
+	require.Equal("+7", text)
+
+	require.NoError(json.Unmarshal([]byte("null"), &r))
+	require.Equal(null.NullRetainedFloat64(), r)
+	data, err = json.Marshal(r)
+	require.NoError(err)
+	require.Equal("null", string(data))
+
+	// Plain Float64s are unaffected.
+	var f null.Float64
+	require.NoError(json.Unmarshal([]byte("1.50"), &f))
+	require.Equal(null.NewFloat64(1.5), f)
+}
+
+func TestRetainedFloat64Invalidation(t *testing.T) {
+	require := require.New(t)
+
+	// Setting the value drops the text ...
+	var r null.RetainedFloat64
+	require.NoError(json.Unmarshal([]byte("1.50"), &r))
+	r.Set(1.5)
+	_, ok := r.Text()
+	require.False(ok)
+	require.Equal(null.NewRetainedFloat64(1.5), r)
+
+	require.NoError(json.Unmarshal([]byte("1.50"), &r))
+	r.Null()
+	require.Equal(null.NullRetainedFloat64(), r)
+
+	// ... and changing it in any other way makes the text stale.
+	require.NoError(json.Unmarshal([]byte("1.50"), &r))
+	r.Float64.Float64 = 2
+	_, ok = r.Text()
+	require.False(ok)
+	data, err := json.Marshal(r)
+	require.NoError(err)
+	require.Equal("2", string(data))
+
+	require.NoError(json.Unmarshal([]byte("1.50"), &r))
+	require.NoError(r.Scan(int64(3)))
+	data, err = json.Marshal(r)
+	require.NoError(err)
+	require.Equal("3", string(data))
+
+	// ZeroAsNull is respected.
+	defer func(v bool) { null.ZeroAsNull = v }(null.ZeroAsNull)
+	null.ZeroAsNull = true
+	require.NoError(json.Unmarshal([]byte("0.0"), &r))
+	data, err = json.Marshal(r)
+	require.NoError(err)
+	require.Equal("null", string(data))
+}
@@ -0,0 +1,155 @@
+package null
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	scannerType = reflect.TypeOf(new(sql.Scanner)).Elem()
+	bytesType   = reflect.TypeOf([]byte(nil))
+	timeType    = reflect.TypeOf(time.Time{})
+)
+
+// ScanStruct scans the columns of the current row of rows into the fields of
+// the struct pointed to by dst, matching each column to a field by name, rather
+// than by position. A field's name is taken from its "map" tag, or from its
+// "db" tag if it has no "map" tag -- in either case, the part before the first
+// comma -- or is the field's own name if it has neither. Tagged names must
+// match column names exactly; field names are matched case-insensitively.
+// Fields tagged "-", and unexported fields, are skipped. The fields of embedded
+// structs are scanned as if they belonged to dst.
+//
+// Each field is scanned by database/sql, so the null types -- and any other
+// sql.Scanner -- will be scanned by their Scan methods, and NULL columns will
+// result in null values. Fields may also be of the bool, integer, float,
+// string, []byte, time.Time, and interface{} types sql.Rows.Scan accepts, or
+// pointers to them. Fields of any other type are an error.
+//
+// Every column must match a field, and no two columns may match the same field;
+// mismatches are reported as errors before anything is scanned. Fields that
+// match no column are left unchanged. As with sql.Rows.Scan, rows.Next must be
+// called before each call to ScanStruct, and it is the caller's responsibility
+// to Close rows.
+func ScanStruct(rows *sql.Rows, dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("null.ScanStruct: dst must be a non-nil pointer to a struct, not %T", dst)
+	}
+	v = v.Elem()
+	cols, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	fields := scanFields(v.Type(), nil)
+	// Fields of dst shadow those of its embedded structs.
+	sort.SliceStable(fields, func(i, j int) bool {
+		return len(fields[i].index) < len(fields[j].index)
+	})
+
+	targets := make([]interface{}, len(cols))
+	used := make(map[string]string, len(cols)) // field index to column
+	for i, col := range cols {
+		f, ok := matchScanField(fields, col)
+		if !ok {
+			return fmt.Errorf("null.ScanStruct: no field of %s matches column %q", v.Type(), col)
+		}
+		key := fmt.Sprint(f.index)
+		if prev, dup := used[key]; dup {
+			return fmt.Errorf("null.ScanStruct: columns %q and %q both match field %s.%s",
+				prev, col, v.Type(), f.goName)
+		}
+		used[key] = col
+		if !scannable(f.typ) {
+			return fmt.Errorf("null.ScanStruct: field %s.%s of type %s, matching column %q, cannot be scanned",
+				v.Type(), f.goName, f.typ, col)
+		}
+		targets[i] = v.FieldByIndex(f.index).Addr().Interface()
+	}
+	return rows.Scan(targets...)
+}
+
+// scanField describes a field of a struct passed to ScanStruct.
+type scanField struct {
+	name   string // the column name the field will be matched to
+	tagged bool   // whether name came from a tag
+	goName string // the name of the field in Go
+	index  []int
+	typ    reflect.Type
+}
+
+// scanFields returns the fields of t that may be matched to columns by
+// ScanStruct, including those of its embedded structs.
+func scanFields(t reflect.Type, index []int) []scanField {
+	var fields []scanField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		idx := append(append([]int(nil), index...), i)
+		tag := sf.Tag.Get("map")
+		if tag == "" {
+			tag = sf.Tag.Get("db")
+		}
+		name := tag
+		if c := strings.IndexByte(tag, ','); c >= 0 {
+			name = tag[:c]
+		}
+		if name == "-" {
+			continue
+		}
+		if sf.Anonymous && name == "" && sf.Type.Kind() == reflect.Struct &&
+			!reflect.PtrTo(sf.Type).Implements(scannerType) {
+			fields = append(fields, scanFields(sf.Type, idx)...)
+			continue
+		}
+		if sf.PkgPath != "" {
+			continue
+		}
+		f := scanField{name: name, tagged: name != "", goName: sf.Name, index: idx, typ: sf.Type}
+		if !f.tagged {
+			f.name = sf.Name
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// matchScanField returns the field of fields matching the column col,
+// preferring exact matches of tagged names, and then of field names, to
+// case-insensitive matches of field names.
+func matchScanField(fields []scanField, col string) (scanField, bool) {
+	for _, f := range fields {
+		if f.name == col {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if !f.tagged && strings.EqualFold(f.name, col) {
+			return f, true
+		}
+	}
+	return scanField{}, false
+}
+
+// scannable returns true if a pointer to a value of type t is a destination
+// sql.Rows.Scan accepts.
+func scannable(t reflect.Type) bool {
+	if reflect.PtrTo(t).Implements(scannerType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Interface:
+		return t.NumMethod() == 0
+	case reflect.Ptr:
+		return t.Elem().Kind() != reflect.Ptr && scannable(t.Elem())
+	}
+	return t == bytesType || t == timeType
+}
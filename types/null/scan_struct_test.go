@@ -0,0 +1,96 @@
+package null_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/pyrrho/encoding/types/null"
+	"github.com/stretchr/testify/require"
+)
+
+type ScanAudit struct {
+	CreatedBy null.String `db:"created_by"`
+}
+
+type ScanUser struct {
+	ScanAudit
+	ID      int64       `map:"id" db:"user_id"`
+	Name    null.String `db:"name,omitempty"`
+	Age     null.Int64
+	Nick    *string
+	Ignored null.String `map:"-"`
+	secret  string
+}
+
+func TestScanStruct(t *testing.T) {
+	require := require.New(t)
+
+	db, err := sql.Open("null_test_rows", "")
+	require.NoError(err)
+	defer db.Close()
+
+	rows, err := db.Query("id,name,AGE,nick,created_by\n1,Ada,36,NULL,root\n2,NULL,NULL,bob,NULL")
+	require.NoError(err)
+	defer rows.Close()
+
+	var users []ScanUser
+	for rows.Next() {
+		u := ScanUser{Ignored: null.NewString("kept")}
+		require.NoError(null.ScanStruct(rows, &u))
+		users = append(users, u)
+	}
+	require.NoError(rows.Err())
+	bob := "bob"
+	require.Equal([]ScanUser{{
+		ScanAudit: ScanAudit{CreatedBy: null.NewString("root")},
+		ID:        1,
+		Name:      null.NewString("Ada"),
+		Age:       null.NewInt64(36),
+		Ignored:   null.NewString("kept"),
+	}, {
+		ID:      2,
+		Nick:    &bob,
+		Ignored: null.NewString("kept"),
+	}}, users)
+}
+
+func TestScanStructErrors(t *testing.T) {
+	require := require.New(t)
+
+	db, err := sql.Open("null_test_rows", "")
+	require.NoError(err)
+	defer db.Close()
+
+	scan := func(query string, dst interface{}) error {
+		rows, err := db.Query(query)
+		require.NoError(err)
+		defer rows.Close()
+		require.True(rows.Next())
+		return null.ScanStruct(rows, dst)
+	}
+
+	var u ScanUser
+	err = scan("id,email\n1,a@b.c", &u)
+	require.EqualError(err, `null.ScanStruct: no field of null_test.ScanUser matches column "email"`)
+	err = scan("user_id\n1", &u)
+	require.EqualError(err, `null.ScanStruct: no field of null_test.ScanUser matches column "user_id"`)
+	err = scan("secret\nshh", &u)
+	require.Error(err)
+	err = scan("age,Age\n1,2", &u)
+	require.EqualError(err, `null.ScanStruct: columns "age" and "Age" both match field null_test.ScanUser.Age`)
+	require.Equal(ScanUser{}, u)
+
+	var bad struct {
+		Tags []string `db:"tags"`
+	}
+	err = scan("tags\nx", &bad)
+	require.EqualError(err,
+		`null.ScanStruct: field struct { Tags []string "db:\"tags\"" }.Tags of type []string, matching column "tags", cannot be scanned`)
+
+	err = scan("id\n1", u)
+	require.EqualError(err, "null.ScanStruct: dst must be a non-nil pointer to a struct, not null_test.ScanUser")
+
+	// Errors from Scan are returned as-is.
+	err = scan("age\nold", &u)
+	require.Error(err)
+}
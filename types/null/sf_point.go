@@ -68,6 +68,15 @@ func (p SFPoint) ValueOrZero() types.SFPoint {
 	return p.Point
 }
 
+// Or returns p if it is valid, or o otherwise. This is useful for layering a
+// value over a default; eg. userSetting.Or(defaultSetting).
+func (p SFPoint) Or(o SFPoint) SFPoint {
+	if p.Valid {
+		return p
+	}
+	return o
+}
+
 // Set copies the given types.SFPoint value into p. If the given value is nil,
 // p will be nulled.
 func (p *SFPoint) Set(v types.SFPoint) {
@@ -80,6 +89,16 @@ func (p *SFPoint) Set(v types.SFPoint) {
 	p.Valid = true
 }
 
+// SetPtr sets p to the value pointed to by v, and guarantees it is valid. If v
+// is nil, p will be nulled.
+func (p *SFPoint) SetPtr(v *types.SFPoint) {
+	if v == nil {
+		p.Null()
+		return
+	}
+	p.Set(*v)
+}
+
 // Null will set p to null; p.Valid will be false, and p.Point will contain no
 // meaningful value.
 func (p *SFPoint) Null() {
@@ -104,6 +123,12 @@ func (p SFPoint) IsZero() bool {
 	return p.Point.IsZero()
 }
 
+// JSONSchemaType implements the JSONSchemaTyper interface. It will return
+// "object", and true, as valid SFPoints are encoded as GeoJSON objects.
+func (p SFPoint) JSONSchemaType() (string, bool) {
+	return "object", true
+}
+
 // Value implements the database/sql/driver Valuer interface. It will return the
 // value of p as a driver.Value. If p is null, nil will be returned.
 func (p SFPoint) Value() (driver.Value, error) {
@@ -124,6 +149,7 @@ func (p *SFPoint) Scan(src interface{}) error {
 	}
 	switch x := src.(type) {
 	case nil:
+		p.Point = types.SFPoint{}
 		p.Valid = false
 		return nil
 	case []byte:
@@ -134,12 +160,13 @@ func (p *SFPoint) Scan(src interface{}) error {
 		}
 		err := p.Point.Scan(x)
 		if err != nil {
-			return err
+			return parseError("SFPoint", "Scan", src, err)
 		}
 		p.Valid = true
 		return nil
 	default:
-		return fmt.Errorf("null.SFPoint: cannot scan type %T (%v)", src, src)
+		return parseError("SFPoint", "Scan", src,
+			fmt.Errorf("cannot scan type %T (%v)", src, src))
 	}
 }
 
@@ -162,7 +189,7 @@ func (p *SFPoint) UnmarshalJSON(data []byte) error {
 	}
 	var k interface{}
 	if err := json.Unmarshal(data, &k); err != nil {
-		return err
+		return parseError("SFPoint", "UnmarshalJSON", data, err)
 	}
 	if k == nil {
 		p.Point = types.SFPoint{}
@@ -170,7 +197,7 @@ func (p *SFPoint) UnmarshalJSON(data []byte) error {
 		return nil
 	}
 	if err := p.Point.UnmarshalJSON(data); err != nil {
-		return err
+		return parseError("SFPoint", "UnmarshalJSON", data, err)
 	}
 	p.Valid = true
 	return nil
@@ -186,3 +213,78 @@ func (p SFPoint) MarshalMapValue() (interface{}, error) {
 	}
 	return p.Point.MarshalMapValue()
 }
+
+// UnmarshalMapValue implements the pyrrho/encoding/maps Unmarshaler interface.
+// It will decode the types.SFPoint v, or the GeoJSON representation of a Point
+// as a map[string]interface{}, into p, or decode nil or the JSON 'null' keyword
+// into a null SFPoint.
+//
+// If the decode fails, the value of p will be unchanged.
+func (p *SFPoint) UnmarshalMapValue(v interface{}) error {
+	if p == nil {
+		return fmt.Errorf("null.SFPoint: UnmarshalMapValue called on nil pointer")
+	}
+	switch val := v.(type) {
+	case types.SFPoint:
+		p.Set(val)
+	case map[string]interface{}:
+		data, err := json.Marshal(val)
+		if err != nil {
+			return parseError("SFPoint", "UnmarshalMapValue", v, err)
+		}
+		var tmp types.SFPoint
+		if err := tmp.UnmarshalJSON(data); err != nil {
+			return parseError("SFPoint", "UnmarshalMapValue", v, err)
+		}
+		p.Set(tmp)
+	default:
+		if !isNullMapValue(v) {
+			return parseError("SFPoint", "UnmarshalMapValue", v,
+				fmt.Errorf("cannot unmarshal map value of type %T", v))
+		}
+		p.Null()
+	}
+	return nil
+}
+
+// MarshalBinary implements the encoding BinaryMarshaler interface. It will
+// encode p into a validity byte followed by the WKB representation of the
+// contained SFPoint if valid, or a single zero byte otherwise.
+func (p SFPoint) MarshalBinary() ([]byte, error) {
+	if !p.Valid {
+		return []byte{0}, nil
+	}
+	v, err := p.Point.Value()
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{1}, v.([]byte)...), nil
+}
+
+// UnmarshalBinary implements the encoding BinaryUnmarshaler interface. It will
+// decode a given []byte into p, so long as the provided []byte was produced by
+// SFPoint.MarshalBinary.
+//
+// If the decode fails, the value of p will be unchanged.
+func (p *SFPoint) UnmarshalBinary(data []byte) error {
+	if p == nil {
+		return fmt.Errorf("null.SFPoint: UnmarshalBinary called on nil pointer")
+	}
+	switch {
+	case len(data) == 1 && data[0] == 0:
+		p.Point = types.SFPoint{}
+		p.Valid = false
+		return nil
+	case len(data) > 1 && data[0] == 1:
+		var tmp types.SFPoint
+		if err := tmp.Scan(data[1:]); err != nil {
+			return parseError("SFPoint", "UnmarshalBinary", data, err)
+		}
+		p.Point = tmp
+		p.Valid = true
+		return nil
+	default:
+		return parseError("SFPoint", "UnmarshalBinary", data,
+			fmt.Errorf("cannot unmarshal binary data (%v)", data))
+	}
+}
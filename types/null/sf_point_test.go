@@ -185,3 +185,44 @@ func TestSFPointMarshsalMapValue(t *testing.T) {
 	require.NoError(err)
 	require.Equal(testSFPointXY, data["Point"])
 }
+
+func TestSFPointMarshalBinary(t *testing.T) {
+	require := require.New(t)
+	var data []byte
+	var err error
+
+	p := null.NewSFPointXY(1.2, 2.3)
+	data, err = p.MarshalBinary()
+	require.NoError(err)
+	require.Equal(append([]byte{0x01}, testPointXYWKB...), data)
+	var rp null.SFPoint
+	err = rp.UnmarshalBinary(data)
+	require.NoError(err)
+	require.Equal(p, rp)
+
+	n := null.SFPoint{}
+	data, err = n.MarshalBinary()
+	require.NoError(err)
+	require.Equal([]byte{0x00}, data)
+	rn := null.NewSFPointXY(1.2, 2.3)
+	err = rn.UnmarshalBinary(data)
+	require.NoError(err)
+	require.Equal(n, rn)
+
+	var bad null.SFPoint
+	err = bad.UnmarshalBinary([]byte{0x01, 0x02})
+	require.Error(err)
+}
+
+func TestSFPointSetPtr(t *testing.T) {
+	require := require.New(t)
+
+	v := types.NewSFPointXY(1.2, 2.3)
+	var n null.SFPoint
+	n.SetPtr(&v)
+	require.Equal(null.NewSFPointXY(1.2, 2.3), n)
+
+	n.SetPtr(nil)
+	require.False(n.Valid)
+	require.Equal(null.NullSFPoint(), n)
+}
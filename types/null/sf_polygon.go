@@ -68,6 +68,15 @@ func (p SFPolygon) ValueOrZero() types.SFPolygon {
 	return p.Polygon
 }
 
+// Or returns p if it is valid, or o otherwise. This is useful for layering a
+// value over a default; eg. userSetting.Or(defaultSetting).
+func (p SFPolygon) Or(o SFPolygon) SFPolygon {
+	if p.Valid {
+		return p
+	}
+	return o
+}
+
 // Set copies the given types.SFPolygon value into p. If the given value is nil,
 // p will be nulled.
 func (p *SFPolygon) Set(v types.SFPolygon) {
@@ -80,6 +89,16 @@ func (p *SFPolygon) Set(v types.SFPolygon) {
 	p.Valid = true
 }
 
+// SetPtr sets p to the value pointed to by v, and guarantees it is valid. If v
+// is nil, p will be nulled.
+func (p *SFPolygon) SetPtr(v *types.SFPolygon) {
+	if v == nil {
+		p.Null()
+		return
+	}
+	p.Set(*v)
+}
+
 // Null will set p to null; p.Valid will be false, and p.Polygon will contain no
 // meaningful value.
 func (p *SFPolygon) Null() {
@@ -104,6 +123,12 @@ func (p SFPolygon) IsZero() bool {
 	return p.Polygon.IsZero()
 }
 
+// JSONSchemaType implements the JSONSchemaTyper interface. It will return
+// "object", and true, as valid SFPolygons are encoded as GeoJSON objects.
+func (p SFPolygon) JSONSchemaType() (string, bool) {
+	return "object", true
+}
+
 // Value implements the database/sql/driver Valuer interface. It will return the
 // value of p as a driver.Value. If p is null, nil will be returned.
 func (p SFPolygon) Value() (driver.Value, error) {
@@ -124,6 +149,7 @@ func (p *SFPolygon) Scan(src interface{}) error {
 	}
 	switch x := src.(type) {
 	case nil:
+		p.Polygon = types.SFPolygon{}
 		p.Valid = false
 		return nil
 	case []byte:
@@ -134,12 +160,13 @@ func (p *SFPolygon) Scan(src interface{}) error {
 		}
 		err := p.Polygon.Scan(x)
 		if err != nil {
-			return err
+			return parseError("SFPolygon", "Scan", src, err)
 		}
 		p.Valid = true
 		return nil
 	default:
-		return fmt.Errorf("null.SFPolygon: cannot scan type %T (%v)", src, src)
+		return parseError("SFPolygon", "Scan", src,
+			fmt.Errorf("cannot scan type %T (%v)", src, src))
 	}
 }
 
@@ -162,7 +189,7 @@ func (p *SFPolygon) UnmarshalJSON(data []byte) error {
 	}
 	var k interface{}
 	if err := json.Unmarshal(data, &k); err != nil {
-		return err
+		return parseError("SFPolygon", "UnmarshalJSON", data, err)
 	}
 	if k == nil {
 		p.Polygon = types.SFPolygon{}
@@ -170,7 +197,7 @@ func (p *SFPolygon) UnmarshalJSON(data []byte) error {
 		return nil
 	}
 	if err := p.Polygon.UnmarshalJSON(data); err != nil {
-		return err
+		return parseError("SFPolygon", "UnmarshalJSON", data, err)
 	}
 	p.Valid = true
 	return nil
@@ -186,3 +213,78 @@ func (p SFPolygon) MarshalMapValue() (interface{}, error) {
 	}
 	return p.Polygon.MarshalMapValue()
 }
+
+// UnmarshalMapValue implements the pyrrho/encoding/maps Unmarshaler interface.
+// It will decode the types.SFPolygon v, or the GeoJSON representation of a
+// Polygon as a map[string]interface{}, into p, or decode nil or the JSON 'null'
+// keyword into a null SFPolygon.
+//
+// If the decode fails, the value of p will be unchanged.
+func (p *SFPolygon) UnmarshalMapValue(v interface{}) error {
+	if p == nil {
+		return fmt.Errorf("null.SFPolygon: UnmarshalMapValue called on nil pointer")
+	}
+	switch val := v.(type) {
+	case types.SFPolygon:
+		p.Set(val)
+	case map[string]interface{}:
+		data, err := json.Marshal(val)
+		if err != nil {
+			return parseError("SFPolygon", "UnmarshalMapValue", v, err)
+		}
+		var tmp types.SFPolygon
+		if err := tmp.UnmarshalJSON(data); err != nil {
+			return parseError("SFPolygon", "UnmarshalMapValue", v, err)
+		}
+		p.Set(tmp)
+	default:
+		if !isNullMapValue(v) {
+			return parseError("SFPolygon", "UnmarshalMapValue", v,
+				fmt.Errorf("cannot unmarshal map value of type %T", v))
+		}
+		p.Null()
+	}
+	return nil
+}
+
+// MarshalBinary implements the encoding BinaryMarshaler interface. It will
+// encode p into a validity byte followed by the WKB representation of the
+// contained SFPolygon if valid, or a single zero byte otherwise.
+func (p SFPolygon) MarshalBinary() ([]byte, error) {
+	if !p.Valid {
+		return []byte{0}, nil
+	}
+	v, err := p.Polygon.Value()
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{1}, v.([]byte)...), nil
+}
+
+// UnmarshalBinary implements the encoding BinaryUnmarshaler interface. It will
+// decode a given []byte into p, so long as the provided []byte was produced by
+// SFPolygon.MarshalBinary.
+//
+// If the decode fails, the value of p will be unchanged.
+func (p *SFPolygon) UnmarshalBinary(data []byte) error {
+	if p == nil {
+		return fmt.Errorf("null.SFPolygon: UnmarshalBinary called on nil pointer")
+	}
+	switch {
+	case len(data) == 1 && data[0] == 0:
+		p.Polygon = types.SFPolygon{}
+		p.Valid = false
+		return nil
+	case len(data) > 1 && data[0] == 1:
+		var tmp types.SFPolygon
+		if err := tmp.Scan(data[1:]); err != nil {
+			return parseError("SFPolygon", "UnmarshalBinary", data, err)
+		}
+		p.Polygon = tmp
+		p.Valid = true
+		return nil
+	default:
+		return parseError("SFPolygon", "UnmarshalBinary", data,
+			fmt.Errorf("cannot unmarshal binary data (%v)", data))
+	}
+}
@@ -267,3 +267,44 @@ func TestSFPolygonMarshsalMapValue(t *testing.T) {
 	require.NoError(err)
 	require.Equal(testSFPolygonXY, data["Polygon"])
 }
+
+func TestSFPolygonMarshalBinary(t *testing.T) {
+	require := require.New(t)
+	var data []byte
+	var err error
+
+	p := null.NewSFPolygonXY(testPolygonExternal, testPolygonInternal)
+	data, err = p.MarshalBinary()
+	require.NoError(err)
+	require.Equal(append([]byte{0x01}, testPolygonWKB...), data)
+	var rp null.SFPolygon
+	err = rp.UnmarshalBinary(data)
+	require.NoError(err)
+	require.Equal(p, rp)
+
+	n := null.SFPolygon{}
+	data, err = n.MarshalBinary()
+	require.NoError(err)
+	require.Equal([]byte{0x00}, data)
+	rn := null.NewSFPolygonXY(testPolygonExternal, testPolygonInternal)
+	err = rn.UnmarshalBinary(data)
+	require.NoError(err)
+	require.Equal(n, rn)
+
+	var bad null.SFPolygon
+	err = bad.UnmarshalBinary([]byte{0x01, 0x02})
+	require.Error(err)
+}
+
+func TestSFPolygonSetPtr(t *testing.T) {
+	require := require.New(t)
+
+	v := types.NewSFPolygonXY([][2]float64{{30, 10}, {40, 40}, {20, 40}, {30, 10}})
+	var n null.SFPolygon
+	n.SetPtr(&v)
+	require.Equal(null.NewSFPolygonXY([][2]float64{{30, 10}, {40, 40}, {20, 40}, {30, 10}}), n)
+
+	n.SetPtr(nil)
+	require.False(n.Valid)
+	require.Equal(null.NullSFPolygon(), n)
+}
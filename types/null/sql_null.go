@@ -0,0 +1,97 @@
+//go:build go1.22
+
+package null
+
+import (
+	"database/sql"
+	"time"
+)
+
+// The functions and methods in this file bridge between this package's types
+// and the generic sql.Null[T] type added to the standard library in Go 1.22.
+// They're only available when building with Go 1.22 or later. As with the
+// FromSQL constructors, invalid sql.Null[T]s produce null, zeroed values.
+
+// BoolFromSQLNull constructs and returns a new Bool initialized with the value
+// and validity of the given sql.Null[bool] n.
+func BoolFromSQLNull(n sql.Null[bool]) Bool {
+	if !n.Valid {
+		return NullBool()
+	}
+	return Bool{sql.NullBool{Bool: n.V, Valid: n.Valid}}
+}
+
+// SQLNull returns the value and validity of b as a sql.Null[bool].
+func (b Bool) SQLNull() sql.Null[bool] {
+	return sql.Null[bool]{V: b.Bool, Valid: b.Valid}
+}
+
+// Float64FromSQLNull constructs and returns a new Float64 initialized with the
+// value and validity of the given sql.Null[float64] n.
+func Float64FromSQLNull(n sql.Null[float64]) Float64 {
+	if !n.Valid {
+		return NullFloat64()
+	}
+	return Float64{sql.NullFloat64{Float64: n.V, Valid: n.Valid}}
+}
+
+// SQLNull returns the value and validity of f as a sql.Null[float64].
+func (f Float64) SQLNull() sql.Null[float64] {
+	return sql.Null[float64]{V: f.Float64, Valid: f.Valid}
+}
+
+// Int64FromSQLNull constructs and returns a new Int64 initialized with the
+// value and validity of the given sql.Null[int64] n.
+func Int64FromSQLNull(n sql.Null[int64]) Int64 {
+	if !n.Valid {
+		return NullInt64()
+	}
+	return Int64{sql.NullInt64{Int64: n.V, Valid: n.Valid}}
+}
+
+// SQLNull returns the value and validity of i as a sql.Null[int64].
+func (i Int64) SQLNull() sql.Null[int64] {
+	return sql.Null[int64]{V: i.Int64, Valid: i.Valid}
+}
+
+// StringFromSQLNull constructs and returns a new String initialized with the
+// value and validity of the given sql.Null[string] n.
+func StringFromSQLNull(n sql.Null[string]) String {
+	if !n.Valid {
+		return NullString()
+	}
+	return String{sql.NullString{String: n.V, Valid: n.Valid}}
+}
+
+// SQLNull returns the value and validity of s as a sql.Null[string].
+func (s String) SQLNull() sql.Null[string] {
+	return sql.Null[string]{V: s.String, Valid: s.Valid}
+}
+
+// TimeFromSQLNull constructs and returns a new Time initialized with the value
+// and validity of the given sql.Null[time.Time] n.
+func TimeFromSQLNull(n sql.Null[time.Time]) Time {
+	if !n.Valid {
+		return NullTime()
+	}
+	return Time{Time: n.V, Valid: n.Valid}
+}
+
+// SQLNull returns the value and validity of t as a sql.Null[time.Time].
+func (t Time) SQLNull() sql.Null[time.Time] {
+	return sql.Null[time.Time]{V: t.Time, Valid: t.Valid}
+}
+
+// Uint8FromSQLNull constructs and returns a new Uint8 initialized with the
+// value and validity of the given sql.Null[uint8] n.
+func Uint8FromSQLNull(n sql.Null[uint8]) Uint8 {
+	if !n.Valid {
+		return NullUint8()
+	}
+	return Uint8{Uint8: n.V, Valid: n.Valid}
+}
+
+// SQLNull returns the value and validity of i as a sql.Null[uint8].
+func (i Uint8) SQLNull() sql.Null[uint8] {
+	return sql.Null[uint8]{V: i.Uint8, Valid: i.Valid}
+}
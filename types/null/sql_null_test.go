@@ -0,0 +1,42 @@
+//go:build go1.22
+
+package null_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/pyrrho/encoding/types/null"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLNull(t *testing.T) {
+	require := require.New(t)
+
+	// Valid values, including zero values, convert in both directions.
+	require.Equal(null.NewInt64(42), null.Int64FromSQLNull(sql.Null[int64]{V: 42, Valid: true}))
+	require.Equal(null.NewInt64(0), null.Int64FromSQLNull(sql.Null[int64]{Valid: true}))
+	require.Equal(sql.Null[int64]{V: 42, Valid: true}, null.NewInt64(42).SQLNull())
+
+	require.Equal(null.NewString(""), null.StringFromSQLNull(sql.Null[string]{Valid: true}))
+	require.Equal(sql.Null[string]{V: "foo", Valid: true}, null.NewString("foo").SQLNull())
+
+	tm := time.Date(2012, 12, 21, 21, 21, 21, 0, time.UTC)
+	require.Equal(null.NewTime(tm), null.TimeFromSQLNull(sql.Null[time.Time]{V: tm, Valid: true}))
+	require.Equal(sql.Null[time.Time]{V: tm, Valid: true}, null.NewTime(tm).SQLNull())
+
+	require.Equal(null.NewBool(true), null.BoolFromSQLNull(sql.Null[bool]{V: true, Valid: true}))
+	require.Equal(null.NewFloat64(1.5), null.Float64FromSQLNull(sql.Null[float64]{V: 1.5, Valid: true}))
+	require.Equal(null.NewUint8(8), null.Uint8FromSQLNull(sql.Null[uint8]{V: 8, Valid: true}))
+
+	// Invalid values are null, regardless of the value they hold.
+	require.Equal(null.NullInt64(), null.Int64FromSQLNull(sql.Null[int64]{}))
+	require.Equal(sql.Null[int64]{}, null.NullInt64().SQLNull())
+	require.Equal(null.NullString(), null.StringFromSQLNull(sql.Null[string]{V: "ignored"}))
+	require.Equal(sql.Null[string]{}, null.NullString().SQLNull())
+	require.Equal(null.NullTime(), null.TimeFromSQLNull(sql.Null[time.Time]{}))
+	require.Equal(null.NullBool(), null.BoolFromSQLNull(sql.Null[bool]{}))
+	require.Equal(null.NullFloat64(), null.Float64FromSQLNull(sql.Null[float64]{}))
+	require.Equal(null.NullUint8(), null.Uint8FromSQLNull(sql.Null[uint8]{}))
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strings"
 )
 
 // String is a wrapper around the database/sql NullString type that implements
@@ -37,6 +38,17 @@ func NewString(s string) String {
 		}}
 }
 
+// StringFromSQL constructs and returns a new String initialized with the value
+// and validity of the given sql.NullString n.
+//
+// If n is not valid, the new String will be null and zeroed, whatever n holds.
+func StringFromSQL(n sql.NullString) String {
+	if !n.Valid {
+		return NullString()
+	}
+	return String{n}
+}
+
 // Getters and Setters
 
 // ValueOrZero returns the value of s if it is valid; otherwise it returns the
@@ -48,17 +60,44 @@ func (s String) ValueOrZero() string {
 	return s.String
 }
 
+// Or returns s if it is valid, or o otherwise. This is useful for layering a
+// value over a default; eg. userSetting.Or(defaultSetting).
+func (s String) Or(o String) String {
+	if s.Valid {
+		return s
+	}
+	return o
+}
+
 // Set modifies the value stored in s, and guarantees it is valid.
 func (s *String) Set(v string) {
 	s.String = v
 	s.Valid = true
 }
 
+// SetPtr sets s to the value pointed to by v, and guarantees it is valid. If v
+// is nil, s will be nulled.
+func (s *String) SetPtr(v *string) {
+	if v == nil {
+		s.Null()
+		return
+	}
+	s.Set(*v)
+}
+
 // Null marks s as null with no meaningful value.
 func (s *String) Null() {
+	s.String = ""
 	s.Valid = false
 }
 
+// SQL returns a pointer to the sql.NullString embedded in s, for use with APIs
+// that only accept the database/sql null types. Modifications made through the
+// returned pointer will be reflected in s.
+func (s *String) SQL() *sql.NullString {
+	return &s.NullString
+}
+
 // Interface
 
 // IsNil implements the pyrrho/encoding IsNiler interface. It will return true
@@ -73,10 +112,32 @@ func (s String) IsZero() bool {
 	return !s.Valid || s.String == ""
 }
 
+// JSONSchemaType implements the JSONSchemaTyper interface. It will return
+// "string", and true.
+func (s String) JSONSchemaType() (string, bool) {
+	return "string", true
+}
+
+// Scan implements the database/sql Scanner interface. It defers to the
+// embedded sql.NullString, and wraps any failure in a *ParseError.
+func (s *String) Scan(src interface{}) error {
+	if s == nil {
+		return fmt.Errorf("null.String: Scan called on nil pointer")
+	}
+	if err := s.NullString.Scan(src); err != nil {
+		return parseError("String", "Scan", src, err)
+	}
+	return nil
+}
+
 // MarshalJSON implements the encoding/json Marshaler interface. It will return
-// the value of s if valid, otherwise 'null'.
+// the value of s if valid, otherwise 'null'. Valid values are encoded by
+// encoding/json, and so are escaped exactly as a plain string would be;
+// including the escaping of '<', '>', and '&' for safe embedding in HTML.
+//
+// Valid zero values will also encode to 'null' if ZeroAsNull is true.
 func (s String) MarshalJSON() ([]byte, error) {
-	if !s.Valid {
+	if !s.Valid || ZeroAsNull && s.IsZero() {
 		return []byte("null"), nil
 	}
 	return json.Marshal(s.String)
@@ -88,7 +149,8 @@ func (s String) MarshalJSON() ([]byte, error) {
 //
 // An empty string will result in a valid-but-empty String. The keyword 'null'
 // will result in a null String. The string '"null"' is considered to be a
-// string -- not a keyword -- and will result in a valid String.
+// string -- not a keyword -- and will result in a valid String, unless it
+// matches one of JSONNullTokens, in which case it will result in a null String.
 //
 // If the decode fails, the value of s will be unchanged.
 func (s *String) UnmarshalJSON(data []byte) error {
@@ -96,8 +158,8 @@ func (s *String) UnmarshalJSON(data []byte) error {
 		return fmt.Errorf("null.String: UnmarshalJSON called on nil pointer")
 	}
 	var j interface{}
-	if err := json.Unmarshal(data, &j); err != nil {
-		return err
+	if err := DecodeJSON(data, &j); err != nil {
+		return parseError("String", "UnmarshalJSON", data, err)
 	}
 	switch val := j.(type) {
 	case string:
@@ -109,8 +171,8 @@ func (s *String) UnmarshalJSON(data []byte) error {
 		s.Valid = false
 		return nil
 	default:
-		return fmt.Errorf("null.String: cannot unmarshal JSON of type %T (%v)",
-			val, data)
+		return parseError("String", "UnmarshalJSON", data,
+			fmt.Errorf("cannot unmarshal JSON of type %T (%v)", val, data))
 	}
 }
 
@@ -123,3 +185,69 @@ func (s String) MarshalMapValue() (interface{}, error) {
 	}
 	return nil, nil
 }
+
+// UnmarshalMapValue implements the pyrrho/encoding/maps Unmarshaler interface.
+// It will decode the string v into s, or decode nil into a null String.
+//
+// If the decode fails, the value of s will be unchanged.
+func (s *String) UnmarshalMapValue(v interface{}) error {
+	if s == nil {
+		return fmt.Errorf("null.String: UnmarshalMapValue called on nil pointer")
+	}
+	switch val := v.(type) {
+	case nil:
+		s.Null()
+	case string:
+		s.Set(val)
+	default:
+		return parseError("String", "UnmarshalMapValue", v,
+			fmt.Errorf("cannot unmarshal map value of type %T", v))
+	}
+	return nil
+}
+
+// MarshalBinary implements the encoding BinaryMarshaler interface. It will
+// encode s into a validity byte followed by the bytes of its value if valid,
+// or a single zero byte otherwise.
+func (s String) MarshalBinary() ([]byte, error) {
+	if !s.Valid {
+		return []byte{0}, nil
+	}
+	ret := make([]byte, 1, len(s.String)+1)
+	ret[0] = 1
+	return append(ret, s.String...), nil
+}
+
+// UnmarshalBinary implements the encoding BinaryUnmarshaler interface. It will
+// decode a given []byte into s, so long as the provided []byte was produced by
+// String.MarshalBinary.
+//
+// If the decode fails, the value of s will be unchanged.
+func (s *String) UnmarshalBinary(data []byte) error {
+	if s == nil {
+		return fmt.Errorf("null.String: UnmarshalBinary called on nil pointer")
+	}
+	switch {
+	case len(data) == 1 && data[0] == 0:
+		s.String = ""
+		s.Valid = false
+		return nil
+	case len(data) >= 1 && data[0] == 1:
+		s.String = string(data[1:])
+		s.Valid = true
+		return nil
+	default:
+		return parseError("String", "UnmarshalBinary", data,
+			fmt.Errorf("cannot unmarshal binary data (%v)", data))
+	}
+}
+
+// Compare returns -1 if s is lexically ordered before o, 1 if s is ordered
+// after o, and 0 if they're equal. Null values are ordered according to
+// NullsFirst.
+func (s String) Compare(o String) int {
+	if c, ok := compareValidity(s.Valid, o.Valid); ok {
+		return c
+	}
+	return strings.Compare(s.String, o.String)
+}
@@ -1,9 +1,12 @@
 package null_test
 
 import (
+	"database/sql"
 	"database/sql/driver"
 	"encoding/json"
+	"errors"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/pyrrho/encoding/maps"
 	"github.com/pyrrho/encoding/types/null"
@@ -206,6 +209,49 @@ func TestStringMarshalJSONInStruct(t *testing.T) {
 	require.EqualValues(`{"null_string":null,"string":"test"}`, sj)
 }
 
+func TestStringMarshalJSONEscaping(t *testing.T) {
+	require := require.New(t)
+
+	payloads := []string{
+		`say "hello"`,
+		`C:\path\to\file`,
+		"line one\nline two\r\n\ttabbed",
+		"\x00\x01\x1f\x7f",
+		"</script><script>alert('xss')</script>",
+		"a & b <c> d",
+		"caf\u00e9 \u65e5\u672c \U0001f600",
+		"\u2028\u2029",
+		"\xff\xfe invalid UTF-8",
+	}
+	for _, p := range payloads {
+		expected, err := json.Marshal(p)
+		require.NoError(err)
+
+		// Escaping matches encoding/json's, including its default HTML
+		// escaping, both for the bare value ...
+		data, err := null.NewString(p).MarshalJSON()
+		require.NoError(err)
+		require.Equal(string(expected), string(data), p)
+		require.True(json.Valid(data), p)
+
+		// ... and when embedded in a larger document.
+		data, err = json.Marshal(map[string]null.String{"s": null.NewString(p)})
+		require.NoError(err)
+		require.Equal(`{"s":`+string(expected)+`}`, string(data), p)
+
+		// Valid UTF-8 round-trips exactly.
+		var rt null.String
+		require.NoError(json.Unmarshal(data[5:len(data)-1], &rt))
+		if utf8.ValidString(p) {
+			require.Equal(p, rt.String, p)
+		}
+	}
+
+	data, err := null.NewString("</script>").MarshalJSON()
+	require.NoError(err)
+	require.Equal(`"\u003c/script\u003e"`, string(data))
+}
+
 func TestStringUnmarshalJSON(t *testing.T) {
 	require := require.New(t)
 	var err error
@@ -248,7 +294,8 @@ func TestStringUnmarshalJSON(t *testing.T) {
 
 	var invalid null.String
 	err = invalid.UnmarshalJSON([]byte(":->"))
-	if _, ok := err.(*json.SyntaxError); !ok {
+	var syntaxErr *json.SyntaxError
+	if !errors.As(err, &syntaxErr) {
 		require.FailNowf(
 			"Unexpected Error Type",
 			"expected *json.SyntaxError, not %T", err)
@@ -287,3 +334,82 @@ func TestStringMarshalMapValue(t *testing.T) {
 	require.NoError(err)
 	require.Equal(map[string]interface{}{"Slice": nil}, data)
 }
+
+func TestStringMarshalBinary(t *testing.T) {
+	require := require.New(t)
+	var data []byte
+	var err error
+
+	s := null.NewString("Hello World")
+	data, err = s.MarshalBinary()
+	require.NoError(err)
+	require.Equal(append([]byte{0x01}, "Hello World"...), data)
+	var rs null.String
+	err = rs.UnmarshalBinary(data)
+	require.NoError(err)
+	require.Equal(s, rs)
+
+	empty := null.NewString("")
+	data, err = empty.MarshalBinary()
+	require.NoError(err)
+	require.Equal([]byte{0x01}, data)
+	var rempty null.String
+	err = rempty.UnmarshalBinary(data)
+	require.NoError(err)
+	require.Equal(empty, rempty)
+
+	nul := null.String{}
+	data, err = nul.MarshalBinary()
+	require.NoError(err)
+	require.Equal([]byte{0x00}, data)
+	rnul := null.NewString("Hello World")
+	err = rnul.UnmarshalBinary(data)
+	require.NoError(err)
+	require.Equal(nul, rnul)
+
+	var bad null.String
+	err = bad.UnmarshalBinary(nil)
+	require.Error(err)
+	err = bad.UnmarshalBinary([]byte{0x02, 'a'})
+	require.Error(err)
+}
+
+func TestStringCompare(t *testing.T) {
+	require := require.New(t)
+
+	require.Equal(-1, null.NewString("a").Compare(null.NewString("b")))
+	require.Equal(1, null.NewString("b").Compare(null.NewString("a")))
+	require.Equal(0, null.NewString("a").Compare(null.NewString("a")))
+	require.Equal(0, null.NullString().Compare(null.NullString()))
+	require.Equal(1, null.NullString().Compare(null.NewString("")))
+	require.Equal(-1, null.NewString("").Compare(null.NullString()))
+}
+
+func TestStringSQL(t *testing.T) {
+	require := require.New(t)
+
+	n := sql.NullString{String: "foo", Valid: true}
+	v := null.StringFromSQL(n)
+	require.True(v.Valid)
+	require.Equal(n, *v.SQL())
+	require.Equal(null.StringFromSQL(sql.NullString{}), null.NullString())
+
+	// SQL returns a pointer to the embedded value, so it can be scanned into
+	// directly.
+	err := v.SQL().Scan(nil)
+	require.NoError(err)
+	require.False(v.Valid)
+}
+
+func TestStringSetPtr(t *testing.T) {
+	require := require.New(t)
+
+	v := "foo"
+	var n null.String
+	n.SetPtr(&v)
+	require.Equal(null.NewString("foo"), n)
+
+	n.SetPtr(nil)
+	require.False(n.Valid)
+	require.True(n.IsNil())
+}
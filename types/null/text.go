@@ -0,0 +1,24 @@
+package null
+
+import (
+	"bytes"
+	"strings"
+)
+
+// NullTextTokens is the set of inputs the UnmarshalText methods of this
+// package's types will decode into a null value. Both the input and the tokens
+// have surrounding whitespace trimmed, and are compared case-insensitively, so
+// the default set matches "", "null", " NULL ", "Null", and so on.
+var NullTextTokens = []string{"", "null"}
+
+// isNullText returns true if text, trimmed of surrounding whitespace, matches
+// one of NullTextTokens.
+func isNullText(text []byte) bool {
+	t := string(bytes.TrimSpace(text))
+	for _, tok := range NullTextTokens {
+		if strings.EqualFold(t, strings.TrimSpace(tok)) {
+			return true
+		}
+	}
+	return false
+}
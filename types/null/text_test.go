@@ -0,0 +1,95 @@
+package null_test
+
+import (
+	"encoding"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pyrrho/encoding/types/null"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshalText(t *testing.T) {
+	require := require.New(t)
+
+	cases := []struct {
+		name  string
+		dst   func() encoding.TextUnmarshaler
+		text  string
+		valid interface{}
+		null  interface{}
+		bad   []string
+	}{
+		{"Bool", func() encoding.TextUnmarshaler { v := null.NewBool(true); return &v },
+			" false\t", null.NewBool(false), null.NullBool(), []string{"yes", "nil"}},
+		{"Float64", func() encoding.TextUnmarshaler { v := null.NewFloat64(1); return &v },
+			"\n-1.5 ", null.NewFloat64(-1.5), null.NullFloat64(), []string{"1,5", "one"}},
+		{"Int64", func() encoding.TextUnmarshaler { v := null.NewInt64(1); return &v },
+			" 42 ", null.NewInt64(42), null.NullInt64(), []string{"1.5", "4 2"}},
+		{"Time", func() encoding.TextUnmarshaler { v := null.NewTime(time.Unix(0, 0)); return &v },
+			" 2012-12-21T21:21:21Z ", null.NewTime(time.Date(2012, 12, 21, 21, 21, 21, 0, time.UTC)), null.NullTime(), []string{"yesterday"}},
+		{"Uint8", func() encoding.TextUnmarshaler { v := null.NewUint8(1); return &v },
+			"255", null.NewUint8(255), null.NullUint8(), []string{"256", "-1"}},
+	}
+	deref := func(v encoding.TextUnmarshaler) interface{} {
+		switch x := v.(type) {
+		case *null.Bool:
+			return *x
+		case *null.Float64:
+			return *x
+		case *null.Int64:
+			return *x
+		case *null.Time:
+			return *x
+		case *null.Uint8:
+			return *x
+		}
+		panic("unexpected type")
+	}
+
+	for _, c := range cases {
+		// Valid text is trimmed, and then parsed strictly.
+		dst := c.dst()
+		require.NoError(dst.UnmarshalText([]byte(c.text)), c.name)
+		if vt, ok := c.valid.(null.Time); ok {
+			require.True(vt.Time.Equal(deref(dst).(null.Time).Time), c.name)
+		} else {
+			require.Equal(c.valid, deref(dst), c.name)
+		}
+
+		// Null tokens are trimmed, and case-insensitive.
+		for _, tok := range []string{"", "  ", "null", "NULL", " Null\n"} {
+			dst = c.dst()
+			require.NoError(dst.UnmarshalText([]byte(tok)), "%s: %q", c.name, tok)
+			require.Equal(c.null, deref(dst), "%s: %q", c.name, tok)
+		}
+
+		// Failures leave the destination unchanged.
+		for _, bad := range c.bad {
+			dst = c.dst()
+			err := dst.UnmarshalText([]byte(bad))
+			var pe *null.ParseError
+			require.True(errors.As(err, &pe), "%s: %q", c.name, bad)
+			require.Equal("UnmarshalText", pe.Func)
+			require.Equal(deref(c.dst()), deref(dst), "%s: %q", c.name, bad)
+		}
+	}
+}
+
+func TestNullTextTokens(t *testing.T) {
+	require := require.New(t)
+
+	defer func(tokens []string) { null.NullTextTokens = tokens }(null.NullTextTokens)
+	null.NullTextTokens = []string{"N/A", `\N`}
+
+	var i null.Int64
+	require.NoError(i.UnmarshalText([]byte(" n/a ")))
+	require.False(i.Valid)
+	require.NoError(i.UnmarshalText([]byte(`\N`)))
+	require.False(i.Valid)
+
+	// Tokens not in the set are no longer null.
+	require.Error(i.UnmarshalText([]byte("null")))
+	require.Error(i.UnmarshalText([]byte("")))
+}
@@ -1,13 +1,58 @@
 package null
 
 import (
+	"bytes"
+	"database/sql"
 	"database/sql/driver"
-	"encoding/json"
 	"fmt"
 	"github.com/relvacode/iso8601"
+	"strconv"
 	"time"
 )
 
+// UnixMode describes whether, and at what resolution, Time treats integers as
+// Unix epoch timestamps.
+type UnixMode int
+
+const (
+	// UnixOff disables epoch handling; Times are stored as time.Time values,
+	// and encoded to JSON as RFC 3339 strings.
+	UnixOff UnixMode = iota
+	// UnixSeconds treats integers as whole seconds since the Unix epoch.
+	UnixSeconds
+	// UnixMillis treats integers as whole milliseconds since the Unix epoch.
+	UnixMillis
+)
+
+// TimeUnixMode is the UnixMode used by Time. It defaults to UnixOff. When set
+// to UnixSeconds or UnixMillis, Time.Scan will accept int64s -- as stored by
+// legacy BIGINT timestamp columns -- and Time.Value will return them, while
+// MarshalJSON will emit numbers, and UnmarshalJSON accept them. Scan still
+// accepts time.Time values, and UnmarshalJSON strings, so that epoch and
+// timestamp columns can be mapped with the same type.
+//
+// Epoch timestamps decode into UTC Times, and encoding truncates any
+// resolution finer than TimeUnixMode's. The text, binary, and map
+// representations of Time are unaffected.
+var TimeUnixMode = UnixOff
+
+// fromUnix converts the epoch timestamp n, at TimeUnixMode's resolution, into a
+// UTC time.Time.
+func fromUnix(n int64) time.Time {
+	if TimeUnixMode == UnixMillis {
+		return time.UnixMilli(n).UTC()
+	}
+	return time.Unix(n, 0).UTC()
+}
+
+// toUnix converts v into an epoch timestamp at TimeUnixMode's resolution.
+func toUnix(v time.Time) int64 {
+	if TimeUnixMode == UnixMillis {
+		return v.UnixMilli()
+	}
+	return v.Unix()
+}
+
 // Time is a nullable wrapper around the time.Time type implementing all of the
 // pyrrho/encoding/types interfaces detailed in the package comments.
 //
@@ -54,6 +99,35 @@ func NewTimeStr(s string) (Time, error) {
 	}, nil
 }
 
+// TimeFromString constructs and returns a new Time by parsing the string
+// pointed to by s as an ISO 8601 timestamp. If s is nil or points to an empty
+// string, the new Time will be null. If the string cannot be parsed, a
+// *ParseError will be returned.
+func TimeFromString(s *string) (Time, error) {
+	if s == nil || len(*s) == 0 {
+		return NullTime(), nil
+	}
+	v, err := iso8601.Parse([]byte(*s))
+	if err != nil {
+		return Time{}, parseError("Time", "TimeFromString", *s, err)
+	}
+	return NewTime(v), nil
+}
+
+// TimeFromSQL constructs and returns a new Time initialized with the value and
+// validity of the given sql.NullTime n.
+//
+// If n is not valid, the new Time will be null and zeroed, whatever n holds.
+func TimeFromSQL(n sql.NullTime) Time {
+	if !n.Valid {
+		return NullTime()
+	}
+	return Time{
+		Time:  n.Time,
+		Valid: n.Valid,
+	}
+}
+
 // Getters and Setters
 
 // ValueOrZero returns the value of t if it is valid; otherwise it returns the
@@ -65,18 +139,47 @@ func (t Time) ValueOrZero() time.Time {
 	return t.Time
 }
 
+// Or returns t if it is valid, or o otherwise. This is useful for layering a
+// value over a default; eg. userSetting.Or(defaultSetting).
+func (t Time) Or(o Time) Time {
+	if t.Valid {
+		return t
+	}
+	return o
+}
+
 // Set modifies the value stored in t, and guarantees it is valid.
 func (t *Time) Set(v time.Time) {
 	t.Time = v
 	t.Valid = true
 }
 
+// SetPtr sets t to the value pointed to by v, and guarantees it is valid. If v
+// is nil, t will be nulled.
+func (t *Time) SetPtr(v *time.Time) {
+	if v == nil {
+		t.Null()
+		return
+	}
+	t.Set(*v)
+}
+
 // Null marks t as null with no meaningful value.
 func (t *Time) Null() {
 	t.Time = time.Time{}
 	t.Valid = false
 }
 
+// SQL returns a copy of t converted to a sql.NullTime, for use with APIs that
+// only accept the database/sql null types. Time doesn't embed a sql.NullTime,
+// so modifications made to the returned value will not be reflected in t.
+func (t Time) SQL() sql.NullTime {
+	return sql.NullTime{
+		Time:  t.Time,
+		Valid: t.Valid,
+	}
+}
+
 // Interfaces
 
 // IsNil implements the pyrrho/encoding IsNiler interface. It will return true
@@ -91,19 +194,34 @@ func (t Time) IsZero() bool {
 	return !t.Valid || t.Time == time.Time{}
 }
 
+// JSONSchemaType implements the JSONSchemaTyper interface. It will return
+// "string", and true, as MarshalJSON produces RFC 3339 timestamps -- or
+// "integer" if TimeUnixMode is set.
+func (t Time) JSONSchemaType() (string, bool) {
+	if TimeUnixMode != UnixOff {
+		return "integer", true
+	}
+	return "string", true
+}
+
 // Value implements the database/sql/driver Valuer interface. As time.Time and
 // nil are both valid types to be stored in a driver.Value, it will return this
-// NullTime's value if valid, or nil otherwise.
+// NullTime's value if valid, or nil otherwise. If TimeUnixMode is set, valid
+// values will be returned as an int64 epoch timestamp instead.
 func (t Time) Value() (driver.Value, error) {
 	if !t.Valid {
 		return nil, nil
 	}
+	if TimeUnixMode != UnixOff {
+		return toUnix(t.Time), nil
+	}
 	return t.Time, nil
 }
 
 // Scan implements the database/sql Scanner interface. It will receive a value
 // from an SQL database and assign it to t, so long as the provided data is of
-// type nil or time.Time. All other types will result in an error.
+// type nil or time.Time, or -- if TimeUnixMode is set -- an int64 epoch
+// timestamp. All other types will result in an error.
 func (t *Time) Scan(src interface{}) error {
 	if t == nil {
 		return fmt.Errorf("null.Time: Scan called on nil pointer")
@@ -113,38 +231,61 @@ func (t *Time) Scan(src interface{}) error {
 		t.Time = val
 		t.Valid = true
 		return nil
+	case int64:
+		if TimeUnixMode == UnixOff {
+			break
+		}
+		t.Time = fromUnix(val)
+		t.Valid = true
+		return nil
 	case nil:
 		t.Time = time.Time{}
 		t.Valid = false
 		return nil
-	default:
-		return fmt.Errorf("null.Time: cannot scan type %T (%v)", val, src)
 	}
+	return parseError("Time", "Scan", src,
+		fmt.Errorf("cannot scan type %T (%v)", src, src))
 }
 
 // MarshalJSON implements the encoding/json Marshaler interface. It will encode
 // t into its JSON RFC 3339 string representation if valid, or
 // 'null' otherwise.
+//
+// Valid zero values will also encode to 'null' if ZeroAsNull is true. If
+// TimeUnixMode is set, valid values will be encoded as an epoch timestamp
+// number instead.
 func (t Time) MarshalJSON() ([]byte, error) {
-	if !t.Valid {
+	if !t.Valid || ZeroAsNull && t.IsZero() {
 		return []byte("null"), nil
 	}
+	if TimeUnixMode != UnixOff {
+		return strconv.AppendInt(nil, toUnix(t.Time), 10), nil
+	}
 	return t.Time.MarshalJSON()
 }
 
 // UnmarshalJSON implements the encoding/json Unmarshaler interface. It will
 // decode a given []byte into t so long as the provided []byte
 // is a valid JSON representation of an ISO 8601 string. Empty strings and
-// the 'null' keyword will both decode into a null NullTime.
+// the 'null' keyword will both decode into a null NullTime, as will strings
+// matching one of JSONNullTokens. If TimeUnixMode is set, integer epoch
+// timestamps will also be accepted.
 //
 // If the decode fails, the value of t will be unchanged.
 func (t *Time) UnmarshalJSON(data []byte) error {
 	if t == nil {
 		return fmt.Errorf("null.Time: UnmarshalJSON called on nil pointer")
 	}
+	if TimeUnixMode != UnixOff {
+		if n, ok := parseJSONInt(data); ok {
+			t.Time = fromUnix(n)
+			t.Valid = true
+			return nil
+		}
+	}
 	var j interface{}
-	if err := json.Unmarshal(data, &j); err != nil {
-		return err
+	if err := DecodeJSON(data, &j); err != nil {
+		return parseError("Time", "UnmarshalJSON", data, err)
 	}
 	switch val := j.(type) {
 	case string:
@@ -158,7 +299,7 @@ func (t *Time) UnmarshalJSON(data []byte) error {
 		// on t.Time.
 		tmp, err := iso8601.Parse([]byte(val))
 		if err != nil {
-			return err
+			return parseError("Time", "UnmarshalJSON", data, err)
 		}
 		t.Time = tmp
 		t.Valid = true
@@ -168,11 +309,36 @@ func (t *Time) UnmarshalJSON(data []byte) error {
 		t.Valid = false
 		return nil
 	default:
-		return fmt.Errorf("null.Time: cannot unmarshal JSON of type %T (%v)",
-			val, data)
+		return parseError("Time", "UnmarshalJSON", data,
+			fmt.Errorf("cannot unmarshal JSON of type %T (%v)", val, data))
 	}
 }
 
+// UnmarshalText implements the encoding TextUnmarshaler interface. It will
+// decode a given []byte into t, so long as the provided []byte -- trimmed of
+// surrounding whitespace -- is the text representation of an ISO 8601
+// timestamp, or matches one of NullTextTokens, which will decode into a null
+// Time.
+//
+// If the decode fails, the value of t will be unchanged.
+func (t *Time) UnmarshalText(text []byte) error {
+	if t == nil {
+		return fmt.Errorf("null.Time: UnmarshalText called on nil pointer")
+	}
+	if isNullText(text) {
+		t.Time = time.Time{}
+		t.Valid = false
+		return nil
+	}
+	v, err := iso8601.Parse(bytes.TrimSpace(text))
+	if err != nil {
+		return parseError("Time", "UnmarshalText", text, err)
+	}
+	t.Time = v
+	t.Valid = true
+	return nil
+}
+
 // MarshalMapValue implements the pyrrho/encoding/maps Marshaler interface. It
 // will encode t into an interface{} representation for use in a
 // map[Time]interface{} if valid, or return nil otherwise.
@@ -182,3 +348,88 @@ func (t Time) MarshalMapValue() (interface{}, error) {
 	}
 	return nil, nil
 }
+
+// UnmarshalMapValue implements the pyrrho/encoding/maps Unmarshaler interface.
+// It will decode the time.Time v, or an ISO 8601 formatted string, into t, or
+// decode nil into a null Time.
+//
+// If the decode fails, the value of t will be unchanged.
+func (t *Time) UnmarshalMapValue(v interface{}) error {
+	if t == nil {
+		return fmt.Errorf("null.Time: UnmarshalMapValue called on nil pointer")
+	}
+	switch val := v.(type) {
+	case nil:
+		t.Null()
+	case time.Time:
+		t.Set(val)
+	case string:
+		tmp, err := iso8601.Parse([]byte(val))
+		if err != nil {
+			return parseError("Time", "UnmarshalMapValue", v, err)
+		}
+		t.Set(tmp)
+	default:
+		return parseError("Time", "UnmarshalMapValue", v,
+			fmt.Errorf("cannot unmarshal map value of type %T", v))
+	}
+	return nil
+}
+
+// MarshalBinary implements the encoding BinaryMarshaler interface. It will
+// encode t into a validity byte followed by the time.Time binary encoding of
+// its value if valid, or a single zero byte otherwise.
+func (t Time) MarshalBinary() ([]byte, error) {
+	if !t.Valid {
+		return []byte{0}, nil
+	}
+	b, err := t.Time.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{1}, b...), nil
+}
+
+// UnmarshalBinary implements the encoding BinaryUnmarshaler interface. It will
+// decode a given []byte into t, so long as the provided []byte was produced by
+// Time.MarshalBinary.
+//
+// If the decode fails, the value of t will be unchanged.
+func (t *Time) UnmarshalBinary(data []byte) error {
+	if t == nil {
+		return fmt.Errorf("null.Time: UnmarshalBinary called on nil pointer")
+	}
+	switch {
+	case len(data) == 1 && data[0] == 0:
+		t.Time = time.Time{}
+		t.Valid = false
+		return nil
+	case len(data) > 1 && data[0] == 1:
+		var tmp time.Time
+		if err := tmp.UnmarshalBinary(data[1:]); err != nil {
+			return parseError("Time", "UnmarshalBinary", data, err)
+		}
+		t.Time = tmp
+		t.Valid = true
+		return nil
+	default:
+		return parseError("Time", "UnmarshalBinary", data,
+			fmt.Errorf("cannot unmarshal binary data (%v)", data))
+	}
+}
+
+// Compare returns -1 if t is an instant before o, 1 if t is an instant after o,
+// and 0 if they're the same instant. Null values are ordered according to
+// NullsFirst.
+func (t Time) Compare(o Time) int {
+	if c, ok := compareValidity(t.Valid, o.Valid); ok {
+		return c
+	}
+	switch {
+	case t.Time.Before(o.Time):
+		return -1
+	case t.Time.After(o.Time):
+		return 1
+	}
+	return 0
+}
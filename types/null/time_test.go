@@ -1,8 +1,10 @@
 package null_test
 
 import (
+	"database/sql"
 	"database/sql/driver"
 	"encoding/json"
+	"errors"
 	"testing"
 	"time"
 
@@ -246,13 +248,65 @@ func TestTimeUnmarshalJSON(t *testing.T) {
 
 	var invalid null.Time
 	err = invalid.UnmarshalJSON([]byte(":->"))
-	if _, ok := err.(*json.SyntaxError); !ok {
+	var syntaxErr *json.SyntaxError
+	if !errors.As(err, &syntaxErr) {
 		require.FailNowf(
 			"Unexpected Error Type",
 			"expected *json.SyntaxError, not %T", err)
 	}
 }
 
+func TestTimeUnixMode(t *testing.T) {
+	require := require.New(t)
+	defer func(v null.UnixMode) { null.TimeUnixMode = v }(null.TimeUnixMode)
+
+	epoch := timeValue.Unix()
+	millis := timeValue.Add(250 * time.Millisecond)
+
+	// Off by default; integers are an error.
+	var ti null.Time
+	require.Error(ti.Scan(epoch))
+	require.Error(json.Unmarshal([]byte("1356124881"), &ti))
+
+	null.TimeUnixMode = null.UnixSeconds
+	require.NoError(ti.Scan(epoch))
+	require.Equal(null.NewTime(timeValue), ti)
+	v, err := null.NewTime(millis).Value()
+	require.NoError(err)
+	require.Equal(epoch, v)
+	data, err := json.Marshal(null.NewTime(millis))
+	require.NoError(err)
+	require.Equal("1356124881", string(data))
+	require.NoError(json.Unmarshal([]byte(" 1356124881 "), &ti))
+	require.Equal(null.NewTime(timeValue), ti)
+
+	// Timestamps and strings are still accepted, and nulls are still nulls.
+	require.NoError(ti.Scan(millis))
+	require.Equal(null.NewTime(millis), ti)
+	require.NoError(json.Unmarshal(timeJSON, &ti))
+	require.Equal(null.NewTime(timeValue), ti)
+	v, err = null.NullTime().Value()
+	require.NoError(err)
+	require.Nil(v)
+	data, err = json.Marshal(null.NullTime())
+	require.NoError(err)
+	require.Equal("null", string(data))
+	require.Error(json.Unmarshal([]byte("1.5"), &ti))
+
+	null.TimeUnixMode = null.UnixMillis
+	require.NoError(ti.Scan(epoch*1000 + 250))
+	require.Equal(null.NewTime(millis), ti)
+	v, err = ti.Value()
+	require.NoError(err)
+	require.Equal(epoch*1000+250, v)
+	data, err = json.Marshal(ti)
+	require.NoError(err)
+	require.Equal("1356124881250", string(data))
+	ti = null.NullTime()
+	require.NoError(json.Unmarshal(data, &ti))
+	require.Equal(null.NewTime(millis), ti)
+}
+
 func TestTimeMarshalMapValue(t *testing.T) {
 	require := require.New(t)
 	type Wrapper struct{ Time null.Time }
@@ -285,3 +339,96 @@ func TestTimeMarshalMapValue(t *testing.T) {
 	require.NoError(err)
 	require.Equal(map[string]interface{}{"Time": nil}, data)
 }
+
+func TestTimeMarshalBinary(t *testing.T) {
+	require := require.New(t)
+	var data []byte
+	var err error
+
+	ti := null.NewTime(timeValue)
+	data, err = ti.MarshalBinary()
+	require.NoError(err)
+	require.Equal(byte(0x01), data[0])
+	var rti null.Time
+	err = rti.UnmarshalBinary(data)
+	require.NoError(err)
+	require.True(rti.Valid)
+	require.True(timeValue.Equal(rti.Time))
+
+	nul := null.Time{}
+	data, err = nul.MarshalBinary()
+	require.NoError(err)
+	require.Equal([]byte{0x00}, data)
+	rnul := null.NewTime(timeValue)
+	err = rnul.UnmarshalBinary(data)
+	require.NoError(err)
+	require.Equal(nul, rnul)
+
+	var bad null.Time
+	err = bad.UnmarshalBinary([]byte{0x01})
+	require.Error(err)
+	err = bad.UnmarshalBinary([]byte{0x01, 0x02, 0x03})
+	require.Error(err)
+}
+
+func TestTimeCompare(t *testing.T) {
+	require := require.New(t)
+
+	later := timeValue.Add(time.Second)
+	require.Equal(-1, null.NewTime(timeValue).Compare(null.NewTime(later)))
+	require.Equal(1, null.NewTime(later).Compare(null.NewTime(timeValue)))
+	require.Equal(0, null.NewTime(timeValue).Compare(null.NewTime(timeValue)))
+	// Instants are compared, not locations.
+	require.Equal(0, null.NewTime(timeValue).Compare(null.NewTime(timeValue.In(time.FixedZone("", 3600)))))
+	require.Equal(0, null.NullTime().Compare(null.NullTime()))
+	require.Equal(1, null.NullTime().Compare(null.NewTime(time.Time{})))
+	require.Equal(-1, null.NewTime(time.Time{}).Compare(null.NullTime()))
+}
+
+func TestTimeSQL(t *testing.T) {
+	require := require.New(t)
+
+	n := sql.NullTime{Time: time.Date(2012, 12, 21, 21, 21, 21, 0, time.UTC), Valid: true}
+	v := null.TimeFromSQL(n)
+	require.Equal(null.NewTime(time.Date(2012, 12, 21, 21, 21, 21, 0, time.UTC)), v)
+	require.Equal(n, v.SQL())
+	require.Equal(null.TimeFromSQL(sql.NullTime{}), null.NullTime())
+	require.Equal(sql.NullTime{}, null.NullTime().SQL())
+}
+
+func TestTimeSetPtr(t *testing.T) {
+	require := require.New(t)
+
+	v := time.Date(2012, 12, 21, 21, 21, 21, 0, time.UTC)
+	var n null.Time
+	n.SetPtr(&v)
+	require.Equal(null.NewTime(time.Date(2012, 12, 21, 21, 21, 21, 0, time.UTC)), n)
+
+	n.SetPtr(nil)
+	require.False(n.Valid)
+	require.Equal(null.NullTime(), n)
+}
+
+func TestTimeFromString(t *testing.T) {
+	require := require.New(t)
+	str := func(s string) *string { return &s }
+
+	tm, err := null.TimeFromString(str("2012-12-21T21:21:21Z"))
+	require.NoError(err)
+	require.True(tm.Valid)
+	require.True(time.Date(2012, 12, 21, 21, 21, 21, 0, time.UTC).Equal(tm.Time))
+
+	// Nil pointers and empty strings are null.
+	tm, err = null.TimeFromString(nil)
+	require.NoError(err)
+	require.Equal(null.NullTime(), tm)
+	tm, err = null.TimeFromString(str(""))
+	require.NoError(err)
+	require.Equal(null.NullTime(), tm)
+
+	for _, bad := range []string{"yesterday", "2012-13-45"} {
+		_, err = null.TimeFromString(str(bad))
+		var pe *null.ParseError
+		require.True(errors.As(err, &pe), bad)
+	}
+}
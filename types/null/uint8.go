@@ -1,6 +1,8 @@
 package null
 
 import (
+	"bytes"
+	"database/sql"
 	"database/sql/driver"
 	"encoding/json"
 	"fmt"
@@ -38,6 +40,20 @@ func NewUint8(i uint8) Uint8 {
 	}
 }
 
+// Uint8FromSQL constructs and returns a new Uint8 initialized with the value
+// and validity of the given sql.NullByte n.
+//
+// If n is not valid, the new Uint8 will be null and zeroed, whatever n holds.
+func Uint8FromSQL(n sql.NullByte) Uint8 {
+	if !n.Valid {
+		return NullUint8()
+	}
+	return Uint8{
+		Uint8: n.Byte,
+		Valid: n.Valid,
+	}
+}
+
 // Getters and Setters
 
 // ValueOrZero returns the value of i if it is valid; otherwise it returns the
@@ -49,18 +65,47 @@ func (i Uint8) ValueOrZero() uint8 {
 	return i.Uint8
 }
 
+// Or returns i if it is valid, or o otherwise. This is useful for layering a
+// value over a default; eg. userSetting.Or(defaultSetting).
+func (i Uint8) Or(o Uint8) Uint8 {
+	if i.Valid {
+		return i
+	}
+	return o
+}
+
 // Set modifies the value stored in i, and guarantees it is valid.
 func (i *Uint8) Set(v uint8) {
 	i.Uint8 = v
 	i.Valid = true
 }
 
+// SetPtr sets i to the value pointed to by v, and guarantees it is valid. If v
+// is nil, i will be nulled.
+func (i *Uint8) SetPtr(v *uint8) {
+	if v == nil {
+		i.Null()
+		return
+	}
+	i.Set(*v)
+}
+
 // Null marks i as null with no meaningful value.
 func (i *Uint8) Null() {
 	i.Uint8 = 0
 	i.Valid = false
 }
 
+// SQL returns a copy of i converted to a sql.NullByte, for use with APIs that
+// only accept the database/sql null types. Uint8 doesn't embed a sql.NullByte,
+// so modifications made to the returned value will not be reflected in i.
+func (i Uint8) SQL() sql.NullByte {
+	return sql.NullByte{
+		Byte:  i.Uint8,
+		Valid: i.Valid,
+	}
+}
+
 // Interfaces
 
 // IsNil implements the pyrrho/encoding IsNiler interface. It will return true
@@ -75,6 +120,12 @@ func (i Uint8) IsZero() bool {
 	return !i.Valid || i.Uint8 == 0
 }
 
+// JSONSchemaType implements the JSONSchemaTyper interface. It will return
+// "integer", and true. Valid values lie in the range [0, 255].
+func (i Uint8) JSONSchemaType() (string, bool) {
+	return "integer", true
+}
+
 // Value implements the database/sql/driver Valuer interface. Nil is a valid
 // type to be stored in a driver.Value, but uint8 isn't, so if this Uint8 is
 // valid it will cast its uint8 to an int64.
@@ -87,8 +138,11 @@ func (i Uint8) Value() (driver.Value, error) {
 
 // Scan implements the database/sql Scanner interface. It will receive a value
 // from an SQL database and assign it to i, so long as the provided data is of
-// type nil, uint8, string, or another integer or float type that doesn't
-// overflow uint8. All other types will result in an error.
+// type nil, uint8, string, []byte, or another integer or float type holding a
+// whole number in the range of a uint8. Wider types -- eg. the int64s most
+// drivers return -- are range checked, and values that don't fit will result
+// in an error rather than being truncated. All other types will result in an
+// error.
 func (i *Uint8) Scan(src interface{}) error {
 	if i == nil {
 		return fmt.Errorf("null.Uint8: Scan called on nil pointer")
@@ -105,64 +159,66 @@ func (i *Uint8) Scan(src interface{}) error {
 		i.Valid = true
 		return nil
 	case uint, uint16, uint32, uint64:
-		v := reflect.ValueOf(src)
-		vi := v.Uint()
+		vi := reflect.ValueOf(src).Uint()
 		if vi > math.MaxUint8 {
-			return fmt.Errorf("null.Uint8: failed to scan type %T (%v): overflow", src, src)
+			return parseError("Uint8", "Scan", src, fmt.Errorf("value %d overflows uint8", vi))
 		}
 		i.Uint8 = uint8(vi)
 		i.Valid = true
 		return nil
 	case int, int8, int16, int32, int64:
-		v := reflect.ValueOf(src)
-		vi := v.Int()
+		// Drivers commonly widen integer columns to int64, so range check
+		// rather than rejecting wider types outright.
+		vi := reflect.ValueOf(src).Int()
 		if vi > math.MaxUint8 {
-			return fmt.Errorf("null.Uint8: failed to scan type %T (%v): overflow", src, src)
+			return parseError("Uint8", "Scan", src, fmt.Errorf("value %d overflows uint8", vi))
 		} else if vi < 0 {
-			return fmt.Errorf("null.Uint8: failed to scan type %T (%v): negative value", src, src)
+			return parseError("Uint8", "Scan", src, fmt.Errorf("value %d is negative", vi))
 		}
 		i.Uint8 = uint8(vi)
 		i.Valid = true
 		return nil
 	case string:
-		parsedUint, err := strconv.ParseUint(val, 10, 0)
-		if err != nil {
-			return fmt.Errorf("null.Uint8: failed to scan type %T (%v): %v", src, src, err)
-		}
-		i.Uint8 = uint8(parsedUint)
-		i.Valid = true
-		return nil
+		return i.scanString(src, val)
+	case []byte:
+		return i.scanString(src, string(val))
 	case float64:
 		// Use a string intermediate so we can generate an error on any loss of
 		// precision.
-		s := strconv.FormatFloat(val, 'g', -1, 64)
-		parsedUint, err := strconv.ParseInt(s, 10, 8)
-		if err != nil {
-			return fmt.Errorf("null.Uint8: failed to convert driver.Value type %T (%v): %v", src, s, err)
-		}
-		i.Uint8 = uint8(parsedUint)
-		i.Valid = true
-		return nil
+		return i.scanString(src, strconv.FormatFloat(val, 'f', -1, 64))
 	case float32:
 		// Use a string intermediate so we can generate an error on any loss of
 		// precision.
-		s := strconv.FormatFloat(float64(val), 'g', -1, 32)
-		parsedUint, err := strconv.ParseInt(s, 10, 8)
-		if err != nil {
-			return fmt.Errorf("null.Uint8: failed to convert driver.Value type %T (%v): %v", src, s, err)
-		}
-		i.Uint8 = uint8(parsedUint)
-		i.Valid = true
-		return nil
+		return i.scanString(src, strconv.FormatFloat(float64(val), 'f', -1, 32))
 	default:
-		return fmt.Errorf("null.Uint8: cannot scan type %T (%v)", src, src)
+		return parseError("Uint8", "Scan", src,
+			fmt.Errorf("cannot scan type %T (%v)", src, src))
+	}
+}
+
+// scanString parses str, the textual form of the scanned src, as a base 10
+// uint8 and assigns it to i. A *ParseError will be returned if str is not an
+// integer in the range [0, 255].
+func (i *Uint8) scanString(src interface{}, str string) error {
+	v, err := strconv.ParseUint(str, 10, 8)
+	if err != nil {
+		if ne, ok := err.(*strconv.NumError); ok && ne.Err == strconv.ErrRange {
+			return parseError("Uint8", "Scan", src, fmt.Errorf("value %s overflows uint8", str))
+		}
+		return parseError("Uint8", "Scan", src,
+			fmt.Errorf("failed to scan type %T (%v): %v", src, src, err))
 	}
+	i.Uint8 = uint8(v)
+	i.Valid = true
+	return nil
 }
 
 // MarshalJSON implements the encoding/json Marshaler interface. It will encode
 // i into its JSON representation if valid, or 'null' otherwise.
+//
+// Valid zero values will also encode to 'null' if ZeroAsNull is true.
 func (i Uint8) MarshalJSON() ([]byte, error) {
-	if !i.Valid {
+	if !i.Valid || ZeroAsNull && i.IsZero() {
 		return []byte("null"), nil
 	}
 	return []byte(strconv.FormatUint(uint64(i.Uint8), 10)), nil
@@ -170,7 +226,8 @@ func (i Uint8) MarshalJSON() ([]byte, error) {
 
 // UnmarshalJSON implements the encoding/json Unmarshaler interface. It will
 // decode a given []byte into i, so long as the provided []byte is a valid JSON
-// representation of an int. The 'null' keyword will decode into a null Uint8.
+// representation of an int. The 'null' keyword will decode into a null Uint8,
+// as will strings matching one of JSONNullTokens.
 //
 // If the decode fails, the value of i will be unchanged.
 func (i *Uint8) UnmarshalJSON(data []byte) error {
@@ -178,8 +235,8 @@ func (i *Uint8) UnmarshalJSON(data []byte) error {
 		return fmt.Errorf("null.Uint8: UnmarshalJSON called on nil pointer")
 	}
 	var j interface{}
-	if err := json.Unmarshal(data, &j); err != nil {
-		return err
+	if err := DecodeJSON(data, &j); err != nil {
+		return parseError("Uint8", "UnmarshalJSON", data, err)
 	}
 	switch val := j.(type) {
 	case float64:
@@ -189,7 +246,7 @@ func (i *Uint8) UnmarshalJSON(data []byte) error {
 		var tmp uint8
 		err := json.Unmarshal(data, &tmp)
 		if err != nil {
-			return err
+			return parseError("Uint8", "UnmarshalJSON", data, err)
 		}
 		i.Uint8 = tmp
 		i.Valid = true
@@ -198,12 +255,40 @@ func (i *Uint8) UnmarshalJSON(data []byte) error {
 		i.Uint8 = 0
 		i.Valid = false
 		return nil
+	case string:
+		return parseError("Uint8", "UnmarshalJSON", data,
+			fmt.Errorf("cannot unmarshal JSON string %q into a uint8", val))
 	default:
-		return fmt.Errorf("null.Uint8: cannot unmarshal JSON of type %T (%v)",
-			val, data)
+		return parseError("Uint8", "UnmarshalJSON", data,
+			fmt.Errorf("cannot unmarshal JSON of type %T (%v)", val, data))
 	}
 }
 
+// UnmarshalText implements the encoding TextUnmarshaler interface. It will
+// decode a given []byte into i, so long as the provided []byte -- trimmed of
+// surrounding whitespace -- is the text representation of an unsigned 8-bit
+// integer, or matches one of NullTextTokens, which will decode into a null
+// Uint8.
+//
+// If the decode fails, the value of i will be unchanged.
+func (i *Uint8) UnmarshalText(text []byte) error {
+	if i == nil {
+		return fmt.Errorf("null.Uint8: UnmarshalText called on nil pointer")
+	}
+	if isNullText(text) {
+		i.Uint8 = 0
+		i.Valid = false
+		return nil
+	}
+	v, err := strconv.ParseUint(string(bytes.TrimSpace(text)), 10, 8)
+	if err != nil {
+		return parseError("Uint8", "UnmarshalText", text, err)
+	}
+	i.Uint8 = uint8(v)
+	i.Valid = true
+	return nil
+}
+
 // MarshalMapValue implements the pyrrho/encoding/maps Marshaler interface. It
 // will encode i into its interface{} representation for use in a
 // map[string]interface{} if valid, or return nil otherwise.
@@ -213,3 +298,77 @@ func (i Uint8) MarshalMapValue() (interface{}, error) {
 	}
 	return nil, nil
 }
+
+// UnmarshalMapValue implements the pyrrho/encoding/maps Unmarshaler interface.
+// It will decode the integer v -- or a float holding a whole number -- into i,
+// so long as it's in the range of a uint8, or decode nil into a null Uint8.
+//
+// If the decode fails, the value of i will be unchanged.
+func (i *Uint8) UnmarshalMapValue(v interface{}) error {
+	if i == nil {
+		return fmt.Errorf("null.Uint8: UnmarshalMapValue called on nil pointer")
+	}
+	if v == nil {
+		i.Null()
+		return nil
+	}
+	val, err := mapValueInt64(v)
+	if err != nil {
+		return parseError("Uint8", "UnmarshalMapValue", v, err)
+	}
+	if val < 0 || val > math.MaxUint8 {
+		return parseError("Uint8", "UnmarshalMapValue", v,
+			fmt.Errorf("value %d overflows uint8", val))
+	}
+	i.Set(uint8(val))
+	return nil
+}
+
+// MarshalBinary implements the encoding BinaryMarshaler interface. It will
+// encode i into a validity byte followed by its value if valid, or a single
+// zero byte otherwise.
+func (i Uint8) MarshalBinary() ([]byte, error) {
+	if !i.Valid {
+		return []byte{0}, nil
+	}
+	return []byte{1, i.Uint8}, nil
+}
+
+// UnmarshalBinary implements the encoding BinaryUnmarshaler interface. It will
+// decode a given []byte into i, so long as the provided []byte was produced by
+// Uint8.MarshalBinary.
+//
+// If the decode fails, the value of i will be unchanged.
+func (i *Uint8) UnmarshalBinary(data []byte) error {
+	if i == nil {
+		return fmt.Errorf("null.Uint8: UnmarshalBinary called on nil pointer")
+	}
+	switch {
+	case len(data) == 1 && data[0] == 0:
+		i.Uint8 = 0
+		i.Valid = false
+		return nil
+	case len(data) == 2 && data[0] == 1:
+		i.Uint8 = data[1]
+		i.Valid = true
+		return nil
+	default:
+		return parseError("Uint8", "UnmarshalBinary", data,
+			fmt.Errorf("cannot unmarshal binary data (%v)", data))
+	}
+}
+
+// Compare returns -1 if i is ordered before o, 1 if i is ordered after o, and 0
+// if they're equal. Null values are ordered according to NullsFirst.
+func (i Uint8) Compare(o Uint8) int {
+	if c, ok := compareValidity(i.Valid, o.Valid); ok {
+		return c
+	}
+	switch {
+	case i.Uint8 < o.Uint8:
+		return -1
+	case i.Uint8 > o.Uint8:
+		return 1
+	}
+	return 0
+}
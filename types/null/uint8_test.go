@@ -1,8 +1,10 @@
 package null_test
 
 import (
+	"database/sql"
 	"database/sql/driver"
 	"encoding/json"
+	"errors"
 	"math"
 	"strconv"
 	"testing"
@@ -155,6 +157,31 @@ func TestUint8SQLScan(t *testing.T) {
 	var b null.Uint8
 	err = b.Scan(true)
 	require.Error(err)
+
+	// Wider values are range checked rather than truncated.
+	for _, src := range []interface{}{
+		int64(255), uint64(255), "255", []byte("255"), float64(255), float32(255),
+	} {
+		var w null.Uint8
+		require.NoError(w.Scan(src), "%T", src)
+		require.Equal(null.NewUint8(255), w, "%T", src)
+	}
+	for _, src := range []interface{}{
+		int64(256), uint64(256), "256", []byte("300"), float64(256), float32(1e9),
+	} {
+		w := null.NewUint8(7)
+		err = w.Scan(src)
+		require.Error(err, "%T", src)
+		require.Contains(err.Error(), "overflows uint8", "%T", src)
+		require.Equal(null.NewUint8(7), w, "%T", src)
+	}
+	for _, src := range []interface{}{int64(-1), "-1", float64(-5)} {
+		w := null.NewUint8(7)
+		require.Error(w.Scan(src), "%T", src)
+		require.Equal(null.NewUint8(7), w, "%T", src)
+	}
+	err = i.Scan(int64(300))
+	require.EqualError(err, "null.Uint8: value 300 overflows uint8")
 }
 
 func TestUint8MarshalJSON(t *testing.T) {
@@ -238,7 +265,8 @@ func TestUint8UnmarshalJSON(t *testing.T) {
 
 	var invalid null.Uint8
 	err = invalid.UnmarshalJSON([]byte(":->"))
-	if _, ok := err.(*json.SyntaxError); !ok {
+	var syntaxErr *json.SyntaxError
+	if !errors.As(err, &syntaxErr) {
 		require.FailNowf(
 			"Unexpected Error Type",
 			"expected *json.SyntaxError, not %T", err)
@@ -295,3 +323,68 @@ func TestUint8MarshalMapValue(t *testing.T) {
 	require.NoError(err)
 	require.Equal(map[string]interface{}{"Uint8": nil}, data)
 }
+
+func TestUint8MarshalBinary(t *testing.T) {
+	require := require.New(t)
+	var data []byte
+	var err error
+
+	i := null.NewUint8(123)
+	data, err = i.MarshalBinary()
+	require.NoError(err)
+	require.Equal([]byte{0x01, 123}, data)
+	var ri null.Uint8
+	err = ri.UnmarshalBinary(data)
+	require.NoError(err)
+	require.Equal(i, ri)
+
+	nul := null.Uint8{}
+	data, err = nul.MarshalBinary()
+	require.NoError(err)
+	require.Equal([]byte{0x00}, data)
+	rnul := null.NewUint8(123)
+	err = rnul.UnmarshalBinary(data)
+	require.NoError(err)
+	require.Equal(nul, rnul)
+
+	var bad null.Uint8
+	err = bad.UnmarshalBinary([]byte{0x01})
+	require.Error(err)
+	err = bad.UnmarshalBinary([]byte{0x01, 0x02, 0x03})
+	require.Error(err)
+}
+
+func TestUint8Compare(t *testing.T) {
+	require := require.New(t)
+
+	require.Equal(-1, null.NewUint8(1).Compare(null.NewUint8(2)))
+	require.Equal(1, null.NewUint8(2).Compare(null.NewUint8(1)))
+	require.Equal(0, null.NewUint8(1).Compare(null.NewUint8(1)))
+	require.Equal(0, null.NullUint8().Compare(null.NullUint8()))
+	require.Equal(1, null.NullUint8().Compare(null.NewUint8(0)))
+	require.Equal(-1, null.NewUint8(0).Compare(null.NullUint8()))
+}
+
+func TestUint8SQL(t *testing.T) {
+	require := require.New(t)
+
+	n := sql.NullByte{Byte: uint8(123), Valid: true}
+	v := null.Uint8FromSQL(n)
+	require.Equal(null.NewUint8(uint8(123)), v)
+	require.Equal(n, v.SQL())
+	require.Equal(null.Uint8FromSQL(sql.NullByte{}), null.NullUint8())
+	require.Equal(sql.NullByte{}, null.NullUint8().SQL())
+}
+
+func TestUint8SetPtr(t *testing.T) {
+	require := require.New(t)
+
+	v := uint8(123)
+	var n null.Uint8
+	n.SetPtr(&v)
+	require.Equal(null.NewUint8(123), n)
+
+	n.SetPtr(nil)
+	require.False(n.Valid)
+	require.Equal(null.NullUint8(), n)
+}
@@ -0,0 +1,12 @@
+/*
+Package orbconv converts between the pyrrho/encoding/types SF geometries and
+the geometries of github.com/paulmach/orb, so that values can be decoded from a
+database or JSON document, handed to orb's algorithms, and encoded again
+without converting coordinates by hand.
+
+The converters live in their own package so that importing
+pyrrho/encoding/types doesn't add orb as a dependency. orb geometries are two
+dimensional; altitude (and other) components of SF geometries are dropped when
+converting to orb, and SF geometries converted from orb have an XY layout.
+*/
+package orbconv
@@ -0,0 +1,121 @@
+package orbconv
+
+import (
+	"fmt"
+
+	"github.com/paulmach/orb"
+
+	"github.com/pyrrho/encoding/types"
+)
+
+// PointToOrb returns the longitude and latitude of p as an orb.Point. As with
+// types.SFPoint.XY, both components will be NaN if p is nil or empty.
+func PointToOrb(p types.SFPoint) orb.Point {
+	return orb.Point(p.XY())
+}
+
+// PointFromOrb returns p as a new XY SFPoint.
+func PointFromOrb(p orb.Point) types.SFPoint {
+	return types.NewSFPointFromXYArray(p)
+}
+
+// LineStringToOrb returns the vertices of l as an orb.LineString. A nil
+// orb.LineString will be returned if l is nil.
+func LineStringToOrb(l types.SFLineString) orb.LineString {
+	if l.IsNil() {
+		return nil
+	}
+	return orb.LineString(toPoints(l.FlatCoords(), l.Stride()))
+}
+
+// LineStringFromOrb returns l as a new XY SFLineString.
+func LineStringFromOrb(l orb.LineString) types.SFLineString {
+	return types.NewSFLineStringXY(fromPoints(l))
+}
+
+// PolygonToOrb returns the rings of p as an orb.Polygon, external ring first.
+// A nil orb.Polygon will be returned if p is nil.
+func PolygonToOrb(p types.SFPolygon) orb.Polygon {
+	if p.IsNil() {
+		return nil
+	}
+	ret := make(orb.Polygon, p.NumLinearRings())
+	for i := range ret {
+		r := p.LinearRing(i)
+		ret[i] = orb.Ring(toPoints(r.FlatCoords(), r.Stride()))
+	}
+	return ret
+}
+
+// PolygonFromOrb returns p as a new XY SFPolygon. An empty SFPolygon will be
+// returned if p has no rings.
+func PolygonFromOrb(p orb.Polygon) types.SFPolygon {
+	if len(p) == 0 {
+		return types.SFPolygon{}
+	}
+	internals := make([][][2]float64, len(p)-1)
+	for i, r := range p[1:] {
+		internals[i] = fromPoints(r)
+	}
+	return types.NewSFPolygonXY(fromPoints(p[0]), internals...)
+}
+
+// ToOrb converts g, which must be a types.SFPoint, SFLineString, or SFPolygon
+// (or a pointer to one), into the equivalent orb.Geometry. A nil g converts
+// into a nil orb.Geometry.
+func ToOrb(g types.SFGeometry) (orb.Geometry, error) {
+	switch g := g.(type) {
+	case nil:
+		return nil, nil
+	case types.SFPoint:
+		return PointToOrb(g), nil
+	case *types.SFPoint:
+		return PointToOrb(*g), nil
+	case types.SFLineString:
+		return LineStringToOrb(g), nil
+	case *types.SFLineString:
+		return LineStringToOrb(*g), nil
+	case types.SFPolygon:
+		return PolygonToOrb(g), nil
+	case *types.SFPolygon:
+		return PolygonToOrb(*g), nil
+	default:
+		return nil, fmt.Errorf("orbconv: cannot convert %T to an orb.Geometry", g)
+	}
+}
+
+// FromOrb converts g, which must be an orb.Point, orb.LineString, orb.Ring, or
+// orb.Polygon, into the equivalent SF geometry. Rings are converted into
+// SFLineStrings. A nil g converts into a nil SFGeometry.
+func FromOrb(g orb.Geometry) (types.SFGeometry, error) {
+	switch g := g.(type) {
+	case nil:
+		return nil, nil
+	case orb.Point:
+		return PointFromOrb(g), nil
+	case orb.LineString:
+		return LineStringFromOrb(g), nil
+	case orb.Ring:
+		return LineStringFromOrb(orb.LineString(g)), nil
+	case orb.Polygon:
+		return PolygonFromOrb(g), nil
+	default:
+		return nil, fmt.Errorf("orbconv: unsupported orb geometry type %s", g.GeoJSONType())
+	}
+}
+
+func toPoints(flat []float64, stride int) []orb.Point {
+	ret := make([]orb.Point, 0, len(flat)/stride)
+	for i := 0; i+1 < len(flat); i += stride {
+		ret = append(ret, orb.Point{flat[i], flat[i+1]})
+	}
+	return ret
+}
+
+func fromPoints(ps []orb.Point) [][2]float64 {
+	ret := make([][2]float64, len(ps))
+	for i, p := range ps {
+		ret[i] = p
+	}
+	return ret
+}
@@ -0,0 +1,78 @@
+package orbconv_test
+
+import (
+	"testing"
+
+	"github.com/paulmach/orb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pyrrho/encoding/types"
+	"github.com/pyrrho/encoding/types/orbconv"
+)
+
+func TestPoint(t *testing.T) {
+	require := require.New(t)
+
+	require.Equal(orb.Point{1.2, 2.3}, orbconv.PointToOrb(types.NewSFPointXYZ(1.2, 2.3, 100)))
+	require.Equal(types.NewSFPointXY(1.2, 2.3), orbconv.PointFromOrb(orb.Point{1.2, 2.3}))
+}
+
+func TestLineString(t *testing.T) {
+	require := require.New(t)
+
+	points := [][2]float64{{30, 10}, {10, 30}, {40, 40}}
+	l := types.NewSFLineStringXY(points)
+	ol := orbconv.LineStringToOrb(l)
+	require.Equal(orb.LineString{{30, 10}, {10, 30}, {40, 40}}, ol)
+	require.Equal(l, orbconv.LineStringFromOrb(ol))
+
+	// Altitudes are dropped.
+	l = types.NewSFLineStringXYZ([][3]float64{{30, 10, 1}, {10, 30, 2}})
+	require.Equal(orb.LineString{{30, 10}, {10, 30}}, orbconv.LineStringToOrb(l))
+
+	require.Nil(orbconv.LineStringToOrb(types.SFLineString{}))
+}
+
+func TestPolygon(t *testing.T) {
+	require := require.New(t)
+
+	external := [][2]float64{{35, 10}, {45, 45}, {15, 40}, {10, 20}, {35, 10}}
+	internal := [][2]float64{{20, 30}, {35, 35}, {30, 20}, {20, 30}}
+	p := types.NewSFPolygonXY(external, internal)
+	op := orbconv.PolygonToOrb(p)
+	require.Len(op, 2)
+	require.Equal(orb.Ring{{35, 10}, {45, 45}, {15, 40}, {10, 20}, {35, 10}}, op[0])
+	require.Equal(orb.Ring{{20, 30}, {35, 35}, {30, 20}, {20, 30}}, op[1])
+	require.Equal(p, orbconv.PolygonFromOrb(op))
+
+	require.Nil(orbconv.PolygonToOrb(types.SFPolygon{}))
+	require.True(orbconv.PolygonFromOrb(nil).IsNil())
+}
+
+func TestGeometry(t *testing.T) {
+	require := require.New(t)
+
+	p := types.NewSFPointXY(1, 2)
+	g, err := orbconv.ToOrb(p)
+	require.NoError(err)
+	require.Equal(orb.Point{1, 2}, g)
+	g, err = orbconv.ToOrb(&p)
+	require.NoError(err)
+	require.Equal(orb.Point{1, 2}, g)
+	g, err = orbconv.ToOrb(nil)
+	require.NoError(err)
+	require.Nil(g)
+
+	sf, err := orbconv.FromOrb(orb.Ring{{0, 0}, {1, 0}, {1, 1}, {0, 0}})
+	require.NoError(err)
+	require.Equal(types.NewSFLineStringXY([][2]float64{{0, 0}, {1, 0}, {1, 1}, {0, 0}}), sf)
+	sf, err = orbconv.FromOrb(orb.Polygon{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}})
+	require.NoError(err)
+	require.Equal("Polygon", sf.GeometryType())
+	sf, err = orbconv.FromOrb(nil)
+	require.NoError(err)
+	require.Nil(sf)
+
+	_, err = orbconv.FromOrb(orb.MultiPoint{{1, 2}})
+	require.EqualError(err, "orbconv: unsupported orb geometry type MultiPoint")
+}
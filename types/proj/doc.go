@@ -0,0 +1,13 @@
+/*
+Package proj provides a registry of coordinate transforms between spatial
+reference systems, identified by their EPSG codes, and helpers for applying
+those transforms to the pyrrho/encoding/types SF geometries.
+
+Only the transforms between WGS84 longitude/latitude (EPSG:4326) and Web
+Mercator (EPSG:3857) are built in. Others may be added with Register, keeping
+heavyweight projection libraries an opt-in dependency.
+
+Importing this package also enables types.SFPoint.TransformEPSG, which
+reprojects a point from the spatial reference system named by its SRID.
+*/
+package proj
@@ -0,0 +1,124 @@
+package proj
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/twpayne/go-geom"
+
+	"github.com/pyrrho/encoding/types"
+)
+
+// EPSG codes for the spatial reference systems with built-in transforms.
+const (
+	WGS84       = 4326
+	WebMercator = 3857
+)
+
+// TransformFunc converts a single coordinate from one spatial reference system
+// to another. Two-dimensional coordinates are passed, and should be returned,
+// with a z of 0.
+type TransformFunc func(x, y, z float64) (float64, float64, float64)
+
+type srsPair struct {
+	from, to int
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[srsPair]TransformFunc{
+		{WGS84, WebMercator}: wgs84ToWebMercator,
+		{WebMercator, WGS84}: webMercatorToWGS84,
+	}
+)
+
+// Register adds fn to the registry as the transform from the spatial reference
+// system identified by the EPSG code from to the one identified by to,
+// replacing any transform previously registered for that pair. Passing a nil
+// fn removes the pair from the registry.
+func Register(from, to int, fn TransformFunc) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if fn == nil {
+		delete(registry, srsPair{from, to})
+		return
+	}
+	registry[srsPair{from, to}] = fn
+}
+
+// Lookup returns the transform registered from the EPSG code from to the EPSG
+// code to, and whether one was found.
+func Lookup(from, to int) (TransformFunc, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	fn, ok := registry[srsPair{from, to}]
+	return fn, ok
+}
+
+// TransformPoint returns a copy of p reprojected from the spatial reference
+// system identified by the EPSG code from to the one identified by to, using
+// the registered transform for that pair. The layout of p is preserved; M
+// values are copied through unchanged, and the SRID of the returned point is
+// set to to. If from and to are equal, p is returned unmodified, save for its
+// SRID.
+func TransformPoint(p types.SFPoint, from, to int) (types.SFPoint, error) {
+	if len(p.FlatCoords()) == 0 {
+		return types.SFPoint{}, fmt.Errorf("proj: cannot transform a nil or empty SFPoint")
+	}
+	if from == to {
+		p.SetSRID(to)
+		return p, nil
+	}
+	fn, ok := Lookup(from, to)
+	if !ok {
+		return types.SFPoint{}, fmt.Errorf("proj: no transform registered from EPSG:%d to EPSG:%d", from, to)
+	}
+
+	layout := p.Layout()
+	c := append(geom.Coord(nil), p.Coords()...)
+	var z float64
+	if zi := layout.ZIndex(); zi != -1 {
+		z = c[zi]
+	}
+	x, y, z := fn(c[0], c[1], z)
+	c[0], c[1] = x, y
+	if zi := layout.ZIndex(); zi != -1 {
+		c[zi] = z
+	}
+
+	ret, err := geom.NewPoint(layout).SetCoords(c)
+	if err != nil {
+		return types.SFPoint{}, err
+	}
+	ret.SetSRID(to)
+	return types.NewSFPoint(*ret), nil
+}
+
+func init() {
+	types.SetPointTransformer(TransformPoint)
+}
+
+// Built-in transforms
+
+// earthRadius is the semi-major axis of the WGS84 ellipsoid, in meters, used by
+// the spherical Web Mercator projection.
+const earthRadius = 6378137.0
+
+// maxMercatorLat is the latitude at which Web Mercator's y coordinate equals
+// its x coordinate at 180 degrees longitude, making the projected world square.
+// Latitudes beyond it are clamped.
+const maxMercatorLat = 85.051128779806592
+
+func wgs84ToWebMercator(lng, lat, z float64) (float64, float64, float64) {
+	lat = math.Max(-maxMercatorLat, math.Min(maxMercatorLat, lat))
+	x := earthRadius * lng * math.Pi / 180
+	y := earthRadius * math.Log(math.Tan(math.Pi/4+lat*math.Pi/360))
+	return x, y, z
+}
+
+func webMercatorToWGS84(x, y, z float64) (float64, float64, float64) {
+	lng := x / earthRadius * 180 / math.Pi
+	lat := (2*math.Atan(math.Exp(y/earthRadius)) - math.Pi/2) * 180 / math.Pi
+	return lng, lat, z
+}
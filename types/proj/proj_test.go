@@ -0,0 +1,114 @@
+package proj_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pyrrho/encoding/types"
+	"github.com/pyrrho/encoding/types/proj"
+)
+
+func withSRID(p types.SFPoint, srid int) types.SFPoint {
+	p.SetSRID(srid)
+	return p
+}
+
+func TestTransformPointWebMercator(t *testing.T) {
+	require := require.New(t)
+
+	// The origin maps to the origin.
+	p, err := proj.TransformPoint(types.NewSFPointXY(0, 0), proj.WGS84, proj.WebMercator)
+	require.NoError(err)
+	require.InDelta(0.0, p.X(), 1e-9)
+	require.InDelta(0.0, p.Y(), 1e-9)
+
+	// Known values, as computed by PROJ.
+	p, err = proj.TransformPoint(types.NewSFPointXY(-122.4194, 37.7749), proj.WGS84, proj.WebMercator)
+	require.NoError(err)
+	require.InDelta(-13627665.27, p.X(), 0.01)
+	require.InDelta(4547675.35, p.Y(), 0.01)
+
+	// Latitudes beyond the projection's bounds are clamped.
+	p, err = proj.TransformPoint(types.NewSFPointXY(180, 90), proj.WGS84, proj.WebMercator)
+	require.NoError(err)
+	require.InDelta(p.X(), p.Y(), 0.01)
+
+	// The inverse round-trips, preserving altitude.
+	src := types.NewSFPointXYZ(151.2093, -33.8688, 58)
+	merc, err := proj.TransformPoint(src, proj.WGS84, proj.WebMercator)
+	require.NoError(err)
+	require.Equal(src.Layout(), merc.Layout())
+	require.Equal(58.0, merc.Z())
+	rt, err := proj.TransformPoint(merc, proj.WebMercator, proj.WGS84)
+	require.NoError(err)
+	require.InDelta(src.Lng(), rt.Lng(), 1e-9)
+	require.InDelta(src.Lat(), rt.Lat(), 1e-9)
+	require.Equal(58.0, rt.Alt())
+
+	// The source point is not modified.
+	require.Equal(types.NewSFPointXYZ(151.2093, -33.8688, 58), src)
+}
+
+func TestTransformPointErrors(t *testing.T) {
+	require := require.New(t)
+
+	_, err := proj.TransformPoint(types.SFPoint{}, proj.WGS84, proj.WebMercator)
+	require.Error(err)
+
+	_, err = proj.TransformPoint(types.NewSFPointXY(1, 2), proj.WGS84, 27700)
+	require.Error(err)
+
+	// Transforming into the same system is a no-op, registered or not, save
+	// for setting the SRID.
+	p, err := proj.TransformPoint(types.NewSFPointXY(1, 2), 27700, 27700)
+	require.NoError(err)
+	require.Equal(withSRID(types.NewSFPointXY(1, 2), 27700), p)
+}
+
+func TestRegister(t *testing.T) {
+	require := require.New(t)
+
+	_, ok := proj.Lookup(4326, 900913)
+	require.False(ok)
+
+	proj.Register(4326, 900913, func(x, y, z float64) (float64, float64, float64) {
+		return x * 2, y * 2, z
+	})
+	defer proj.Register(4326, 900913, nil)
+
+	fn, ok := proj.Lookup(4326, 900913)
+	require.True(ok)
+	require.NotNil(fn)
+
+	p, err := proj.TransformPoint(types.NewSFPointXY(1, 2), 4326, 900913)
+	require.NoError(err)
+	require.Equal(withSRID(types.NewSFPointXY(2, 4), 900913), p)
+
+	// Registering a nil transform removes the pair.
+	proj.Register(4326, 900913, nil)
+	_, ok = proj.Lookup(4326, 900913)
+	require.False(ok)
+}
+
+func TestTransformEPSG(t *testing.T) {
+	require := require.New(t)
+
+	src := withSRID(types.NewSFPointXY(-122.4194, 37.7749), proj.WGS84)
+	p, err := src.TransformEPSG(proj.WebMercator)
+	require.NoError(err)
+	require.Equal(proj.WebMercator, p.SRID())
+	require.InDelta(-13627665.27, p.X(), 0.01)
+	require.InDelta(4547675.35, p.Y(), 0.01)
+
+	rt, err := p.TransformEPSG(proj.WGS84)
+	require.NoError(err)
+	require.Equal(proj.WGS84, rt.SRID())
+	require.InDelta(src.Lng(), rt.Lng(), 1e-9)
+
+	// Points need an SRID, and a registered transform.
+	_, err = types.NewSFPointXY(1, 2).TransformEPSG(proj.WebMercator)
+	require.Error(err)
+	_, err = src.TransformEPSG(27700)
+	require.Error(err)
+}
@@ -0,0 +1,146 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/twpayne/go-geom/encoding/geojson"
+)
+
+// SFFeature is a GeoJSON Feature; a Simple Feature geometry paired with an
+// optional identifier and a set of arbitrary properties.
+//
+// This type is built on top of the go-geom geojson.Feature type. Features have
+// no WKB representation, so unlike the other SF types only the JSON interfaces
+// (MarshalJSON and UnmarshalJSON) are implemented.
+type SFFeature struct {
+	geojson.Feature
+}
+
+// Interfaces
+
+// String implements the fmt Stringer interface. It will return the WKT
+// representation of f's geometry, or "<nil feature>" if f has no geometry.
+// f's ID and properties are not included.
+func (f SFFeature) String() string {
+	return wktString(f.Geometry, "feature")
+}
+
+// MarshalJSON implements the encoding/json Marshaler interface. It will return
+// the GeoJSON encoded representation of f.
+func (f SFFeature) MarshalJSON() ([]byte, error) {
+	return f.Feature.MarshalJSON()
+}
+
+// UnmarshalJSON implements the encoding/json Unmarshaler interface. It expects
+// to receive a valid GeoJSON Feature, and will assign the value of that data
+// to f. Like the SF geometry types, it ignores a leading UTF-8 byte order mark
+// and surrounding whitespace.
+func (f *SFFeature) UnmarshalJSON(data []byte) error {
+	if f == nil {
+		return fmt.Errorf("types.SFFeature: UnmarshalJSON called on nil pointer")
+	}
+	data = trimGeoJSON(data)
+	var tmp geojson.Feature
+	if err := tmp.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	f.Feature = tmp
+	return nil
+}
+
+// FeatureStreamDecoder reads the members of a GeoJSON FeatureCollection's
+// "features" array from an input stream one at a time, so that arbitrarily
+// large collections can be processed with bounded memory. Members of the
+// FeatureCollection other than "features" are skipped.
+type FeatureStreamDecoder struct {
+	dec     *json.Decoder
+	started bool
+	index   int
+	err     error
+}
+
+// NewFeatureStreamDecoder constructs and returns a new FeatureStreamDecoder
+// that reads from r.
+func NewFeatureStreamDecoder(r io.Reader) *FeatureStreamDecoder {
+	return &FeatureStreamDecoder{
+		dec: json.NewDecoder(r),
+	}
+}
+
+// Next decodes and returns the next Feature in the stream. Once the "features"
+// array has been exhausted, Next will return io.EOF.
+//
+// If an individual Feature is well formed JSON but not a valid GeoJSON Feature,
+// an error naming the index of that Feature will be returned, and decoding may
+// continue with the next call to Next. Any other error will be returned from
+// this and all subsequent calls to Next.
+func (d *FeatureStreamDecoder) Next() (*SFFeature, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	if !d.started {
+		if err := d.seekFeatures(); err != nil {
+			d.err = err
+			return nil, err
+		}
+		d.started = true
+	}
+	if !d.dec.More() {
+		// Consume the closing ']', and stop reading.
+		if _, err := d.dec.Token(); err != nil {
+			d.err = fmt.Errorf("types.FeatureStreamDecoder: %v", err)
+			return nil, d.err
+		}
+		d.err = io.EOF
+		return nil, d.err
+	}
+
+	index := d.index
+	d.index++
+	var raw json.RawMessage
+	if err := d.dec.Decode(&raw); err != nil {
+		d.err = fmt.Errorf("types.FeatureStreamDecoder: feature %d: %v", index, err)
+		return nil, d.err
+	}
+	f := &SFFeature{}
+	if err := f.UnmarshalJSON(raw); err != nil {
+		return nil, fmt.Errorf("types.FeatureStreamDecoder: feature %d: %v", index, err)
+	}
+	return f, nil
+}
+
+// seekFeatures advances d's underlying json.Decoder to the first element of
+// the FeatureCollection's "features" array.
+func (d *FeatureStreamDecoder) seekFeatures() error {
+	if err := d.expectDelim('{'); err != nil {
+		return err
+	}
+	for d.dec.More() {
+		tok, err := d.dec.Token()
+		if err != nil {
+			return fmt.Errorf("types.FeatureStreamDecoder: %v", err)
+		}
+		if key, ok := tok.(string); ok && key == "features" {
+			return d.expectDelim('[')
+		}
+		// Skip over the value of any other member.
+		var skip json.RawMessage
+		if err := d.dec.Decode(&skip); err != nil {
+			return fmt.Errorf("types.FeatureStreamDecoder: %v", err)
+		}
+	}
+	return fmt.Errorf("types.FeatureStreamDecoder: no \"features\" member found")
+}
+
+func (d *FeatureStreamDecoder) expectDelim(delim json.Delim) error {
+	tok, err := d.dec.Token()
+	if err != nil {
+		return fmt.Errorf("types.FeatureStreamDecoder: %v", err)
+	}
+	if tok != delim {
+		return fmt.Errorf("types.FeatureStreamDecoder: expected '%v', got %v", delim, tok)
+	}
+	return nil
+}
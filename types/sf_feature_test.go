@@ -0,0 +1,116 @@
+package types_test
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/twpayne/go-geom"
+
+	"github.com/pyrrho/encoding/types"
+)
+
+var testFeatureGeoJSON = []byte(`{"type":"Feature","id":"one","geometry":{"type":"Point","coordinates":[1.2,2.3]},"properties":{"name":"One"}}`)
+
+func TestSFFeatureJSON(t *testing.T) {
+	require := require.New(t)
+	var err error
+
+	var f types.SFFeature
+	err = json.Unmarshal(testFeatureGeoJSON, &f)
+	require.NoError(err)
+	require.Equal("one", f.ID)
+	require.Equal([]float64{1.2, 2.3}, f.Geometry.FlatCoords())
+	require.Equal(map[string]interface{}{"name": "One"}, f.Properties)
+
+	data, err := json.Marshal(f)
+	require.NoError(err)
+	require.JSONEq(string(testFeatureGeoJSON), string(data))
+
+	var bad types.SFFeature
+	err = json.Unmarshal([]byte(`{"type":"Point","coordinates":[1.2,2.3]}`), &bad)
+	require.Error(err)
+}
+
+func TestFeatureStreamDecoder(t *testing.T) {
+	require := require.New(t)
+
+	// Members before and after "features" are skipped.
+	r := strings.NewReader(`{
+		"type": "FeatureCollection",
+		"bbox": [1.2, 2.3, 3.4, 4.5],
+		"features": [
+			{"type":"Feature","id":"one","geometry":{"type":"Point","coordinates":[1.2,2.3]},"properties":null},
+			{"type":"Feature","id":"two","geometry":{"type":"Point","coordinates":[3.4,4.5]},"properties":null}
+		],
+		"name": "points"
+	}`)
+	d := types.NewFeatureStreamDecoder(r)
+
+	f, err := d.Next()
+	require.NoError(err)
+	require.Equal("one", f.ID)
+	require.Equal(geom.XY, f.Geometry.Layout())
+
+	f, err = d.Next()
+	require.NoError(err)
+	require.Equal("two", f.ID)
+	require.Equal([]float64{3.4, 4.5}, f.Geometry.FlatCoords())
+
+	f, err = d.Next()
+	require.Equal(io.EOF, err)
+	require.Nil(f)
+
+	// EOF is sticky.
+	_, err = d.Next()
+	require.Equal(io.EOF, err)
+}
+
+func TestFeatureStreamDecoderErrors(t *testing.T) {
+	require := require.New(t)
+	var err error
+
+	// A malformed Feature reports its index, but doesn't stop the stream.
+	d := types.NewFeatureStreamDecoder(strings.NewReader(`{"features":[
+		{"type":"Feature","geometry":{"type":"Point","coordinates":[1.2,2.3]},"properties":null},
+		{"type":"NotAFeature"},
+		{"type":"Feature","id":"three","geometry":{"type":"Point","coordinates":[1.2,2.3]},"properties":null}
+	]}`))
+	_, err = d.Next()
+	require.NoError(err)
+	_, err = d.Next()
+	require.Error(err)
+	require.Contains(err.Error(), "feature 1")
+	f, err := d.Next()
+	require.NoError(err)
+	require.Equal("three", f.ID)
+	_, err = d.Next()
+	require.Equal(io.EOF, err)
+
+	// Documents without a "features" array are errors.
+	d = types.NewFeatureStreamDecoder(strings.NewReader(`{"type":"FeatureCollection"}`))
+	_, err = d.Next()
+	require.Error(err)
+	require.NotEqual(io.EOF, err)
+
+	d = types.NewFeatureStreamDecoder(strings.NewReader(`[]`))
+	_, err = d.Next()
+	require.Error(err)
+
+	// Truncated input is an error, not an EOF.
+	d = types.NewFeatureStreamDecoder(strings.NewReader(`{"features":[{"type":"Feature",`))
+	_, err = d.Next()
+	require.Error(err)
+	require.NotEqual(io.EOF, err)
+}
+
+func TestSFFeatureString(t *testing.T) {
+	require := require.New(t)
+
+	var f types.SFFeature
+	require.NoError(json.Unmarshal(testFeatureGeoJSON, &f))
+	require.Equal("POINT (1.2 2.3)", f.String())
+	require.Equal("<nil feature>", types.SFFeature{}.String())
+}
@@ -0,0 +1,490 @@
+package types
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/geojson"
+	"github.com/twpayne/go-geom/encoding/wkb"
+)
+
+// SFLineString is a Simple Feature LineString, named for the OpenGIS
+// specification that backs WKB, WKT, and GeoJSON representations of geospatial
+// data. An SFLineString represents a path through a series of [longitude,
+// latitude] or [longitude, latitude, altitude] points in a given coordinate
+// system.
+//
+// This type is built on top of the go-geom geom.LineString type, implementing
+// all of the pyrrho/encoding/types interfaces detailed in the package comments.
+// Database interactions (Value and Scan) will convert to and from a WKB (Well
+// Known Binary) representation. JSON interactions (MarshalJSON and
+// UnmarshalJSON) will convert to and from a GeoJSON representation. Per
+// RFC 7946, GeoJSON coordinates are assumed to be WGS84 longitude and latitude;
+// the obsolete "crs" member is neither emitted nor consulted.
+type SFLineString struct {
+	geom.LineString
+}
+
+// Constructors
+
+// NewSFLineString constructs and returns a new SFLineString object initialized
+// with the given geom.LineString l.
+func NewSFLineString(l geom.LineString) SFLineString {
+	return SFLineString{l}
+}
+
+// NewSFLineStringXY constructs and returns a new SFLineString object with
+// longitude and latitude components initialized with the given points.
+func NewSFLineStringXY(points [][2]float64) SFLineString {
+	coords := make([]geom.Coord, len(points))
+	for i := range points {
+		coords[i] = append(geom.Coord(nil), points[i][:]...)
+	}
+	l, err := geom.NewLineString(geom.XY).SetCoords(coords)
+	if err != nil {
+		panic(err)
+	}
+	return SFLineString{*l}
+}
+
+// NewSFLineStringXYZ constructs and returns a new SFLineString object with
+// longitude, latitude, and altitude components initialized with the given
+// points.
+func NewSFLineStringXYZ(points [][3]float64) SFLineString {
+	coords := make([]geom.Coord, len(points))
+	for i := range points {
+		coords[i] = append(geom.Coord(nil), points[i][:]...)
+	}
+	l, err := geom.NewLineString(geom.XYZ).SetCoords(coords)
+	if err != nil {
+		panic(err)
+	}
+	return SFLineString{*l}
+}
+
+// Getters
+
+// GeometryType returns the GeoJSON type name of l, "LineString".
+func (l SFLineString) GeometryType() string {
+	return "LineString"
+}
+
+// BBox returns the bounding box of the longitude and latitude components of
+// every vertex of l. An empty BBox will be returned if l is nil.
+func (l SFLineString) BBox() BBox {
+	if l.IsNil() {
+		return NewBBoxEmpty()
+	}
+	return bboxOf(l.FlatCoords(), l.Stride())
+}
+
+// NumPoints returns the number of vertices in l. Nil SFLineStrings have no
+// vertices.
+func (l SFLineString) NumPoints() int {
+	if l.Layout() == geom.NoLayout {
+		return 0
+	}
+	return l.NumCoords()
+}
+
+// Point returns a copy of the i-th vertex of l as a new SFPoint, with l's
+// layout and SRID. Like indexing a slice, Point will panic if i is out of the
+// range [0, l.NumPoints()).
+func (l SFLineString) Point(i int) SFPoint {
+	if i < 0 || i >= l.NumPoints() {
+		panic(fmt.Sprintf("types.SFLineString: point index %d out of range [0, %d)", i, l.NumPoints()))
+	}
+	stride := l.Stride()
+	coords := append([]float64(nil), l.FlatCoords()[i*stride:(i+1)*stride]...)
+	return SFPoint{*geom.NewPointFlat(l.Layout(), coords).SetSRID(l.SRID())}
+}
+
+// Densify returns a copy of l with vertices inserted so that no segment is
+// longer than maxSegmentLength. The original vertices are all preserved, and
+// each segment is split into the fewest equal-length pieces that satisfy the
+// limit. Any Z or M components of the inserted vertices are linearly
+// interpolated.
+//
+// Lengths are planar; they're measured in the units of l's coordinates, and
+// only consider the X and Y components. If maxSegmentLength is not a positive
+// number, an unmodified copy of l is returned. An empty SFLineString will be
+// returned if l is nil.
+func (l SFLineString) Densify(maxSegmentLength float64) SFLineString {
+	if l.IsNil() {
+		return SFLineString{}
+	}
+	stride := l.Stride()
+	flat := l.FlatCoords()
+	if !(maxSegmentLength > 0) || math.IsInf(maxSegmentLength, 1) {
+		return SFLineString{*geom.NewLineStringFlat(l.Layout(), append([]float64(nil), flat...)).SetSRID(l.SRID())}
+	}
+
+	out := make([]float64, 0, len(flat))
+	out = append(out, flat[:stride]...)
+	for i := stride; i < len(flat); i += stride {
+		a, b := flat[i-stride:i], flat[i:i+stride]
+		n := math.Ceil(math.Hypot(b[0]-a[0], b[1]-a[1]) / maxSegmentLength)
+		for k := 1.0; k < n; k++ {
+			for j := 0; j < stride; j++ {
+				out = append(out, a[j]+(b[j]-a[j])*k/n)
+			}
+		}
+		out = append(out, b...)
+	}
+	return SFLineString{*geom.NewLineStringFlat(l.Layout(), out).SetSRID(l.SRID())}
+}
+
+// Length returns the planar length of l; the sum of the lengths of its
+// segments. As with Densify, only the X and Y components are considered, and
+// the length is measured in the units of l's coordinates. Nil SFLineStrings,
+// and those with fewer than two points, have no length.
+func (l SFLineString) Length() float64 {
+	flat, stride := l.FlatCoords(), l.Stride()
+	var length float64
+	for i := stride; i < len(flat); i += stride {
+		length += math.Hypot(flat[i]-flat[i-stride], flat[i+1]-flat[i-stride+1])
+	}
+	return length
+}
+
+// MultiLineStringLength returns the total planar length of lines, as measured
+// by SFLineString.Length, treating them as the components of a
+// MultiLineString. Nil and empty lines contribute nothing.
+func MultiLineStringLength(lines []SFLineString) float64 {
+	var length float64
+	for _, l := range lines {
+		length += l.Length()
+	}
+	return length
+}
+
+// ClosestPoint returns the point on l nearest to p, and the distance between
+// the two. Where several points on l are equally near, the one closest to the
+// start of l is returned. Zero-length segments are treated as single vertices.
+// Any Z or M components of the returned point are linearly interpolated along
+// the segment it lies on, and it has the same layout and SRID as l.
+//
+// As with Densify, distances are planar, and only consider the X and Y
+// components. An error will be returned if l is nil, or if p is nil or empty.
+func (l SFLineString) ClosestPoint(p SFPoint) (SFPoint, float64, error) {
+	if l.IsNil() {
+		return SFPoint{}, 0, fmt.Errorf("types.SFLineString: cannot find the closest point on an empty SFLineString")
+	}
+	if len(p.FlatCoords()) == 0 {
+		return SFPoint{}, 0, fmt.Errorf("types.SFLineString: cannot find the closest point to a nil or empty SFPoint")
+	}
+	stride := l.Stride()
+	flat := l.FlatCoords()
+	px, py := p.X(), p.Y()
+
+	// Start with the first vertex, so that single-vertex lines have an answer.
+	best := flat[:stride]
+	bestT, bestDist := 0.0, math.Hypot(px-flat[0], py-flat[1])
+	bestSeg := 0
+	for i := stride; i < len(flat); i += stride {
+		a, b := flat[i-stride:i], flat[i:i+stride]
+		dx, dy := b[0]-a[0], b[1]-a[1]
+		t := 0.0
+		if l2 := dx*dx + dy*dy; l2 > 0 {
+			t = math.Max(0, math.Min(1, ((px-a[0])*dx+(py-a[1])*dy)/l2))
+		}
+		if d := math.Hypot(px-(a[0]+t*dx), py-(a[1]+t*dy)); d < bestDist {
+			best, bestT, bestDist, bestSeg = a, t, d, i
+		}
+	}
+
+	coords := append([]float64(nil), best...)
+	if bestSeg > 0 {
+		b := flat[bestSeg : bestSeg+stride]
+		for j := range coords {
+			coords[j] += (b[j] - coords[j]) * bestT
+		}
+	}
+	return SFPoint{*geom.NewPointFlat(l.Layout(), coords).SetSRID(l.SRID())}, bestDist, nil
+}
+
+// MergeLineStrings joins lines that share endpoints into longer, continuous
+// lines -- the GIS "line merge" operation -- and returns the fewest lines
+// that can be made this way; eg. reassembling road segments into routes. Two
+// lines are joined where an endpoint of one exactly equals an endpoint of the
+// other, and no third line ends there, so junctions where three or more lines
+// meet are left unmerged. Lines are only joined if they share a layout and an
+// SRID, and endpoints must match in every component, with no tolerance.
+//
+// Joined lines keep the direction of the earliest of their parts in lines,
+// reversing later parts as needed, and are returned in the order of those
+// earliest parts. If no lines can be joined, copies of the originals are
+// returned. Nil SFLineStrings are dropped, and lines is not modified.
+func MergeLineStrings(lines []SFLineString) []SFLineString {
+	type part struct {
+		flat   []float64
+		layout geom.Layout
+		srid   int
+		alive  bool
+	}
+	parts := make([]*part, 0, len(lines))
+	for _, l := range lines {
+		if l.IsNil() {
+			continue
+		}
+		parts = append(parts, &part{
+			flat:   append([]float64(nil), l.FlatCoords()...),
+			layout: l.Layout(),
+			srid:   l.SRID(),
+			alive:  true,
+		})
+	}
+
+	// ends maps each endpoint to the indices of the parts that end there; a
+	// part appears twice for an endpoint it both starts and ends at.
+	endKey := func(p *part, atEnd bool) string {
+		stride := p.layout.Stride()
+		c := p.flat[:stride]
+		if atEnd {
+			c = p.flat[len(p.flat)-stride:]
+		}
+		b := make([]byte, 0, 16+8*stride)
+		b = binary.LittleEndian.AppendUint64(b, uint64(p.layout))
+		b = binary.LittleEndian.AppendUint64(b, uint64(p.srid))
+		for _, v := range c {
+			// Adding zero normalizes -0 to 0, so the two compare equal.
+			b = binary.LittleEndian.AppendUint64(b, math.Float64bits(v+0))
+		}
+		return string(b)
+	}
+	ends := make(map[string][]int, 2*len(parts))
+	remove := func(k string, i int) {
+		refs := ends[k]
+		for n, r := range refs {
+			if r == i {
+				ends[k] = append(refs[:n:n], refs[n+1:]...)
+				return
+			}
+		}
+	}
+	for i, p := range parts {
+		ends[endKey(p, false)] = append(ends[endKey(p, false)], i)
+		ends[endKey(p, true)] = append(ends[endKey(p, true)], i)
+	}
+
+	for i, p := range parts {
+		for merged := true; merged; {
+			merged = false
+			for _, atEnd := range []bool{true, false} {
+				k := endKey(p, atEnd)
+				refs := ends[k]
+				if len(refs) != 2 || refs[0] == refs[1] {
+					continue
+				}
+				j := refs[0]
+				if j == i {
+					j = refs[1]
+				}
+				q := parts[j]
+				qStartsHere := endKey(q, false) == k
+				qOther := endKey(q, qStartsHere)
+				remove(k, i)
+				remove(k, j)
+				remove(qOther, j)
+
+				stride := p.layout.Stride()
+				qflat := q.flat
+				if qStartsHere != atEnd {
+					// q runs against p's direction.
+					qflat = append([]float64(nil), q.flat...)
+					reverseRing(qflat, stride)
+				}
+				if atEnd {
+					p.flat = append(p.flat, qflat[stride:]...)
+				} else {
+					p.flat = append(qflat[:len(qflat)-stride:len(qflat)-stride], p.flat...)
+				}
+				q.alive = false
+				ends[qOther] = append(ends[qOther], i)
+				merged = true
+				break
+			}
+		}
+	}
+
+	ret := make([]SFLineString, 0, len(parts))
+	for _, p := range parts {
+		if p.alive {
+			ret = append(ret, SFLineString{*geom.NewLineStringFlat(p.layout, p.flat).SetSRID(p.srid)})
+		}
+	}
+	return ret
+}
+
+// MarshalTWKB returns the TWKB (Tiny Well-Known Binary) encoded representation
+// of l. Coordinates are rounded as described by SFPoint.MarshalTWKB, and then
+// delta encoded, so lines of many nearby points encode especially compactly. An
+// SFLineString with a layout but no points is encoded as an empty TWKB
+// LineString. If l has no layout, an error will be returned.
+func (l SFLineString) MarshalTWKB(precision int) ([]byte, error) {
+	if l.Layout() == geom.NoLayout {
+		return nil, fmt.Errorf("types.SFLineString: cannot encode an uninitialized SFLineString as TWKB")
+	}
+	b, err := appendTWKB(nil, twkbLineString, l.Layout(), l.FlatCoords(), precision)
+	if err != nil {
+		return nil, fmt.Errorf("types.SFLineString: %v", err)
+	}
+	return b, nil
+}
+
+// UnmarshalTWKB decodes the TWKB encoded LineString data into l, at whatever
+// precision it was encoded with. If data is not a well formed TWKB LineString,
+// an error will be returned, and l will be unchanged.
+func (l *SFLineString) UnmarshalTWKB(data []byte) error {
+	if l == nil {
+		return fmt.Errorf("types.SFLineString: UnmarshalTWKB called on nil pointer")
+	}
+	layout, flat, err := decodeTWKB(data, twkbLineString)
+	if err != nil {
+		return fmt.Errorf("types.SFLineString: %v", err)
+	}
+	*l = SFLineString{*geom.NewLineStringFlat(layout, flat)}
+	return nil
+}
+
+// MarshalKML returns the KML <LineString> element describing l, with its
+// coordinates listed as SFPoint.MarshalKML would write them. An error will be
+// returned if l is nil, or has fewer than two points.
+func (l SFLineString) MarshalKML() ([]byte, error) {
+	if l.IsNil() {
+		return nil, fmt.Errorf("types.SFLineString: cannot encode a nil SFLineString as KML")
+	}
+	if n := l.NumCoords(); n < 2 {
+		return nil, fmt.Errorf("types.SFLineString: cannot encode a LineString with %d points as KML", n)
+	}
+	return appendKMLElement(nil, "LineString", l.Layout(), l.FlatCoords()), nil
+}
+
+// Interfaces
+
+// IsNil implements the pyrrho/encoding IsNiler interface. It will return true
+// if l contains no meaningful data. More specifically, if this SFLineString has
+// been zero-initialized, or if it has been explicitly initialized with no
+// layout or no points;
+//
+//	var l types.SFLineString
+//	var l := types.SFLineString{}
+//	var l := types.NewSFLineString(geom.LineString{geom.NoLayout})
+//	var l := types.NewSFLineStringXY(nil)
+func (l SFLineString) IsNil() bool {
+	return len(l.FlatCoords()) == 0 || l.Layout() == geom.NoLayout
+}
+
+// IsZero implements the pyrrho/encoding IsZeroer interface. It will return true
+// if l.IsNil() returns true, or if the contained data is of the zero-value.
+func (l SFLineString) IsZero() bool {
+	for _, f := range l.FlatCoords() {
+		if f != 0.0 {
+			return false
+		}
+	}
+	return true
+}
+
+// String implements the fmt Stringer interface. It will return the WKT
+// representation of l -- eg. "LINESTRING (1 2, 3 4)" -- "LINESTRING EMPTY" if l
+// has a layout but no points, or "<nil linestring>" if l has no layout.
+func (l SFLineString) String() string {
+	return wktString(&l.LineString, "linestring")
+}
+
+// Value implements the database/sql/driver Valuer interface. It will return the
+// value of l as a driver.Value; specifically a WKB encoded []byte.
+func (l SFLineString) Value() (driver.Value, error) {
+	b := &bytes.Buffer{}
+	if err := wkb.Write(b, wkb.NDR, &l.LineString); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// Scan implements the database/sql Scanner interface. It expects to receive a
+// WKB encoded []byte describing a LineString from an SQL database, and will
+// assign that value to l. Strings, *[]bytes, and driver.Valuers that produce
+// either are accepted in place of a []byte. If the incoming []byte is not a
+// well formed WKB, or if that WKB value does not describe a LineString, an
+// error will be returned.
+func (l *SFLineString) Scan(src interface{}) error {
+	if l == nil {
+		return fmt.Errorf("types.SFLineString: Scan called on nil pointer")
+	}
+	b, err := scanBytes(src)
+	if err != nil {
+		return fmt.Errorf("types.SFLineString: %v", err)
+	}
+	if err := checkWKBGeometryType(b, "LineString"); err != nil {
+		return fmt.Errorf("types.SFLineString: %v", err)
+	}
+	g, err := wkb.Unmarshal(b)
+	if err != nil {
+		return err
+	}
+	t, ok := g.(*geom.LineString)
+	if !ok {
+		return fmt.Errorf("types.SFLineString: scan did not return a *geom.LineString (got a %T)", g)
+	}
+	l.LineString.Swap(t)
+	return nil
+}
+
+// MarshalJSON implements the encoding/json Marshaler interface. It will return
+// the GeoJSON encoded representation of l. If l is nil, an error will be
+// returned, or 'null' if EmptyGeometryAsNull is true.
+func (l SFLineString) MarshalJSON() ([]byte, error) {
+	if l.IsNil() {
+		if EmptyGeometryAsNull {
+			return []byte("null"), nil
+		}
+		return nil, fmt.Errorf("types.SFLineString: cannot marshal an uninitialized SFLineString")
+	}
+	return marshalGeoJSON(&l.LineString)
+}
+
+// UnmarshalJSON implements the encoding/json Unmarshaler interface. It expects
+// to receive a valid GeoJSON Geometry of the type LineString, and will assign
+// the value of that data to l. data may begin with a UTF-8 byte order mark, and
+// be padded with whitespace.
+func (l *SFLineString) UnmarshalJSON(data []byte) error {
+	if l == nil {
+		return fmt.Errorf("types.SFLineString: UnmarshalJSON called on nil pointer")
+	}
+	data = trimGeoJSON(data)
+	var gt geom.T
+	if err := geojson.Unmarshal(data, &gt); err != nil {
+		return err
+	}
+	if gt == nil {
+		// The 'null' keyword decodes into a nil LineString.
+		l.LineString = geom.LineString{}
+		return nil
+	}
+	t, ok := gt.(*geom.LineString)
+	if !ok {
+		return fmt.Errorf("types.SFLineString: cannot unmarshal a GeoJSON %T", gt)
+	}
+	if err := checkGeoJSONBBox(data, t); err != nil {
+		return fmt.Errorf("types.SFLineString: %v", err)
+	}
+	l.LineString.Swap(t)
+	return nil
+}
+
+// MarshalMapValue implements the pyrrho/encoding/maps Marshaler interface. It
+// will return l wrapped in an interface{} for use in a map[string]interface{},
+// or l's GeoJSON representation as a map[string]interface{} if
+// MapValueAsGeoJSON is true.
+func (l SFLineString) MarshalMapValue() (interface{}, error) {
+	if MapValueAsGeoJSON {
+		return geoJSONMapValue(l)
+	}
+	return l, nil
+}
@@ -0,0 +1,322 @@
+package types_test
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"testing"
+
+	"github.com/pyrrho/encoding/maps"
+	"github.com/pyrrho/encoding/types"
+	"github.com/stretchr/testify/require"
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/wkb"
+)
+
+var (
+	testLineStringPoints  = [][2]float64{{30, 10}, {10, 30}, {40, 40}}
+	testLineStringGeoJSON = []byte(`{"type":"LineString","coordinates":[[30,10],[10,30],[40,40]]}`)
+	testLineStringGoGeom  = *geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{30, 10}, {10, 30}, {40, 40}})
+)
+
+func TestSFLineStringCtors(t *testing.T) {
+	require := require.New(t)
+
+	l := types.NewSFLineString(testLineStringGoGeom)
+	require.Equal(geom.XY, l.Layout())
+	require.Equal([]float64{30, 10, 10, 30, 40, 40}, l.FlatCoords())
+
+	xy := types.NewSFLineStringXY(testLineStringPoints)
+	require.Equal(l, xy)
+
+	xyz := types.NewSFLineStringXYZ([][3]float64{{1, 2, 3}, {4, 5, 6}})
+	require.Equal(geom.XYZ, xyz.Layout())
+	require.Equal([]float64{1, 2, 3, 4, 5, 6}, xyz.FlatCoords())
+}
+
+func TestSFLineStringDensify(t *testing.T) {
+	require := require.New(t)
+
+	// Segments longer than the limit are split into equal pieces, and original
+	// vertices are preserved.
+	l := types.NewSFLineStringXY([][2]float64{{0, 0}, {10, 0}, {10, 1}})
+	d := l.Densify(4)
+	require.Equal(geom.XY, d.Layout())
+	require.Equal([]float64{
+		0, 0,
+		10.0 / 3, 0,
+		20.0 / 3, 0,
+		10, 0,
+		10, 1,
+	}, d.FlatCoords())
+
+	// A segment exactly as long as the limit is left alone.
+	d = l.Densify(10)
+	require.Equal(l.FlatCoords(), d.FlatCoords())
+
+	// Length is planar, measured in X and Y, and Z is interpolated.
+	l3 := types.NewSFLineStringXYZ([][3]float64{{0, 0, 100}, {3, 4, 200}})
+	d = l3.Densify(2.5)
+	require.Equal(geom.XYZ, d.Layout())
+	require.Equal([]float64{0, 0, 100, 1.5, 2, 150, 3, 4, 200}, d.FlatCoords())
+
+	// The source is not modified.
+	require.Equal([]float64{0, 0, 10, 0, 10, 1}, l.FlatCoords())
+
+	// Non-positive limits return an unmodified copy.
+	for _, max := range []float64{0, -1} {
+		d = l.Densify(max)
+		require.Equal(l.FlatCoords(), d.FlatCoords())
+		d.FlatCoords()[0] = 42
+		require.Equal(0.0, l.FlatCoords()[0])
+	}
+
+	// The SRID is kept, whether or not vertices are inserted.
+	l.SetSRID(4326)
+	d = l.Densify(4)
+	require.Equal(4326, d.SRID())
+	d = l.Densify(0)
+	require.Equal(4326, d.SRID())
+
+	// Empty line strings remain empty.
+	require.True(types.SFLineString{}.Densify(1).IsNil())
+}
+
+func TestSFLineStringClosestPoint(t *testing.T) {
+	require := require.New(t)
+
+	l := types.NewSFLineStringXY([][2]float64{{0, 0}, {10, 0}, {10, 10}})
+
+	// Projections onto the interior of a segment.
+	p, d, err := l.ClosestPoint(types.NewSFPointXY(4, 3))
+	require.NoError(err)
+	require.Equal(types.NewSFPointXY(4, 0), p)
+	require.Equal(3.0, d)
+	p, d, err = l.ClosestPoint(types.NewSFPointXY(12, 5))
+	require.NoError(err)
+	require.Equal(types.NewSFPointXY(10, 5), p)
+	require.Equal(2.0, d)
+
+	// Points beyond the ends snap to the end vertices.
+	p, d, err = l.ClosestPoint(types.NewSFPointXY(-3, -4))
+	require.NoError(err)
+	require.Equal(types.NewSFPointXY(0, 0), p)
+	require.Equal(5.0, d)
+
+	// Points on the line are their own closest point.
+	p, d, err = l.ClosestPoint(types.NewSFPointXY(10, 7))
+	require.NoError(err)
+	require.Equal(types.NewSFPointXY(10, 7), p)
+	require.Equal(0.0, d)
+
+	// Z is interpolated along the segment.
+	l3 := types.NewSFLineStringXYZ([][3]float64{{0, 0, 100}, {10, 0, 200}})
+	p, d, err = l3.ClosestPoint(types.NewSFPointXY(2.5, 1))
+	require.NoError(err)
+	require.Equal(types.NewSFPointXYZ(2.5, 0, 125), p)
+	require.Equal(1.0, d)
+
+	// Zero-length segments, and single-vertex lines, behave as a vertex.
+	deg := types.NewSFLineStringXY([][2]float64{{1, 1}, {1, 1}})
+	p, d, err = deg.ClosestPoint(types.NewSFPointXY(4, 5))
+	require.NoError(err)
+	require.Equal(types.NewSFPointXY(1, 1), p)
+	require.Equal(5.0, d)
+	single := types.NewSFLineStringXY([][2]float64{{1, 1}})
+	p, _, err = single.ClosestPoint(types.NewSFPointXY(4, 5))
+	require.NoError(err)
+	require.Equal(types.NewSFPointXY(1, 1), p)
+
+	// The returned point has the SRID of l.
+	l.SetSRID(4326)
+	p, _, err = l.ClosestPoint(types.NewSFPointXY(4, 3))
+	require.NoError(err)
+	require.Equal(4326, p.SRID())
+
+	_, _, err = types.SFLineString{}.ClosestPoint(types.NewSFPointXY(1, 1))
+	require.Error(err)
+	_, _, err = l.ClosestPoint(types.SFPoint{})
+	require.Error(err)
+}
+
+func TestSFLineStringPoints(t *testing.T) {
+	require := require.New(t)
+
+	l := types.NewSFLineStringXYZ([][3]float64{{1, 2, 3}, {4, 5, 6}})
+	require.Equal(2, l.NumPoints())
+	require.Equal(types.NewSFPointXYZ(1, 2, 3), l.Point(0))
+	require.Equal(types.NewSFPointXYZ(4, 5, 6), l.Point(1))
+
+	// Points are copies.
+	p := l.Point(0)
+	p.FlatCoords()[0] = -1
+	require.Equal(types.NewSFPointXYZ(1, 2, 3), l.Point(0))
+
+	require.Panics(func() { l.Point(2) })
+	require.Panics(func() { l.Point(-1) })
+	require.Equal(0, types.SFLineString{}.NumPoints())
+	require.Panics(func() { types.SFLineString{}.Point(0) })
+}
+
+func TestSFLineStringIsNil(t *testing.T) {
+	require := require.New(t)
+
+	require.True(types.SFLineString{}.IsNil())
+	require.True(types.NewSFLineString(geom.LineString{}).IsNil())
+	require.True(types.NewSFLineStringXY(nil).IsNil())
+	require.False(types.NewSFLineStringXY(testLineStringPoints).IsNil())
+}
+
+func TestSFLineStringIsZero(t *testing.T) {
+	require := require.New(t)
+
+	require.True(types.SFLineString{}.IsZero())
+	require.True(types.NewSFLineStringXY([][2]float64{{0, 0}, {0, 0}}).IsZero())
+	require.False(types.NewSFLineStringXY(testLineStringPoints).IsZero())
+}
+
+func TestSFLineStringSQLValue(t *testing.T) {
+	require := require.New(t)
+
+	expected, err := wkb.Marshal(&testLineStringGoGeom, wkb.NDR)
+	require.NoError(err)
+	val, err := types.NewSFLineStringXY(testLineStringPoints).Value()
+	require.NoError(err)
+	require.Equal(driver.Value(expected), val)
+}
+
+func TestSFLineStringSQLScan(t *testing.T) {
+	require := require.New(t)
+
+	data, err := wkb.Marshal(&testLineStringGoGeom, wkb.NDR)
+	require.NoError(err)
+	var l types.SFLineString
+	err = l.Scan(data)
+	require.NoError(err)
+	require.Equal(types.NewSFLineStringXY(testLineStringPoints), l)
+
+	point, err := wkb.Marshal(geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2}), wkb.NDR)
+	require.NoError(err)
+	err = l.Scan(point)
+	require.EqualError(err, "types.SFLineString: expected LineString, got Point")
+	require.Equal(types.NewSFLineStringXY(testLineStringPoints), l)
+	err = l.Scan("LINESTRING(30 10,10 30,40 40)")
+	require.Error(err)
+}
+
+func TestSFLineStringMarshalJSON(t *testing.T) {
+	require := require.New(t)
+
+	l := types.NewSFLineStringXY(testLineStringPoints)
+	data, err := json.Marshal(l)
+	require.NoError(err)
+	require.EqualValues(testLineStringGeoJSON, data)
+	data, err = json.Marshal(&l)
+	require.NoError(err)
+	require.EqualValues(testLineStringGeoJSON, data)
+
+	_, err = json.Marshal(types.SFLineString{})
+	require.Error(err)
+}
+
+func TestSFLineStringUnmarshalJSON(t *testing.T) {
+	require := require.New(t)
+
+	var l types.SFLineString
+	err := json.Unmarshal(testLineStringGeoJSON, &l)
+	require.NoError(err)
+	require.Equal(types.NewSFLineStringXY(testLineStringPoints), l)
+
+	err = json.Unmarshal([]byte(`{"type":"Point","coordinates":[1,2]}`), &l)
+	require.Error(err)
+
+	err = json.Unmarshal([]byte(`null`), &l)
+	require.NoError(err)
+	require.True(l.IsNil())
+}
+
+func TestSFLineStringMarshalMapValue(t *testing.T) {
+	require := require.New(t)
+	type Wrapper struct{ Path types.SFLineString }
+
+	l := types.NewSFLineStringXY(testLineStringPoints)
+	data, err := maps.Marshal(Wrapper{l})
+	require.NoError(err)
+	require.Equal(map[string]interface{}{"Path": l}, data)
+}
+
+func TestSFLineStringString(t *testing.T) {
+	require := require.New(t)
+
+	require.Equal("LINESTRING (1 2, 3 4)", types.NewSFLineStringXY([][2]float64{{1, 2}, {3, 4}}).String())
+	require.Equal("LINESTRING EMPTY", types.NewSFLineString(*geom.NewLineString(geom.XY)).String())
+	require.Equal("<nil linestring>", types.SFLineString{}.String())
+}
+
+func TestMergeLineStrings(t *testing.T) {
+	require := require.New(t)
+
+	xy := types.NewSFLineStringXY
+	// Three segments of one route, out of order and direction, and a separate
+	// line.
+	lines := []types.SFLineString{
+		xy([][2]float64{{1, 0}, {2, 0}}),
+		xy([][2]float64{{3, 0}, {2, 0}}),
+		xy([][2]float64{{10, 10}, {11, 11}}),
+		xy([][2]float64{{0, 0}, {1, 0}}),
+		{},
+	}
+	original := append([]types.SFLineString(nil), lines...)
+	require.Equal([]types.SFLineString{
+		xy([][2]float64{{0, 0}, {1, 0}, {2, 0}, {3, 0}}),
+		xy([][2]float64{{10, 10}, {11, 11}}),
+	}, types.MergeLineStrings(lines))
+	require.Equal(original, lines)
+
+	// Lines aren't joined at junctions of three or more lines.
+	star := []types.SFLineString{
+		xy([][2]float64{{0, 0}, {1, 0}}),
+		xy([][2]float64{{0, 0}, {0, 1}}),
+		xy([][2]float64{{-1, 0}, {0, 0}}),
+		xy([][2]float64{{1, 0}, {2, 0}}),
+	}
+	require.Equal([]types.SFLineString{
+		xy([][2]float64{{0, 0}, {1, 0}, {2, 0}}),
+		xy([][2]float64{{0, 0}, {0, 1}}),
+		xy([][2]float64{{-1, 0}, {0, 0}}),
+	}, types.MergeLineStrings(star))
+
+	// Lines that join into a loop stop there.
+	loop := types.MergeLineStrings([]types.SFLineString{
+		xy([][2]float64{{0, 0}, {1, 0}}),
+		xy([][2]float64{{1, 1}, {0, 0}}),
+		xy([][2]float64{{1, 0}, {1, 1}}),
+	})
+	require.Equal([]types.SFLineString{
+		xy([][2]float64{{0, 0}, {1, 0}, {1, 1}, {0, 0}}),
+	}, loop)
+
+	// Endpoints must match exactly, in every component.
+	apart := []types.SFLineString{
+		xy([][2]float64{{0, 0}, {1, 0}}),
+		xy([][2]float64{{1.0000001, 0}, {2, 0}}),
+		types.NewSFLineStringXYZ([][3]float64{{1, 0, 0}, {2, 0, 0}}),
+	}
+	merged := types.MergeLineStrings(apart)
+	require.Equal(apart, merged)
+	merged[0].FlatCoords()[0] = 5
+	require.Equal(0.0, apart[0].FlatCoords()[0])
+
+	require.Empty(types.MergeLineStrings(nil))
+}
+
+func TestSFLineStringLength(t *testing.T) {
+	require := require.New(t)
+
+	l := types.NewSFLineStringXY([][2]float64{{0, 0}, {3, 4}, {3, 0}})
+	require.Equal(9.0, l.Length())
+	require.Equal(5.0, types.NewSFLineStringXYZ([][3]float64{{0, 0, 0}, {3, 4, 100}}).Length())
+	require.Equal(0.0, types.SFLineString{}.Length())
+
+	require.Equal(18.0, types.MultiLineStringLength([]types.SFLineString{l, {}, l}))
+	require.Equal(0.0, types.MultiLineStringLength(nil))
+}
@@ -3,11 +3,29 @@ package types
 import (
 	"bytes"
 	"database/sql/driver"
+	"encoding/binary"
+	"encoding/json"
 	"fmt"
+	"math"
+	"strings"
 
 	"github.com/twpayne/go-geom"
 	"github.com/twpayne/go-geom/encoding/geojson"
 	"github.com/twpayne/go-geom/encoding/wkb"
+	"github.com/twpayne/go-geom/encoding/wkbcommon"
+)
+
+const (
+	// geoHashAlphabet is the base32 alphabet used to encode geohashes.
+	geoHashAlphabet     = "0123456789bcdefghjkmnpqrstuvwxyz"
+	maxGeoHashPrecision = 12
+
+	// wkbXDR and wkbNDR are the WKB byte order markers for big- and
+	// little-endian data, and wkbPointID is the WKB geometry type of an XY
+	// Point.
+	wkbXDR     = 0
+	wkbNDR     = 1
+	wkbPointID = 1
 )
 
 // SFPoint is a Simple Feature Point, named for the OpenGIS specification that
@@ -19,7 +37,16 @@ import (
 // the pyrrho/encoding/types interfaces detailed in the package comments.
 // Database interactions (Value and Scan) will convert to and from a WKB (Well
 // Known Binary) representation. JSON interactions (MarshalJSON and
-// UnmarshalJSON) will convert to and from a GeoJSON representation.
+// UnmarshalJSON) will convert to and from a GeoJSON representation. Per
+// RFC 7946, GeoJSON coordinates are assumed to be WGS84 longitude and latitude;
+// the obsolete "crs" member is neither emitted nor consulted.
+//
+// A nil SFPoint -- the zero value, with no layout -- represents a missing
+// geometry. An empty SFPoint has a layout but no coordinates, and represents
+// the OGC "POINT EMPTY"; a geometry that is present, but describes no
+// location. See IsNil and IsEmpty. Empty SFPoints are encoded to WKB with NaN
+// coordinates, as PostGIS does, and to GeoJSON as a Point with an empty
+// "coordinates" array.
 type SFPoint struct {
 	geom.Point
 }
@@ -52,8 +79,148 @@ func NewSFPointXYZ(x float64, y float64, z float64) SFPoint {
 	return SFPoint{*p}
 }
 
+// NewSFPointFromXYArray constructs and returns a new SFPoint with longitude and
+// latitude components taken from the pair xy, as returned by XY.
+func NewSFPointFromXYArray(xy [2]float64) SFPoint {
+	return NewSFPointXY(xy[0], xy[1])
+}
+
+// NewSFPointEmpty constructs and returns a new, empty SFPoint with the given
+// layout. Empty SFPoints are distinct from nil SFPoints; see IsEmpty.
+func NewSFPointEmpty(l geom.Layout) SFPoint {
+	return SFPoint{*geom.NewPointEmpty(l)}
+}
+
+// NewSFPointFromGeoHash constructs and returns a new SFPoint with longitude
+// and latitude components at the center of the cell described by the given
+// geohash. An error will be returned if hash is empty or contains characters
+// outside of the geohash base32 alphabet.
+func NewSFPointFromGeoHash(hash string) (SFPoint, error) {
+	if len(hash) == 0 {
+		return SFPoint{}, fmt.Errorf("types.SFPoint: cannot decode an empty geohash")
+	}
+	minLng, maxLng := -180.0, 180.0
+	minLat, maxLat := -90.0, 90.0
+	even := true
+	for i := 0; i < len(hash); i++ {
+		idx := strings.IndexByte(geoHashAlphabet, hash[i])
+		if idx < 0 {
+			return SFPoint{}, fmt.Errorf("types.SFPoint: invalid geohash character %q at position %d", hash[i], i)
+		}
+		for bit := 4; bit >= 0; bit-- {
+			set := (idx>>uint(bit))&1 == 1
+			if even {
+				mid := (minLng + maxLng) / 2
+				if set {
+					minLng = mid
+				} else {
+					maxLng = mid
+				}
+			} else {
+				mid := (minLat + maxLat) / 2
+				if set {
+					minLat = mid
+				} else {
+					maxLat = mid
+				}
+			}
+			even = !even
+		}
+	}
+	return NewSFPointXY((minLng+maxLng)/2, (minLat+maxLat)/2), nil
+}
+
+// DecodeWKBPoints constructs and returns a new SFPoint for each of the WKB
+// encoded points in blobs, as Scan would. It's intended for bulk imports; the
+// coordinates of all of the returned SFPoints share a single allocation, and
+// each blob is decoded directly, rather than through go-geom's general purpose
+// WKB decoder.
+//
+// An error naming the index of the offending blob will be returned if any blob
+// can't be decoded, or doesn't contain a Point.
+func DecodeWKBPoints(blobs [][]byte) ([]SFPoint, error) {
+	n := 0
+	for _, b := range blobs {
+		if len(b) > 5 {
+			n += (len(b) - 5) / 8
+		}
+	}
+	flat := make([]float64, 0, n)
+	ret := make([]SFPoint, len(blobs))
+	for i, b := range blobs {
+		var ok bool
+		ret[i], flat, ok = decodeISOWKBPoint(b, flat)
+		if ok {
+			continue
+		}
+		if err := ret[i].Scan(b); err != nil {
+			return nil, fmt.Errorf("types.DecodeWKBPoints: blob %d: %v", i, err)
+		}
+	}
+	return ret, nil
+}
+
+// decodeISOWKBPoint decodes the ISO WKB point b, appending its coordinates to
+// flat. ok will be false if b is anything other than a well formed ISO WKB
+// point, in which case flat is returned unmodified.
+func decodeISOWKBPoint(b []byte, flat []float64) (p SFPoint, ret []float64, ok bool) {
+	if len(b) < 5 {
+		return SFPoint{}, flat, false
+	}
+	var order binary.ByteOrder
+	switch b[0] {
+	case wkbXDR:
+		order = binary.BigEndian
+	case wkbNDR:
+		order = binary.LittleEndian
+	default:
+		return SFPoint{}, flat, false
+	}
+	var layout geom.Layout
+	switch order.Uint32(b[1:5]) {
+	case wkbPointID:
+		layout = geom.XY
+	case wkbPointID + 1000:
+		layout = geom.XYZ
+	case wkbPointID + 2000:
+		layout = geom.XYM
+	case wkbPointID + 3000:
+		layout = geom.XYZM
+	default:
+		return SFPoint{}, flat, false
+	}
+	stride := layout.Stride()
+	if len(b) != 5+8*stride {
+		return SFPoint{}, flat, false
+	}
+	start, empty := len(flat), true
+	for i := 0; i < stride; i++ {
+		c := math.Float64frombits(order.Uint64(b[5+8*i:]))
+		empty = empty && math.IsNaN(c)
+		flat = append(flat, c)
+	}
+	if empty {
+		return NewSFPointEmpty(layout), flat[:start], true
+	}
+	return SFPoint{*geom.NewPointFlat(layout, flat[start:len(flat):len(flat)])}, flat, true
+}
+
 // Getters
 
+// GeometryType returns the GeoJSON type name of p, "Point".
+func (p SFPoint) GeometryType() string {
+	return "Point"
+}
+
+// BBox returns the degenerate bounding box containing only p. An empty BBox
+// will be returned if p is nil or empty.
+func (p SFPoint) BBox() BBox {
+	if p.IsNil() {
+		return NewBBoxEmpty()
+	}
+	return bboxOf(p.FlatCoords(), p.Stride())
+}
+
 // Lng returns the longitude (northing, first) component of this SFPoint.
 func (p SFPoint) Lng() float64 {
 	return p.X()
@@ -70,22 +237,291 @@ func (p SFPoint) Alt() float64 {
 	return p.Z()
 }
 
+// XY returns the longitude and latitude components of p as a pair, for
+// interoperating with libraries that represent points that way. Any altitude
+// component is dropped. [NaN, NaN] will be returned if p is nil or empty, as
+// it would be encoded to WKB.
+func (p SFPoint) XY() [2]float64 {
+	if len(p.FlatCoords()) == 0 {
+		return [2]float64{math.NaN(), math.NaN()}
+	}
+	return [2]float64{p.X(), p.Y()}
+}
+
+// GeoHash returns the geohash of the cell containing p, with the given number
+// of characters of precision. Precision will be clamped to the range [1, 12];
+// at 12 characters a cell is smaller than a few centimeters across. An empty
+// string will be returned if p is nil or empty.
+func (p SFPoint) GeoHash(precision int) string {
+	if len(p.FlatCoords()) == 0 {
+		return ""
+	}
+	if precision < 1 {
+		precision = 1
+	} else if precision > maxGeoHashPrecision {
+		precision = maxGeoHashPrecision
+	}
+	lng, lat := p.X(), p.Y()
+	minLng, maxLng := -180.0, 180.0
+	minLat, maxLat := -90.0, 90.0
+	even := true
+	ret := make([]byte, precision)
+	for i := range ret {
+		idx := 0
+		for bit := 4; bit >= 0; bit-- {
+			if even {
+				mid := (minLng + maxLng) / 2
+				if lng >= mid {
+					idx |= 1 << uint(bit)
+					minLng = mid
+				} else {
+					maxLng = mid
+				}
+			} else {
+				mid := (minLat + maxLat) / 2
+				if lat >= mid {
+					idx |= 1 << uint(bit)
+					minLat = mid
+				} else {
+					maxLat = mid
+				}
+			}
+			even = !even
+		}
+		ret[i] = geoHashAlphabet[idx]
+	}
+	return string(ret)
+}
+
+// Destination returns the point reached by traveling distanceMeters from p,
+// along the great circle that leaves p on an initial bearing of bearingDegrees,
+// measured clockwise from true north. Negative distances travel in the opposite
+// direction. Any altitude (or other) components of p are copied into the
+// returned SFPoint, as are its layout and SRID, and its longitude is normalized
+// to the range [-180, 180].
+//
+// As with NewSFCircle, the Earth is modeled as a sphere with the IUGG mean
+// radius of 6,371,008.8 meters, so results may differ from those computed on
+// the WGS84 ellipsoid by up to about 0.5%. The coordinates of p are assumed to
+// be EPSG:4326 longitude and latitude, in degrees. A copy of p will be returned
+// if it is nil or empty.
+func (p SFPoint) Destination(bearingDegrees, distanceMeters float64) SFPoint {
+	coords := append([]float64(nil), p.FlatCoords()...)
+	if len(coords) == 0 {
+		if p.IsNil() {
+			return SFPoint{}
+		}
+		return SFPoint{*geom.NewPointEmpty(p.Layout()).SetSRID(p.SRID())}
+	}
+	lng1 := coords[0] * math.Pi / 180
+	lat1 := coords[1] * math.Pi / 180
+	bearing := bearingDegrees * math.Pi / 180
+	d := distanceMeters / earthMeanRadius
+
+	lat2 := math.Asin(math.Sin(lat1)*math.Cos(d) + math.Cos(lat1)*math.Sin(d)*math.Cos(bearing))
+	lng2 := lng1 + math.Atan2(
+		math.Sin(bearing)*math.Sin(d)*math.Cos(lat1),
+		math.Cos(d)-math.Sin(lat1)*math.Sin(lat2))
+	coords[0] = math.Mod(lng2*180/math.Pi+540, 360) - 180
+	coords[1] = lat2 * 180 / math.Pi
+	return SFPoint{*geom.NewPointFlat(p.Layout(), coords).SetSRID(p.SRID())}
+}
+
+// InitialBearing returns the bearing, in degrees clockwise from true north in
+// the range [0, 360), on which the great circle path from p to o begins. The
+// bearing will generally change along the path; the final bearing of the path
+// from p to o is the reverse of o.InitialBearing(p). As with Destination, the
+// Earth is modeled as a sphere, and the coordinates of p and o are assumed to
+// be EPSG:4326 longitude and latitude, in degrees.
+//
+// The bearing between coincident points is 0, and NaN will be returned if
+// either p or o is nil or empty.
+func (p SFPoint) InitialBearing(o SFPoint) float64 {
+	if len(p.FlatCoords()) == 0 || len(o.FlatCoords()) == 0 {
+		return math.NaN()
+	}
+	lat1 := p.Lat() * math.Pi / 180
+	lat2 := o.Lat() * math.Pi / 180
+	dLng := (o.Lng() - p.Lng()) * math.Pi / 180
+
+	y := math.Sin(dLng) * math.Cos(lat2)
+	x := math.Cos(lat1)*math.Sin(lat2) - math.Sin(lat1)*math.Cos(lat2)*math.Cos(dLng)
+	return math.Mod(math.Atan2(y, x)*180/math.Pi+360, 360)
+}
+
+// pointTransformer is the function TransformEPSG uses to reproject SFPoints,
+// as set by SetPointTransformer.
+var pointTransformer func(p SFPoint, from, to int) (SFPoint, error)
+
+// SetPointTransformer sets the function used by SFPoint.TransformEPSG to
+// reproject a point from the spatial reference system identified by the EPSG
+// code from to the one identified by to. It's called by the init function of
+// pyrrho/encoding/types/proj, which implements the transforms, and can't be
+// imported here without an import cycle. Programs need only import that package
+// -- for its side effects, if nothing else -- to enable TransformEPSG.
+func SetPointTransformer(fn func(p SFPoint, from, to int) (SFPoint, error)) {
+	pointTransformer = fn
+}
+
+// TransformEPSG returns a copy of p reprojected from the spatial reference
+// system identified by its SRID to the one identified by the EPSG code to, with
+// its SRID set to to. See the pyrrho/encoding/types/proj package for the
+// available transforms. An error will be returned if p has no SRID, if no
+// transform is available, or if that package hasn't been imported.
+func (p SFPoint) TransformEPSG(to int) (SFPoint, error) {
+	if p.SRID() == 0 {
+		return SFPoint{}, fmt.Errorf("types.SFPoint: cannot transform an SFPoint with no SRID")
+	}
+	if pointTransformer == nil {
+		return SFPoint{}, fmt.Errorf("types.SFPoint: no point transformer is set; import pyrrho/encoding/types/proj")
+	}
+	return pointTransformer(p, p.SRID(), to)
+}
+
+// AppendWKB appends the little-endian (NDR) WKB encoded representation of p to
+// dst, and returns the extended buffer. This produces the same bytes as Value,
+// but allows callers that encode many SFPoints -- eg. when bulk inserting rows
+// -- to reuse a single buffer. Empty SFPoints are encoded with NaN coordinates.
+// An error will be returned, and dst will be returned unmodified, if p is nil.
+func (p SFPoint) AppendWKB(dst []byte) ([]byte, error) {
+	var typ uint32
+	switch p.Layout() {
+	case geom.XY:
+		typ = wkbPointID
+	case geom.XYZ:
+		typ = wkbPointID + 1000
+	case geom.XYM:
+		typ = wkbPointID + 2000
+	case geom.XYZM:
+		typ = wkbPointID + 3000
+	default:
+		return dst, fmt.Errorf("types.SFPoint: cannot encode a Point with layout %v as WKB", p.Layout())
+	}
+	var scratch [8]byte
+	dst = append(dst, wkbNDR)
+	binary.LittleEndian.PutUint32(scratch[:4], typ)
+	dst = append(dst, scratch[:4]...)
+	if p.IsEmpty() {
+		binary.LittleEndian.PutUint64(scratch[:], geom.PointEmptyCoordHex)
+		for i := 0; i < p.Stride(); i++ {
+			dst = append(dst, scratch[:]...)
+		}
+		return dst, nil
+	}
+	for _, c := range p.FlatCoords() {
+		binary.LittleEndian.PutUint64(scratch[:], math.Float64bits(c))
+		dst = append(dst, scratch[:]...)
+	}
+	return dst, nil
+}
+
+// MarshalTWKB returns the TWKB (Tiny Well-Known Binary) encoded representation
+// of p, with its longitude and latitude rounded to precision decimal places.
+// precision must be in [-8, 7]; negative values round to tens, hundreds, etc.
+// Altitudes and measures are rounded to precision places, clamped to [0, 7].
+// Empty SFPoints are encoded as empty TWKB Points. If p is nil, an error will
+// be returned.
+func (p SFPoint) MarshalTWKB(precision int) ([]byte, error) {
+	if p.IsNil() {
+		return nil, fmt.Errorf("types.SFPoint: cannot encode an uninitialized SFPoint as TWKB")
+	}
+	b, err := appendTWKB(nil, twkbPoint, p.Layout(), p.FlatCoords(), precision)
+	if err != nil {
+		return nil, fmt.Errorf("types.SFPoint: %v", err)
+	}
+	return b, nil
+}
+
+// UnmarshalTWKB decodes the TWKB encoded Point data into p, at whatever
+// precision it was encoded with. If data is not a well formed TWKB Point, an
+// error will be returned, and p will be unchanged.
+func (p *SFPoint) UnmarshalTWKB(data []byte) error {
+	if p == nil {
+		return fmt.Errorf("types.SFPoint: UnmarshalTWKB called on nil pointer")
+	}
+	layout, flat, err := decodeTWKB(data, twkbPoint)
+	if err != nil {
+		return fmt.Errorf("types.SFPoint: %v", err)
+	}
+	if len(flat) == 0 {
+		*p = NewSFPointEmpty(layout)
+		return nil
+	}
+	*p = SFPoint{*geom.NewPointFlat(layout, flat)}
+	return nil
+}
+
+// Setters
+
+// SetCoords overwrites the coordinates of p in place with c, which must have
+// exactly as many components as p's layout -- two for an XY SFPoint, three for
+// an XYZ SFPoint, etc. An error will be returned, and p will be unmodified, if
+// p is nil or empty, or if c doesn't match p's layout.
+//
+// No allocation is made; the existing coordinate storage is reused. SFPoints
+// copied by value share that storage, so copies will observe the change. Use
+// NewSFPoint(*p.Clone()) to make an independent copy first if that's
+// undesirable.
+func (p *SFPoint) SetCoords(c geom.Coord) error {
+	if p == nil {
+		return fmt.Errorf("types.SFPoint: SetCoords called on nil pointer")
+	}
+	if len(p.FlatCoords()) == 0 {
+		return fmt.Errorf("types.SFPoint: cannot set the coordinates of a nil or empty SFPoint")
+	}
+	if len(c) != p.Stride() {
+		return fmt.Errorf("types.SFPoint: cannot set %d coordinates on a Point with layout %v", len(c), p.Layout())
+	}
+	copy(p.FlatCoords(), c)
+	return nil
+}
+
+// SetXY overwrites the longitude and latitude components of p in place, leaving
+// any other components untouched. If p is nil or empty, it will become a new XY
+// SFPoint. As with SetCoords, SFPoints copied by value from p will observe the
+// change.
+func (p *SFPoint) SetXY(x float64, y float64) {
+	if len(p.FlatCoords()) == 0 {
+		*p = NewSFPointXY(x, y)
+		return
+	}
+	flat := p.FlatCoords()
+	flat[0], flat[1] = x, y
+}
+
+// MarshalKML returns the KML <Point> element describing p; eg.
+// <Point><coordinates>-122.08,37.42,12</coordinates></Point>. The altitude is
+// included if p has a Z component. An error will be returned if p is nil or
+// empty, neither of which KML can represent.
+func (p SFPoint) MarshalKML() ([]byte, error) {
+	if p.IsNil() || p.IsEmpty() {
+		return nil, fmt.Errorf("types.SFPoint: cannot encode a nil or empty SFPoint as KML")
+	}
+	return appendKMLElement(nil, "Point", p.Layout(), p.FlatCoords()), nil
+}
+
 // Interfaces
 
 // IsNil implements the pyrrho/encoding IsNiler interface. It will return true
 // if p contains no meaningful data. More specifically, if this if this SFPoint
 // has been zero-initialized, or if it has been explicitly initialized with no
-// layout;
+// layout. Empty SFPoints are not nil;
 //   var p types.SFPoint
 //   var p := types.SFPoint{}
 //   var p := types.NewSFPoint(geom.Point{geom.NoLayout})
 func (p SFPoint) IsNil() bool {
-	// return p.FlatCoords() == nil || p.Layout() == geom.NoLayout
-	return len(p.FlatCoords()) == 0
+	return p.Layout() == geom.NoLayout
+}
+
+// IsEmpty returns true if p is an empty SFPoint -- the OGC "POINT EMPTY" --
+// with a layout, but no coordinates. Nil SFPoints are not empty.
+func (p SFPoint) IsEmpty() bool {
+	return !p.IsNil() && len(p.FlatCoords()) == 0
 }
 
 // IsZero implements the pyrrho/encoding IsZeroer interface. It will return true
-// if p.IsNil() returns true, or if the contained data is of the zero-value.
+// if p.IsNil() or p.IsEmpty() returns true, or if the contained data is of the
+// zero-value.
 func (p SFPoint) IsZero() bool {
 	for _, f := range p.FlatCoords() {
 		if f != 0.0 {
@@ -95,66 +531,192 @@ func (p SFPoint) IsZero() bool {
 	return true
 }
 
+// String implements the fmt Stringer interface. It will return the WKT (Well
+// Known Text) representation of p -- eg. "POINT (1.2 2.3)" -- "POINT EMPTY" if
+// p is empty, or "<nil point>" if p is nil.
+func (p SFPoint) String() string {
+	return wktString(&p.Point, "point")
+}
+
 // Value implements the database/sql/driver Valuer interface. It will return the
 // value of p as a driver.Value; specifically a WKB encoded []byte.
 func (p SFPoint) Value() (driver.Value, error) {
-	b := &bytes.Buffer{}
-	if err := wkb.Write(b, wkb.NDR, &p.Point); err != nil {
+	b, err := p.AppendWKB(make([]byte, 0, 5+8*len(p.FlatCoords())))
+	if err != nil {
 		return nil, err
 	}
-	return b.Bytes(), nil
+	return b, nil
 }
 
 // Scan implements the database/sql Scanner interface. It expects to receive a
 // WKB encoded []byte describing a Point from an SQL database, and will assign
-// that value to p. If the incoming []byte is not a well formed WKB, or if that
-// WKB value does not describe a Point, an error will be returned.
+// that value to p. Points with all-NaN coordinates will be scanned as empty
+// SFPoints. The WKB may also be passed as a string, a *[]byte, or a
+// driver.Valuer that produces either. If the incoming []byte is not a well
+// formed WKB, or if that WKB value does not describe a Point, an error will be
+// returned.
 func (p *SFPoint) Scan(src interface{}) error {
 	if p == nil {
 		return fmt.Errorf("types.SFPoint: Scan called on nil SFLpointer")
 	}
-	b, ok := src.([]byte)
-	if !ok {
-		return fmt.Errorf("types.SFPoint: cannot scan type %T (%v)", src, src)
+	b, err := scanBytes(src)
+	if err != nil {
+		return fmt.Errorf("types.SFPoint: %v", err)
 	}
-	g, err := wkb.Unmarshal(b)
+	if err := checkWKBGeometryType(b, "Point"); err != nil {
+		return fmt.Errorf("types.SFPoint: %v", err)
+	}
+	g, err := wkb.Unmarshal(b, wkbcommon.WKBOptionEmptyPointHandling(wkbcommon.EmptyPointHandlingNaN))
 	if err != nil {
 		return err
 	}
 	t, ok := g.(*geom.Point)
 	if !ok {
-		return fmt.Errorf("types.SFPoint: scan did not return a *geom.Point (got a %T)", t)
+		return fmt.Errorf("types.SFPoint: scan did not return a *geom.Point (got a %T)", g)
 	}
 	p.Point.Swap(t)
 	return nil
 }
 
 // MarshalJSON implements the encoding/json Marshaler interface. It will return
-// the GeoJSON encoded representation of p.
+// the GeoJSON encoded representation of p. Empty SFPoints will be encoded as a
+// Point with an empty "coordinates" array. If p is nil, an error will be
+// returned, or 'null' if EmptyGeometryAsNull is true.
 func (p SFPoint) MarshalJSON() ([]byte, error) {
 	if p.IsNil() {
+		if EmptyGeometryAsNull {
+			return []byte("null"), nil
+		}
 		return nil, fmt.Errorf("types.SFPoint: cannot unmarshal an uninitialized SFPoint")
 	}
-	return geojson.Marshal(&p.Point)
+	if p.IsEmpty() {
+		return []byte(`{"type":"Point","coordinates":[]}`), nil
+	}
+	return marshalGeoJSON(&p.Point)
 }
 
 // UnmarshalJSON implements the encoding/json Unmarshaler interface. It expects
 // to receive a valid GeoJSON Geometry of the type Point, and will assign
-// the value of that data to p.
+// the value of that data to p. A Point with an empty or missing "coordinates"
+// member will decode into an empty XY SFPoint. If AcceptBareCoordinates is
+// true, a bare array of two or three numbers will also be accepted. A leading
+// UTF-8 byte order mark, and surrounding whitespace, are ignored.
+//
+// Null coordinates are an error, unless TolerantCoordinateNull is true and the
+// null is the third (altitude) coordinate, in which case an XY SFPoint will be
+// decoded. A numeric third coordinate always decodes into an XYZ SFPoint.
 func (p *SFPoint) UnmarshalJSON(data []byte) error {
 	if p == nil {
 		return fmt.Errorf("types.SFPoint: UnmarshalJSON called on nil SFLpointer")
 	}
+	data = trimGeoJSON(data)
+	if AcceptBareCoordinates {
+		if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+			return p.unmarshalBareCoordinates(trimmed)
+		}
+	}
+	if bytes.Contains(data, []byte("null")) {
+		// encoding/json silently decodes null coordinates as 0, so look for
+		// them before handing data off to geojson.
+		var obj struct {
+			Type        string
+			Coordinates []*float64
+		}
+		if json.Unmarshal(data, &obj) == nil && obj.Type == "Point" && hasNil(obj.Coordinates) {
+			np, err := pointFromCoordinates(obj.Coordinates)
+			if err != nil {
+				return err
+			}
+			*p = np
+			return nil
+		}
+	}
 	var gt geom.T
 	if err := geojson.Unmarshal(data, &gt); err != nil {
 		return err
 	}
-	p.Point.Swap(gt.(*geom.Point))
+	if gt == nil {
+		// The 'null' keyword decodes into a nil Point.
+		p.Point = geom.Point{}
+		return nil
+	}
+	t, ok := gt.(*geom.Point)
+	if !ok {
+		return fmt.Errorf("types.SFPoint: cannot unmarshal a GeoJSON %T", gt)
+	}
+	if t.Layout() == geom.NoLayout {
+		// A Point without "coordinates" is empty, not missing.
+		t = geom.NewPointEmpty(geom.XY)
+	}
+	if err := checkGeoJSONBBox(data, t); err != nil {
+		return fmt.Errorf("types.SFPoint: %v", err)
+	}
+	p.Point.Swap(t)
+	return nil
+}
+
+// unmarshalBareCoordinates assigns the [lng, lat] or [lng, lat, alt] JSON
+// array data to p.
+func (p *SFPoint) unmarshalBareCoordinates(data []byte) error {
+	var raw []*float64
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("types.SFPoint: cannot unmarshal bare coordinates: %v", err)
+	}
+	np, err := pointFromCoordinates(raw)
+	if err != nil {
+		return err
+	}
+	*p = np
 	return nil
 }
 
+// TolerantCoordinateNull controls how SFPoint.UnmarshalJSON handles a null
+// third (altitude) coordinate -- eg. [lng, lat, null] -- as sent by producers
+// that don't know a point's altitude. By default, null coordinates are an
+// error. When TolerantCoordinateNull is true, a null altitude will be dropped,
+// and an XY SFPoint decoded. Null longitudes and latitudes are always an error.
+var TolerantCoordinateNull = false
+
+// pointFromCoordinates constructs and returns a new XY or XYZ SFPoint from the
+// two or three JSON decoded coordinates raw, in which nulls are nil.
+func pointFromCoordinates(raw []*float64) (SFPoint, error) {
+	if len(raw) == 3 && raw[2] == nil && TolerantCoordinateNull {
+		raw = raw[:2]
+	}
+	coords := make([]float64, len(raw))
+	for i, c := range raw {
+		if c == nil {
+			return SFPoint{}, fmt.Errorf("types.SFPoint: coordinate %d is null", i)
+		}
+		coords[i] = *c
+	}
+	switch len(coords) {
+	case 2:
+		return NewSFPointXY(coords[0], coords[1]), nil
+	case 3:
+		return NewSFPointXYZ(coords[0], coords[1], coords[2]), nil
+	default:
+		return SFPoint{}, fmt.Errorf("types.SFPoint: cannot unmarshal %d coordinates; expected 2 or 3", len(coords))
+	}
+}
+
+// hasNil returns true if any element of ps is nil.
+func hasNil(ps []*float64) bool {
+	for _, p := range ps {
+		if p == nil {
+			return true
+		}
+	}
+	return false
+}
+
 // MarshalMapValue implements the pyrrho/encoding/maps Marshaler interface. It
-// will return p wrapped in an interface{} for use in a map[string]interface{}.
+// will return p wrapped in an interface{} for use in a map[string]interface{},
+// or p's GeoJSON representation as a map[string]interface{} if
+// MapValueAsGeoJSON is true.
 func (p SFPoint) MarshalMapValue() (interface{}, error) {
+	if MapValueAsGeoJSON {
+		return geoJSONMapValue(p)
+	}
 	return p, nil
 }
@@ -2,11 +2,15 @@ package types_test
 
 import (
 	"database/sql/driver"
+	"encoding/binary"
 	"encoding/json"
+	"fmt"
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/wkb"
 
 	"github.com/pyrrho/encoding/maps"
 	"github.com/pyrrho/encoding/types"
@@ -63,6 +67,62 @@ func TestSFPointIsNil(t *testing.T) {
 	require.True(empty.IsNil())
 }
 
+func TestSFPointEmpty(t *testing.T) {
+	require := require.New(t)
+
+	empty := types.NewSFPointEmpty(geom.XY)
+	require.True(empty.IsEmpty())
+	require.False(empty.IsNil())
+	require.True(empty.IsZero())
+	require.False(types.SFPoint{}.IsEmpty())
+	require.False(types.NewSFPointXY(0, 0).IsEmpty())
+
+	// POINT EMPTY is encoded to WKB with NaN coordinates, as PostGIS does.
+	emptyWKB := []byte{
+		0x01, 0x01, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xf8, 0x7f,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xf8, 0x7f,
+	}
+	v, err := empty.Value()
+	require.NoError(err)
+	require.Equal(driver.Value(emptyWKB), v)
+	var scanned types.SFPoint
+	require.NoError(scanned.Scan(emptyWKB))
+	require.True(scanned.IsEmpty())
+	require.Equal(geom.XY, scanned.Layout())
+
+	// XYZ empty points keep their layout through WKB.
+	v, err = types.NewSFPointEmpty(geom.XYZ).Value()
+	require.NoError(err)
+	require.Len(v, 5+3*8)
+	require.NoError(scanned.Scan(v))
+	require.True(scanned.IsEmpty())
+	require.Equal(geom.XYZ, scanned.Layout())
+
+	// GeoJSON represents POINT EMPTY with an empty coordinates array.
+	data, err := json.Marshal(empty)
+	require.NoError(err)
+	require.Equal(`{"type":"Point","coordinates":[]}`, string(data))
+	scanned = types.NewSFPointXY(1, 2)
+	require.NoError(json.Unmarshal(data, &scanned))
+	require.True(scanned.IsEmpty())
+	scanned = types.NewSFPointXY(1, 2)
+	require.NoError(json.Unmarshal([]byte(`{"type":"Point"}`), &scanned))
+	require.True(scanned.IsEmpty())
+
+	// ... while nil points remain missing geometries.
+	_, err = json.Marshal(types.SFPoint{})
+	require.Error(err)
+	require.NoError(json.Unmarshal([]byte(`null`), &scanned))
+	require.True(scanned.IsNil())
+
+	// Empty points have no location.
+	require.Equal("", empty.GeoHash(5))
+	require.Error(empty.SetCoords(geom.Coord{1, 2}))
+	empty.SetXY(1, 2)
+	require.Equal(types.NewSFPointXY(1, 2), empty)
+}
+
 func TestSFPointIsZero(t *testing.T) {
 	require := require.New(t)
 
@@ -76,6 +136,53 @@ func TestSFPointIsZero(t *testing.T) {
 	require.True(empty.IsZero())
 }
 
+func TestSFPointSetCoords(t *testing.T) {
+	require := require.New(t)
+
+	p := types.NewSFPointXY(1, 2)
+	require.NoError(p.SetCoords(geom.Coord{3, 4}))
+	require.Equal(types.NewSFPointXY(3, 4), p)
+	require.NoError(p.SetCoords(geom.Coord{0, 0}))
+	require.False(p.IsNil())
+	require.True(p.IsZero())
+
+	// The coordinates must match the point's layout.
+	require.Error(p.SetCoords(geom.Coord{1, 2, 3}))
+	require.Error(p.SetCoords(geom.Coord{1}))
+	require.Equal(types.NewSFPointXY(0, 0), p)
+
+	p = types.NewSFPointXYZ(1, 2, 3)
+	require.NoError(p.SetCoords(geom.Coord{4, 5, 6}))
+	require.Equal(types.NewSFPointXYZ(4, 5, 6), p)
+	require.Error(p.SetCoords(geom.Coord{1, 2}))
+
+	var empty types.SFPoint
+	require.Error(empty.SetCoords(geom.Coord{1, 2}))
+	require.True(empty.IsNil())
+
+	// Updates happen in place.
+	p = types.NewSFPointXY(1, 2)
+	require.Zero(testing.AllocsPerRun(100, func() { _ = p.SetCoords(geom.Coord{5, 6}) }))
+}
+
+func TestSFPointSetXY(t *testing.T) {
+	require := require.New(t)
+
+	p := types.NewSFPointXYZ(1, 2, 3)
+	p.SetXY(4, 5)
+	require.Equal(types.NewSFPointXYZ(4, 5, 3), p)
+
+	var empty types.SFPoint
+	empty.SetXY(1, 2)
+	require.Equal(types.NewSFPointXY(1, 2), empty)
+	require.False(empty.IsNil())
+
+	p = types.NewSFPointXY(1, 2)
+	require.Zero(testing.AllocsPerRun(100, func() { p.SetXY(3, 4) }))
+	require.Equal(3.0, p.Lng())
+	require.Equal(4.0, p.Lat())
+}
+
 func TestSFPointSQLValue(t *testing.T) {
 	require := require.New(t)
 	var val driver.Value
@@ -87,6 +194,139 @@ func TestSFPointSQLValue(t *testing.T) {
 	require.EqualValues(testPointWKB, val)
 }
 
+func TestSFPointAppendWKB(t *testing.T) {
+	require := require.New(t)
+	var data []byte
+	var err error
+
+	p := types.NewSFPointXY(1.2, 2.3)
+	data, err = p.AppendWKB(nil)
+	require.NoError(err)
+	require.Equal(testPointWKB, data)
+
+	// The WKB is appended to any existing data.
+	data, err = p.AppendWKB([]byte{0xff})
+	require.NoError(err)
+	require.Equal(append([]byte{0xff}, testPointWKB...), data)
+
+	// Other layouts match go-geom's WKB encoder.
+	for _, g := range []*geom.Point{
+		geom.NewPoint(geom.XYZ).MustSetCoords(geom.Coord{1, 2, 3}),
+		geom.NewPoint(geom.XYM).MustSetCoords(geom.Coord{1, 2, 3}),
+		geom.NewPoint(geom.XYZM).MustSetCoords(geom.Coord{1, 2, 3, 4}),
+	} {
+		expected, err := wkb.Marshal(g, wkb.NDR)
+		require.NoError(err)
+		data, err = types.NewSFPoint(*g).AppendWKB(nil)
+		require.NoError(err)
+		require.Equal(expected, data)
+	}
+
+	data, err = types.SFPoint{}.AppendWKB([]byte{0xff})
+	require.Error(err)
+	require.Equal([]byte{0xff}, data)
+}
+
+func BenchmarkSFPointValue(b *testing.B) {
+	p := types.NewSFPointXY(1.2, 2.3)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := p.Value(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSFPointAppendWKB(b *testing.B) {
+	p := types.NewSFPointXY(1.2, 2.3)
+	buf := make([]byte, 0, 64)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var err error
+		buf, err = p.AppendWKB(buf[:0])
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestDecodeWKBPoints(t *testing.T) {
+	require := require.New(t)
+
+	var blobs [][]byte
+	for _, g := range []*geom.Point{
+		geom.NewPointFlat(geom.XY, []float64{1.2, 2.3}),
+		geom.NewPointFlat(geom.XYZ, []float64{1.2, 2.3, 3.4}),
+		geom.NewPointFlat(geom.XYM, []float64{1.2, 2.3, 4.5}),
+		geom.NewPointFlat(geom.XYZM, []float64{1.2, 2.3, 3.4, 4.5}),
+	} {
+		for _, order := range []binary.ByteOrder{wkb.NDR, wkb.XDR} {
+			b, err := wkb.Marshal(g, order)
+			require.NoError(err)
+			blobs = append(blobs, b)
+		}
+	}
+	empty, err := types.NewSFPointEmpty(geom.XYZ).Value()
+	require.NoError(err)
+	blobs = append(blobs, empty.([]byte))
+
+	// Each point matches what Scan would produce.
+	points, err := types.DecodeWKBPoints(blobs)
+	require.NoError(err)
+	require.Len(points, len(blobs))
+	for i, b := range blobs {
+		var expected types.SFPoint
+		require.NoError(expected.Scan(b))
+		require.Equal(expected, points[i], "blob %d", i)
+	}
+	require.True(points[len(points)-1].IsEmpty())
+
+	// Points don't share coordinate storage.
+	require.NoError(points[0].SetCoords(geom.Coord{9, 9}))
+	require.Equal([]float64{1.2, 2.3}, points[1].FlatCoords())
+
+	points, err = types.DecodeWKBPoints(nil)
+	require.NoError(err)
+	require.Empty(points)
+
+	line, err := wkb.Marshal(geom.NewLineStringFlat(geom.XY, []float64{1, 2, 3, 4}), wkb.NDR)
+	require.NoError(err)
+	for _, bad := range [][]byte{line, testPointWKB[:10], nil} {
+		_, err = types.DecodeWKBPoints([][]byte{testPointWKB, bad})
+		require.Error(err)
+		require.Contains(err.Error(), "blob 1")
+	}
+}
+
+func BenchmarkDecodeWKBPoints(b *testing.B) {
+	blobs := make([][]byte, 1000)
+	for i := range blobs {
+		blobs[i] = testPointWKB
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := types.DecodeWKBPoints(blobs); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSFPointScanMany(b *testing.B) {
+	blobs := make([][]byte, 1000)
+	for i := range blobs {
+		blobs[i] = testPointWKB
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		points := make([]types.SFPoint, len(blobs))
+		for j, blob := range blobs {
+			if err := points[j].Scan(blob); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
 func TestSFPointSQLScan(t *testing.T) {
 	require := require.New(t)
 	var err error
@@ -99,6 +339,45 @@ func TestSFPointSQLScan(t *testing.T) {
 	var bad types.SFPoint
 	err = bad.Scan(driver.Value(nil))
 	require.Error(err)
+
+	// The WKB must describe a Point.
+	err = p.Scan(driver.Value(testPolygonWKB))
+	require.EqualError(err, "types.SFPoint: expected Point, got Polygon")
+	require.Equal([]float64{1.2, 2.3}, p.FlatCoords())
+}
+
+type wkbValuer []byte
+
+func (v wkbValuer) Value() (driver.Value, error) {
+	return []byte(v), nil
+}
+
+func TestSFPointSQLScanWrappers(t *testing.T) {
+	require := require.New(t)
+
+	b := append([]byte(nil), testPointWKB...)
+	for _, src := range []interface{}{
+		&b,
+		string(testPointWKB),
+		wkbValuer(testPointWKB),
+		types.NewSFPointXY(1.2, 2.3),
+	} {
+		var p types.SFPoint
+		require.NoError(p.Scan(src), "%T", src)
+		require.Equal([]float64{1.2, 2.3}, p.FlatCoords(), "%T", src)
+	}
+
+	var p types.SFPoint
+	require.EqualError(p.Scan((*[]byte)(nil)), "types.SFPoint: cannot scan type *[]uint8 (<nil>)")
+	require.EqualError(p.Scan(42), "types.SFPoint: cannot scan type int (42)")
+	var l types.SFLineString
+	require.NoError(l.Scan(types.NewSFLineStringXY(testLineStringPoints)))
+	require.Equal(types.NewSFLineStringXY(testLineStringPoints), l)
+	var poly types.SFPolygon
+	require.NoError(poly.Scan(&testPolygonWKB))
+	g, err := types.ScanGeometry(wkbValuer(testPolygonWKB))
+	require.NoError(err)
+	require.Equal("Polygon", g.GeometryType())
 }
 
 func TestSFPointMarshalJSON(t *testing.T) {
@@ -130,6 +409,84 @@ func TestSFPointUnmarshalJSON(t *testing.T) {
 	require.NoError(err)
 	require.Equal(1.2, p.Lng())
 	require.Equal(2.3, p.Lat())
+
+	// Valid GeoJSON of another geometry type is an error, and leaves p as it
+	// was.
+	err = json.Unmarshal([]byte(`{"type":"LineString","coordinates":[[1,2],[3,4]]}`), &p)
+	require.EqualError(err, "types.SFPoint: cannot unmarshal a GeoJSON *geom.LineString")
+	err = json.Unmarshal(testPolygonGeoJSON, &p)
+	require.EqualError(err, "types.SFPoint: cannot unmarshal a GeoJSON *geom.Polygon")
+	require.Equal(types.NewSFPointXY(1.2, 2.3), p)
+}
+
+func TestSFPointUnmarshalBareCoordinates(t *testing.T) {
+	require := require.New(t)
+	defer func(v bool) { types.AcceptBareCoordinates = v }(types.AcceptBareCoordinates)
+
+	var p types.SFPoint
+	types.AcceptBareCoordinates = false
+	require.Error(json.Unmarshal([]byte(`[1.2, 2.3]`), &p))
+
+	types.AcceptBareCoordinates = true
+	require.NoError(json.Unmarshal([]byte(` [1.2, 2.3]`), &p))
+	require.Equal(types.NewSFPointXY(1.2, 2.3), p)
+	require.NoError(json.Unmarshal([]byte(`[1.2, 2.3, 4.5]`), &p))
+	require.Equal(types.NewSFPointXYZ(1.2, 2.3, 4.5), p)
+
+	// GeoJSON objects are still accepted.
+	require.NoError(json.Unmarshal(testPointGeoJSON, &p))
+	require.Equal(types.NewSFPointXY(1.2, 2.3), p)
+
+	for _, bad := range []string{`[]`, `[1.2]`, `[1, 2, 3, 4]`, `["1", "2"]`, `[1, null]`} {
+		p = types.NewSFPointXY(9, 9)
+		require.Error(json.Unmarshal([]byte(bad), &p), bad)
+		require.Equal(types.NewSFPointXY(9, 9), p, bad)
+	}
+}
+
+func TestSFPointTolerantCoordinateNull(t *testing.T) {
+	require := require.New(t)
+	defer func(v bool) { types.TolerantCoordinateNull = v }(types.TolerantCoordinateNull)
+	defer func(v bool) { types.AcceptBareCoordinates = v }(types.AcceptBareCoordinates)
+	types.AcceptBareCoordinates = true
+
+	nullAlt := []string{
+		`{"type":"Point","coordinates":[1.2,2.3,null]}`,
+		`[1.2, 2.3, null]`,
+	}
+	alwaysBad := []string{
+		`{"type":"Point","coordinates":[null,2.3]}`,
+		`{"type":"Point","coordinates":[1.2,null,3.4]}`,
+		`[1.2, null]`,
+	}
+
+	// By default, null coordinates are an error, rather than being zeroed.
+	types.TolerantCoordinateNull = false
+	for _, in := range append(nullAlt, alwaysBad...) {
+		p := types.NewSFPointXY(9, 9)
+		require.Error(json.Unmarshal([]byte(in), &p), in)
+		require.Equal(types.NewSFPointXY(9, 9), p, in)
+	}
+
+	types.TolerantCoordinateNull = true
+	for _, in := range nullAlt {
+		var p types.SFPoint
+		require.NoError(json.Unmarshal([]byte(in), &p), in)
+		require.Equal(types.NewSFPointXY(1.2, 2.3), p, in)
+	}
+	for _, in := range alwaysBad {
+		require.Error(json.Unmarshal([]byte(in), &types.SFPoint{}), in)
+	}
+
+	// Numeric altitudes, including 0, always produce XYZ points, and missing
+	// altitudes XY points.
+	var p types.SFPoint
+	require.NoError(json.Unmarshal([]byte(`{"type":"Point","coordinates":[1.2,2.3,0]}`), &p))
+	require.Equal(types.NewSFPointXYZ(1.2, 2.3, 0), p)
+	require.NoError(json.Unmarshal([]byte(`{"type":"Point","coordinates":[1.2,2.3]}`), &p))
+	require.Equal(types.NewSFPointXY(1.2, 2.3), p)
+	require.NoError(json.Unmarshal([]byte(`null`), &p))
+	require.True(p.IsNil())
 }
 
 func TestSFPointMarshsalMapValue(t *testing.T) {
@@ -147,3 +504,117 @@ func TestSFPointMarshsalMapValue(t *testing.T) {
 	require.NoError(err)
 	require.Equal(types.NewSFPointXY(1.2, 2.3), data["Point"])
 }
+
+func TestSFPointXYArray(t *testing.T) {
+	require := require.New(t)
+
+	p := types.NewSFPointFromXYArray([2]float64{1.2, 2.3})
+	require.Equal(types.NewSFPointXY(1.2, 2.3), p)
+	require.Equal([2]float64{1.2, 2.3}, p.XY())
+	require.Equal([2]float64{1, 2}, types.NewSFPointXYZ(1, 2, 3).XY())
+
+	xy := types.SFPoint{}.XY()
+	require.True(math.IsNaN(xy[0]) && math.IsNaN(xy[1]))
+	xy = types.NewSFPointEmpty(geom.XY).XY()
+	require.True(math.IsNaN(xy[0]) && math.IsNaN(xy[1]))
+}
+
+func TestSFPointGeoHash(t *testing.T) {
+	require := require.New(t)
+
+	p := types.NewSFPointXY(10.40744, 57.64911)
+	require.Equal("u4pruydqqvj", p.GeoHash(11))
+	require.Equal("u4pru", p.GeoHash(5))
+
+	// Precision is clamped to [1, 12].
+	require.Equal("u", p.GeoHash(0))
+	require.Equal("u", p.GeoHash(-3))
+	require.Len(p.GeoHash(20), 12)
+
+	// Nil points have no geohash.
+	require.Equal("", types.SFPoint{}.GeoHash(5))
+}
+
+func TestSFPointFromGeoHash(t *testing.T) {
+	require := require.New(t)
+
+	p, err := types.NewSFPointFromGeoHash("ezs42")
+	require.NoError(err)
+	require.InDelta(-5.60302734375, p.Lng(), 1e-9)
+	require.InDelta(42.60498046875, p.Lat(), 1e-9)
+
+	// Decoding and re-encoding is lossless at the same precision.
+	p, err = types.NewSFPointFromGeoHash("u4pruydqqvj")
+	require.NoError(err)
+	require.Equal("u4pruydqqvj", p.GeoHash(11))
+
+	_, err = types.NewSFPointFromGeoHash("")
+	require.Error(err)
+	// 'a', 'i', 'l', and 'o' are not part of the geohash alphabet.
+	_, err = types.NewSFPointFromGeoHash("ezs4a")
+	require.Error(err)
+}
+
+func TestSFPointDestination(t *testing.T) {
+	require := require.New(t)
+
+	// A quarter of the way around the equator.
+	quarter := math.Pi / 2 * 6371008.8
+	d := types.NewSFPointXY(0, 0).Destination(90, quarter)
+	require.InDelta(90, d.Lng(), 1e-9)
+	require.InDelta(0, d.Lat(), 1e-9)
+	d = types.NewSFPointXY(0, 0).Destination(0, quarter)
+	require.InDelta(90, d.Lat(), 1e-9)
+
+	// 124.8km east-south-east from 53°19'14"N, 1°43'47"W reaches 53°11'18"N,
+	// 0°08'00"E. Altitudes are kept, and the trip begins on the given bearing.
+	start := types.NewSFPointXYZ(-1.729722, 53.320556, 35)
+	d = start.Destination(96.021667, 124800)
+	require.InDelta(0.133333, d.Lng(), 1e-3)
+	require.InDelta(53.188333, d.Lat(), 1e-3)
+	require.Equal(geom.XYZ, d.Layout())
+	require.Equal(35.0, d.Alt())
+	require.InDelta(96.021667, start.InitialBearing(d), 1e-9)
+
+	// Longitudes wrap across the antimeridian.
+	d = types.NewSFPointXY(179.9, 0).Destination(90, 50000)
+	require.InDelta(-179.65, d.Lng(), 1e-2)
+
+	// Negative distances travel backwards.
+	d = types.NewSFPointXY(0, 0).Destination(0, -111195)
+	require.InDelta(-1, d.Lat(), 1e-4)
+
+	require.True(types.SFPoint{}.Destination(0, 100).IsNil())
+	require.True(types.NewSFPointEmpty(geom.XY).Destination(0, 100).IsEmpty())
+}
+
+func TestSFPointInitialBearing(t *testing.T) {
+	require := require.New(t)
+
+	o := types.NewSFPointXY(0, 0)
+	require.InDelta(0, o.InitialBearing(types.NewSFPointXY(0, 10)), 1e-9)
+	require.InDelta(90, o.InitialBearing(types.NewSFPointXY(10, 0)), 1e-9)
+	require.InDelta(180, o.InitialBearing(types.NewSFPointXY(0, -10)), 1e-9)
+	require.InDelta(270, o.InitialBearing(types.NewSFPointXY(-10, 0)), 1e-9)
+	require.Equal(0.0, o.InitialBearing(o))
+
+	// Great circle paths don't follow lines of latitude.
+	require.InDelta(86.46, types.NewSFPointXY(0, 45).InitialBearing(types.NewSFPointXY(10, 45)), 0.1)
+
+	require.True(math.IsNaN(o.InitialBearing(types.SFPoint{})))
+	require.True(math.IsNaN(types.NewSFPointEmpty(geom.XY).InitialBearing(o)))
+}
+
+func TestSFPointString(t *testing.T) {
+	require := require.New(t)
+
+	require.Equal("POINT (1.2 2.3)", types.NewSFPointXY(1.2, 2.3).String())
+	require.Equal("POINT Z (1 2 3)", types.NewSFPointXYZ(1, 2, 3).String())
+	require.Equal("POINT EMPTY", types.NewSFPointEmpty(geom.XY).String())
+	require.Equal("<nil point>", types.SFPoint{}.String())
+
+	// fmt uses String, rather than dumping the underlying geom.Point.
+	p := types.NewSFPointXY(1.2, 2.3)
+	require.Equal("at POINT (1.2 2.3)", fmt.Sprintf("at %v", p))
+	require.Equal("at POINT (1.2 2.3)", fmt.Sprintf("at %v", &p))
+}
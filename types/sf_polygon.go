@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"database/sql/driver"
 	"fmt"
+	"math"
+	"sort"
 
 	"github.com/twpayne/go-geom"
 	"github.com/twpayne/go-geom/encoding/geojson"
@@ -24,11 +26,24 @@ import (
 // of the pyrrho/encoding/types interfaces detailed in the package comments.
 // Database interactions (Value and Scan) will convert to and from a WKB (Well
 // Known Binary) representation. JSON interactions (MarshalJSON and
-// UnmarshalJSON) will convert to and from a GeoJSON representation.
+// UnmarshalJSON) will convert to and from a GeoJSON representation. Per
+// RFC 7946, GeoJSON coordinates are assumed to be WGS84 longitude and latitude;
+// the obsolete "crs" member is neither emitted nor consulted.
 type SFPolygon struct {
 	geom.Polygon
 }
 
+// NormalizePolygonWinding controls the ring winding order of SFPolygons
+// encoded by MarshalJSON. By default, rings are emitted in whatever order they
+// were constructed or scanned with. When NormalizePolygonWinding is true,
+// polygons will be passed through Normalize before being encoded, so that the
+// resulting GeoJSON follows the RFC 7946 right-hand rule regardless of how the
+// polygon was stored; clients that honor winding when filling polygons will
+// then render them correctly.
+//
+// UnmarshalJSON and Scan accept rings in either order, and never modify them.
+var NormalizePolygonWinding = false
+
 // Constructors
 
 // NewSFPolygon constructs and returns a new SFPolygon object initialized with
@@ -87,6 +102,403 @@ func NewSFPolygonXYZ(external [][3]float64, internals ...[][3]float64) SFPolygon
 	return SFPolygon{*p}
 }
 
+// NewSFPolygonFromBBox constructs and returns a new SFPolygon with longitude
+// and latitude components describing the rectangle bounded by the given
+// minimum and maximum coordinates. The resulting ring is closed, and wraps
+// counter-clockwise. If a minimum is greater than its maximum, the two will be
+// swapped.
+func NewSFPolygonFromBBox(minX, minY, maxX, maxY float64) SFPolygon {
+	if minX > maxX {
+		minX, maxX = maxX, minX
+	}
+	if minY > maxY {
+		minY, maxY = maxY, minY
+	}
+	return NewSFPolygonXY([][2]float64{
+		{minX, minY},
+		{maxX, minY},
+		{maxX, maxY},
+		{minX, maxY},
+		{minX, minY},
+	})
+}
+
+// earthMeanRadius is the mean radius of the Earth, in meters, as defined by the
+// IUGG.
+const earthMeanRadius = 6371008.8
+
+// NewSFCircle constructs and returns a new SFPolygon with longitude and
+// latitude components approximating the geodesic circle of radiusMeters meters
+// around center. The polygon's vertices lie on the circle, evenly spaced by
+// bearing, and are computed on a spherical model of the Earth. The ring is
+// closed and wraps counter-clockwise. Longitudes are normalized to the range
+// [-180, 180]. Fewer than 3 segments will be treated as 3.
+//
+// As the vertices lie on the circle, the polygon is inscribed within it. The
+// midpoint of each edge falls short of the circle by radiusMeters * (1 -
+// cos(pi / segments)) -- roughly 0.5% of the radius at 32 segments, and 0.03%
+// at 128.
+//
+// An empty SFPolygon will be returned if center is nil or empty.
+func NewSFCircle(center SFPoint, radiusMeters float64, segments int) SFPolygon {
+	if len(center.FlatCoords()) == 0 {
+		return SFPolygon{}
+	}
+	if segments < 3 {
+		segments = 3
+	}
+	lng1 := center.Lng() * math.Pi / 180
+	lat1 := center.Lat() * math.Pi / 180
+	d := radiusMeters / earthMeanRadius
+
+	ring := make([][2]float64, segments+1)
+	for i := 0; i < segments; i++ {
+		// Bearings run clockwise from north; step backwards to wind the ring
+		// counter-clockwise.
+		bearing := -2 * math.Pi * float64(i) / float64(segments)
+		lat2 := math.Asin(math.Sin(lat1)*math.Cos(d) + math.Cos(lat1)*math.Sin(d)*math.Cos(bearing))
+		lng2 := lng1 + math.Atan2(
+			math.Sin(bearing)*math.Sin(d)*math.Cos(lat1),
+			math.Cos(d)-math.Sin(lat1)*math.Sin(lat2))
+		lng := math.Mod(lng2*180/math.Pi+540, 360) - 180
+		ring[i] = [2]float64{lng, lat2 * 180 / math.Pi}
+	}
+	ring[segments] = ring[0]
+	return NewSFPolygonXY(ring)
+}
+
+// Getters
+
+// GeometryType returns the GeoJSON type name of p, "Polygon".
+func (p SFPolygon) GeometryType() string {
+	return "Polygon"
+}
+
+// BBox returns the bounding box of the longitude and latitude components of p.
+// Only the external ring is consulted, as internal rings lie within it. An
+// empty BBox will be returned if p is nil.
+func (p SFPolygon) BBox() BBox {
+	if p.IsNil() {
+		return NewBBoxEmpty()
+	}
+	return bboxOf(p.FlatCoords()[:p.Ends()[0]], p.Stride())
+}
+
+// Envelope returns the bounding box of p as a new rectangular SFPolygon, with
+// longitude and latitude components, constructed by NewSFPolygonFromBBox. An
+// empty SFPolygon will be returned if p is nil.
+func (p SFPolygon) Envelope() SFPolygon {
+	if p.IsNil() {
+		return SFPolygon{}
+	}
+	b := p.Bounds()
+	return NewSFPolygonFromBBox(b.Min(0), b.Min(1), b.Max(0), b.Max(1))
+}
+
+// NumRings returns the number of linear rings in p, including its external
+// ring. Nil SFPolygons have no rings.
+func (p SFPolygon) NumRings() int {
+	if p.IsNil() {
+		return 0
+	}
+	return p.NumLinearRings()
+}
+
+// Ring returns a copy of the i-th linear ring of p as a new SFLineString, with
+// p's layout and SRID. The 0th ring is the external ring, and any others are
+// internal rings. Rings are returned exactly as they're stored, so the closing
+// vertex of a closed ring is included in its points. Like indexing a slice,
+// Ring will panic if i is out of the range [0, p.NumRings()).
+func (p SFPolygon) Ring(i int) SFLineString {
+	if i < 0 || i >= p.NumRings() {
+		panic(fmt.Sprintf("types.SFPolygon: ring index %d out of range [0, %d)", i, p.NumRings()))
+	}
+	ends, start := p.Ends(), 0
+	if i > 0 {
+		start = ends[i-1]
+	}
+	coords := append([]float64(nil), p.FlatCoords()[start:ends[i]]...)
+	return SFLineString{*geom.NewLineStringFlat(p.Layout(), coords).SetSRID(p.SRID())}
+}
+
+// Area returns the planar area of p; the area enclosed by its external ring,
+// less the areas enclosed by its internal rings. As with Normalize, only the
+// longitude and latitude components are considered, and the area is measured
+// in the squared units of p's coordinates, not in square meters. Nil
+// SFPolygons have no area.
+func (p SFPolygon) Area() float64 {
+	area, _, _ := p.areaCentroid()
+	return area
+}
+
+// Centroid returns the planar centroid -- the center of mass -- of p, with
+// longitude and latitude components, and p's SRID. Internal rings are holes,
+// and pull the centroid away from themselves. The centroid of a concave
+// polygon may lie outside of it. An empty SFPoint will be returned if p is nil,
+// or has no area.
+func (p SFPolygon) Centroid() SFPoint {
+	area, cx, cy := p.areaCentroid()
+	if area == 0 {
+		return NewSFPointEmpty(geom.XY)
+	}
+	c := NewSFPointXY(cx, cy)
+	c.SetSRID(p.SRID())
+	return c
+}
+
+// areaCentroid returns the planar area of p, and the coordinates of its
+// centroid, which are meaningless if the area is 0.
+func (p SFPolygon) areaCentroid() (area, cx, cy float64) {
+	flat, stride, start := p.FlatCoords(), p.Stride(), 0
+	for i, end := range p.Ends() {
+		a, x, y := ringAreaCentroid(flat[start:end], stride)
+		if i > 0 {
+			a = -a
+		}
+		area += a
+		cx += a * x
+		cy += a * y
+		start = end
+	}
+	if area == 0 {
+		return 0, 0, 0
+	}
+	return area, cx / area, cy / area
+}
+
+// ringAreaCentroid returns the planar area enclosed by the ring described by
+// flat, regardless of its winding, and the coordinates of its centroid, which
+// are meaningless if the area is 0.
+func ringAreaCentroid(flat []float64, stride int) (area, cx, cy float64) {
+	n := len(flat) / stride
+	for i := 0; i < n; i++ {
+		j := (i + 1) % n
+		xi, yi := flat[i*stride], flat[i*stride+1]
+		xj, yj := flat[j*stride], flat[j*stride+1]
+		cross := xi*yj - xj*yi
+		area += cross
+		cx += (xi + xj) * cross
+		cy += (yi + yj) * cross
+	}
+	if area == 0 {
+		return 0, 0, 0
+	}
+	// area is twice the signed area, so the centroid is divided by 3*area
+	// rather than 6*(area/2). The signs cancel.
+	return math.Abs(area) / 2, cx / (3 * area), cy / (3 * area)
+}
+
+// PointOnSurface returns a point guaranteed to lie in the interior of p --
+// unlike its Centroid, which may fall outside concave polygons, or inside a
+// hole -- with longitude and latitude components, and p's SRID. This makes it
+// suitable for placing labels.
+//
+// The point is found by a scan-line: a horizontal line is drawn across p, near
+// the middle of its bounding box, at a latitude chosen to pass between
+// vertices rather than through any of them. The line's crossings with every
+// ring of p divide it into segments alternately inside and outside of p, and
+// the midpoint of the widest inside segment is returned. The point is inside p,
+// but isn't necessarily the one furthest from its boundary (the "pole of
+// inaccessibility"); on a narrow, horizontal section of p, it may lie close to
+// an edge. As with Area, the computation is planar.
+//
+// An empty SFPoint will be returned if p is nil, or has no area. In the
+// unlikely event that floating point error leaves no inside segment, p's
+// Centroid will be returned instead.
+func (p SFPolygon) PointOnSurface() SFPoint {
+	if p.IsNil() || p.Area() == 0 {
+		return NewSFPointEmpty(geom.XY)
+	}
+	flat, stride := p.FlatCoords(), p.Stride()
+	y := scanLineY(flat, stride)
+
+	var xs []float64
+	start := 0
+	for _, end := range p.Ends() {
+		ring := flat[start:end]
+		n := len(ring) / stride
+		for i := 0; i < n; i++ {
+			j := (i + 1) % n
+			xi, yi := ring[i*stride], ring[i*stride+1]
+			xj, yj := ring[j*stride], ring[j*stride+1]
+			if (yi > y) != (yj > y) {
+				xs = append(xs, xi+(y-yi)*(xj-xi)/(yj-yi))
+			}
+		}
+		start = end
+	}
+	sort.Float64s(xs)
+
+	width, x := 0.0, 0.0
+	for i := 0; i+1 < len(xs); i += 2 {
+		if w := xs[i+1] - xs[i]; w > width {
+			width, x = w, (xs[i]+xs[i+1])/2
+		}
+	}
+	if width == 0 {
+		return p.Centroid()
+	}
+	c := NewSFPointXY(x, y)
+	c.SetSRID(p.SRID())
+	return c
+}
+
+// scanLineY returns the latitude halfway between the two vertex latitudes in
+// flat closest to the middle of its extent, one at or below the middle, and one
+// above it. No vertex lies on that latitude, so a horizontal line drawn along
+// it crosses edges rather than touching vertices.
+func scanLineY(flat []float64, stride int) float64 {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for i := 1; i < len(flat); i += stride {
+		lo, hi = math.Min(lo, flat[i]), math.Max(hi, flat[i])
+	}
+	mid := (lo + hi) / 2
+	for i := 1; i < len(flat); i += stride {
+		switch y := flat[i]; {
+		case y <= mid && y > lo:
+			lo = y
+		case y > mid && y < hi:
+			hi = y
+		}
+	}
+	return (lo + hi) / 2
+}
+
+// MultiPolygonArea returns the total planar area of polys, as measured by
+// SFPolygon.Area, treating them as the components of a MultiPolygon. Polygons
+// are assumed not to overlap; overlapping areas are counted once per polygon.
+// Nil and empty polygons contribute nothing.
+func MultiPolygonArea(polys []SFPolygon) float64 {
+	var area float64
+	for _, p := range polys {
+		area += p.Area()
+	}
+	return area
+}
+
+// MultiPolygonCentroid returns the planar centroid of polys, treating them as
+// the components of a MultiPolygon; the average of the centroids of each
+// polygon, weighted by its area. The returned SFPoint has longitude and
+// latitude components, and the SRID of the first polygon with any area. An
+// empty SFPoint will be returned if none of polys have any area.
+func MultiPolygonCentroid(polys []SFPolygon) SFPoint {
+	var area, cx, cy float64
+	srid := -1
+	for _, p := range polys {
+		a, x, y := p.areaCentroid()
+		if a == 0 {
+			continue
+		}
+		if srid < 0 {
+			srid = p.SRID()
+		}
+		area += a
+		cx += a * x
+		cy += a * y
+	}
+	if area == 0 {
+		return NewSFPointEmpty(geom.XY)
+	}
+	c := NewSFPointXY(cx/area, cy/area)
+	c.SetSRID(srid)
+	return c
+}
+
+// IsNormalized returns true if every ring of p follows the RFC 7946 right-hand
+// rule; the external ring wraps counter-clockwise, and every internal ring
+// wraps clockwise. Rings with no area are considered to be correctly wound. Nil
+// SFPolygons are considered normalized.
+func (p SFPolygon) IsNormalized() bool {
+	flat, stride, start := p.FlatCoords(), p.Stride(), 0
+	for i, end := range p.Ends() {
+		if ringNeedsReversal(i, flat[start:end], stride) {
+			return false
+		}
+		start = end
+	}
+	return true
+}
+
+// Normalize returns a copy of p whose rings have been rewound, as necessary, to
+// follow the RFC 7946 right-hand rule; the external ring will wrap
+// counter-clockwise, and every internal ring will wrap clockwise. Reversed
+// rings that were closed remain closed, and start from the same vertex. Winding
+// is determined by the planar signed area of the longitude and latitude
+// components of each ring. An empty SFPolygon will be returned if p is nil.
+func (p SFPolygon) Normalize() SFPolygon {
+	if p.IsNil() {
+		return SFPolygon{}
+	}
+	flat := append([]float64(nil), p.FlatCoords()...)
+	ends := append([]int(nil), p.Ends()...)
+	stride, start := p.Stride(), 0
+	for i, end := range ends {
+		if ring := flat[start:end]; ringNeedsReversal(i, ring, stride) {
+			reverseRing(ring, stride)
+		}
+		start = end
+	}
+	return SFPolygon{*geom.NewPolygonFlat(p.Layout(), flat, ends).SetSRID(p.SRID())}
+}
+
+// ringNeedsReversal returns true if the ring described by flat -- the i-th ring
+// of a polygon -- winds against the right-hand rule.
+func ringNeedsReversal(i int, flat []float64, stride int) bool {
+	area := ringSignedArea(flat, stride)
+	if i == 0 {
+		return area < 0
+	}
+	return area > 0
+}
+
+// ringSignedArea returns twice the planar signed area of the ring described by
+// flat, as computed by the shoelace formula. The area is positive if the ring
+// wraps counter-clockwise, and negative if it wraps clockwise.
+func ringSignedArea(flat []float64, stride int) float64 {
+	var area float64
+	n := len(flat) / stride
+	for i := 0; i < n; i++ {
+		j := (i + 1) % n
+		area += flat[i*stride]*flat[j*stride+1] - flat[j*stride]*flat[i*stride+1]
+	}
+	return area
+}
+
+// reverseRing reverses the order of the vertices of the ring described by flat
+// in place.
+func reverseRing(flat []float64, stride int) {
+	n := len(flat) / stride
+	for i, j := 0, n-1; i < j; i, j = i+1, j-1 {
+		for k := 0; k < stride; k++ {
+			flat[i*stride+k], flat[j*stride+k] = flat[j*stride+k], flat[i*stride+k]
+		}
+	}
+}
+
+// MarshalKML returns the KML <Polygon> element describing p, with its external
+// ring as the <outerBoundaryIs>, and each of its internal rings as an
+// <innerBoundaryIs>. Coordinates are listed as SFPoint.MarshalKML would write
+// them. KML doesn't specify a winding order, so rings are written as-is. An
+// error will be returned if p is nil, or has no rings.
+func (p SFPolygon) MarshalKML() ([]byte, error) {
+	if p.IsNil() || p.NumRings() == 0 {
+		return nil, fmt.Errorf("types.SFPolygon: cannot encode a nil or empty SFPolygon as KML")
+	}
+	b := []byte("<Polygon>")
+	flat, start := p.FlatCoords(), 0
+	for i, end := range p.Ends() {
+		boundary := "innerBoundaryIs"
+		if i == 0 {
+			boundary = "outerBoundaryIs"
+		}
+		b = append(b, "<"+boundary+">"...)
+		b = appendKMLElement(b, "LinearRing", p.Layout(), flat[start:end])
+		b = append(b, "</"+boundary+">"...)
+		start = end
+	}
+	return append(b, "</Polygon>"...), nil
+}
+
 // Interfaces
 
 // IsNil implements the pyrrho/encoding IsNiler interface. It will return true
@@ -113,6 +525,13 @@ func (p SFPolygon) IsZero() bool {
 	return true
 }
 
+// String implements the fmt Stringer interface. It will return the WKT
+// representation of p -- eg. "POLYGON ((0 0, 1 0, 1 1, 0 0))" -- "POLYGON
+// EMPTY" if p has a layout but no rings, or "<nil polygon>" if p has no layout.
+func (p SFPolygon) String() string {
+	return wktString(&p.Polygon, "polygon")
+}
+
 // Value implements the database/sql/driver Valuer interface. It will return the
 // value of p as a driver.Value; specifically a WKB encoded []byte.
 func (p SFPolygon) Value() (driver.Value, error) {
@@ -125,15 +544,20 @@ func (p SFPolygon) Value() (driver.Value, error) {
 
 // Scan implements the database/sql Scanner interface. It expects to receive a
 // WKB encoded []byte describing a Polygon from an SQL database, and will assign
-// that value to p. If the incoming []byte is not a well formed WKB, or if that
-// WKB value does not describe a Polygon, an error will be returned.
+// that value to p. As with SFPoint.Scan, a string, a *[]byte, or a
+// driver.Valuer may be passed in place of the []byte. If the incoming []byte is
+// not a well formed WKB, or if that WKB value does not describe a Polygon, an
+// error will be returned.
 func (p *SFPolygon) Scan(src interface{}) error {
 	if p == nil {
 		return fmt.Errorf("types.SFPolygon: Scan called on nil SFLPolygoner")
 	}
-	b, ok := src.([]byte)
-	if !ok {
-		return fmt.Errorf("types.SFPolygon: cannot scan type %T (%v)", src, src)
+	b, err := scanBytes(src)
+	if err != nil {
+		return fmt.Errorf("types.SFPolygon: %v", err)
+	}
+	if err := checkWKBGeometryType(b, "Polygon"); err != nil {
+		return fmt.Errorf("types.SFPolygon: %v", err)
 	}
 	g, err := wkb.Unmarshal(b)
 	if err != nil {
@@ -141,38 +565,66 @@ func (p *SFPolygon) Scan(src interface{}) error {
 	}
 	t, ok := g.(*geom.Polygon)
 	if !ok {
-		return fmt.Errorf("types.SFPolygon: scan did not return a *geom.Polygon (got a %T)", t)
+		return fmt.Errorf("types.SFPolygon: scan did not return a *geom.Polygon (got a %T)", g)
 	}
 	p.Polygon.Swap(t)
 	return nil
 }
 
 // MarshalJSON implements the encoding/json Marshaler interface. It will return
-// the GeoJSON encoded representation of p.
+// the GeoJSON encoded representation of p. If p is nil, an error will be
+// returned, or 'null' if EmptyGeometryAsNull is true. If
+// NormalizePolygonWinding is true, the rings of p will be rewound to follow the
+// RFC 7946 right-hand rule.
 func (p SFPolygon) MarshalJSON() ([]byte, error) {
 	if p.IsNil() {
+		if EmptyGeometryAsNull {
+			return []byte("null"), nil
+		}
 		return nil, fmt.Errorf("types.SFPolygon: cannot unmarshal an uninitialized SFPolygon")
 	}
-	return geojson.Marshal(&p.Polygon)
+	if NormalizePolygonWinding {
+		p = p.Normalize()
+	}
+	return marshalGeoJSON(&p.Polygon)
 }
 
 // UnmarshalJSON implements the encoding/json Unmarshaler interface. It expects
 // to receive a valid GeoJSON Geometry with of the type Polygon, and will assign
-// the value of that data to p.
+// the value of that data to p. A UTF-8 byte order mark at the start of data,
+// and whitespace around it, are ignored.
 func (p *SFPolygon) UnmarshalJSON(data []byte) error {
 	if p == nil {
 		return fmt.Errorf("types.SFPolygon: UnmarshalJSON called on nil SFLPolygoner")
 	}
+	data = trimGeoJSON(data)
 	var gt geom.T
 	if err := geojson.Unmarshal(data, &gt); err != nil {
 		return err
 	}
-	p.Polygon.Swap(gt.(*geom.Polygon))
+	if gt == nil {
+		// The 'null' keyword decodes into a nil Polygon.
+		p.Polygon = geom.Polygon{}
+		return nil
+	}
+	t, ok := gt.(*geom.Polygon)
+	if !ok {
+		return fmt.Errorf("types.SFPolygon: cannot unmarshal a GeoJSON %T", gt)
+	}
+	if err := checkGeoJSONBBox(data, t); err != nil {
+		return fmt.Errorf("types.SFPolygon: %v", err)
+	}
+	p.Polygon.Swap(t)
 	return nil
 }
 
 // MarshalMapValue implements the pyrrho/encoding/maps Marshaler interface. It
-// will return p wrapped in an interface{} for use in a map[string]interface{}.
+// will return p wrapped in an interface{} for use in a map[string]interface{},
+// or p's GeoJSON representation as a map[string]interface{} if
+// MapValueAsGeoJSON is true.
 func (p SFPolygon) MarshalMapValue() (interface{}, error) {
+	if MapValueAsGeoJSON {
+		return geoJSONMapValue(p)
+	}
 	return p, nil
 }
@@ -3,6 +3,7 @@ package types_test
 import (
 	"database/sql/driver"
 	"encoding/json"
+	"math"
 	"testing"
 
 	"github.com/pyrrho/encoding/maps"
@@ -153,6 +154,178 @@ func TestSFPolygonCtors(t *testing.T) {
 		pc.Polygon)
 }
 
+func TestSFPolygonFromBBox(t *testing.T) {
+	require := require.New(t)
+
+	p := types.NewSFPolygonFromBBox(-1, -2, 3, 4)
+	require.Equal(geom.XY, p.Layout())
+	require.Equal([][]geom.Coord{{
+		{-1, -2},
+		{3, -2},
+		{3, 4},
+		{-1, 4},
+		{-1, -2},
+	}}, p.Coords())
+	// A counter-clockwise ring has a positive signed area.
+	require.Equal(24.0, p.Area())
+	require.True(p.LinearRing(0).Area() > 0)
+
+	// Inverted bounds are normalized.
+	require.Equal(p, types.NewSFPolygonFromBBox(3, 4, -1, -2))
+}
+
+func TestSFCircle(t *testing.T) {
+	require := require.New(t)
+
+	haversine := func(a, b geom.Coord) float64 {
+		const r = 6371008.8
+		lat1, lat2 := a[1]*math.Pi/180, b[1]*math.Pi/180
+		dLat, dLng := lat2-lat1, (b[0]-a[0])*math.Pi/180
+		h := math.Pow(math.Sin(dLat/2), 2) + math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin(dLng/2), 2)
+		return 2 * r * math.Asin(math.Sqrt(h))
+	}
+	signedArea := func(ring []geom.Coord) float64 {
+		var a float64
+		for i := 0; i < len(ring)-1; i++ {
+			a += ring[i][0]*ring[i+1][1] - ring[i+1][0]*ring[i][1]
+		}
+		return a / 2
+	}
+
+	center := types.NewSFPointXY(-122.4194, 37.7749)
+	p := types.NewSFCircle(center, 1000, 64)
+	require.Equal(geom.XY, p.Layout())
+	ring := p.Coords()[0]
+	require.Len(ring, 65)
+	require.Equal(ring[0], ring[64])
+	// Every vertex lies on the circle ...
+	for _, c := range ring {
+		require.InDelta(1000, haversine(center.Coords(), c), 1e-6)
+	}
+	// ... the first is due north of the center ...
+	require.InDelta(center.Lng(), ring[0][0], 1e-12)
+	require.True(ring[0][1] > center.Lat())
+	// ... and the ring wraps counter-clockwise.
+	require.True(signedArea(ring) > 0)
+
+	// Longitudes are normalized across the antimeridian.
+	p = types.NewSFCircle(types.NewSFPointXY(179.99, 0), 10000, 16)
+	for _, c := range p.Coords()[0] {
+		require.True(c[0] >= -180 && c[0] <= 180, "%v", c)
+		require.True(math.Abs(c[0]) > 179.8, "%v", c)
+	}
+
+	// Too few segments are raised to a triangle.
+	p = types.NewSFCircle(center, 1000, 1)
+	require.Len(p.Coords()[0], 4)
+
+	require.True(types.NewSFCircle(types.SFPoint{}, 1000, 32).IsNil())
+}
+
+func TestSFPolygonEnvelope(t *testing.T) {
+	require := require.New(t)
+
+	p := types.NewSFPolygonXY(testPolygonExternal, testPolygonInternal)
+	require.Equal(types.NewSFPolygonFromBBox(10, 10, 40, 40), p.Envelope())
+
+	// The envelope of an XYZ polygon is flattened to XY.
+	p = types.NewSFPolygonXYZ([][3]float64{{0, 0, 5}, {2, 1, 6}, {1, 3, 7}, {0, 0, 5}})
+	require.Equal(types.NewSFPolygonFromBBox(0, 0, 2, 3), p.Envelope())
+
+	require.True(types.SFPolygon{}.Envelope().IsNil())
+}
+
+func TestSFPolygonRings(t *testing.T) {
+	require := require.New(t)
+
+	p := types.NewSFPolygonXY(testPolygonExternal, testPolygonInternal)
+	require.Equal(2, p.NumRings())
+	require.Equal(types.NewSFLineStringXY(testPolygonExternal), p.Ring(0))
+	require.Equal(types.NewSFLineStringXY(testPolygonInternal), p.Ring(1))
+	require.Equal(len(testPolygonInternal), p.Ring(1).NumPoints())
+
+	// Rings are copies.
+	r := p.Ring(0)
+	r.FlatCoords()[0] = -1
+	require.Equal(types.NewSFLineStringXY(testPolygonExternal), p.Ring(0))
+
+	require.Panics(func() { p.Ring(2) })
+	require.Panics(func() { p.Ring(-1) })
+	require.Equal(0, types.SFPolygon{}.NumRings())
+	require.Panics(func() { types.SFPolygon{}.Ring(0) })
+}
+
+// reversedRing returns a copy of ring with its vertices in the opposite order.
+func reversedRing(ring [][2]float64) [][2]float64 {
+	ret := make([][2]float64, len(ring))
+	for i := range ring {
+		ret[len(ring)-1-i] = ring[i]
+	}
+	return ret
+}
+
+func TestSFPolygonNormalize(t *testing.T) {
+	require := require.New(t)
+
+	// The test polygon is already wound per RFC 7946.
+	correct := types.NewSFPolygonXY(testPolygonExternal, testPolygonInternal)
+	require.True(correct.IsNormalized())
+	require.Equal(correct, correct.Normalize())
+
+	// Both rings wound the wrong way around.
+	wrong := types.NewSFPolygonXY(reversedRing(testPolygonExternal), reversedRing(testPolygonInternal))
+	require.False(wrong.IsNormalized())
+	n := wrong.Normalize()
+	require.True(n.IsNormalized())
+	require.Equal(testPolygonCoords, n.Coords())
+	// Normalize doesn't modify its receiver.
+	require.False(wrong.IsNormalized())
+
+	// Only the hole wound the wrong way around.
+	mixed := types.NewSFPolygonXY(testPolygonExternal, reversedRing(testPolygonInternal))
+	require.False(mixed.IsNormalized())
+	n = mixed.Normalize()
+	require.Equal(testPolygonCoords, n.Coords())
+
+	// Altitudes travel with their vertices.
+	xyz := types.NewSFPolygonXYZ([][3]float64{{0, 0, 1}, {0, 1, 2}, {1, 0, 3}, {0, 0, 1}})
+	require.False(xyz.IsNormalized())
+	n = xyz.Normalize()
+	require.Equal([][]geom.Coord{{{0, 0, 1}, {1, 0, 3}, {0, 1, 2}, {0, 0, 1}}}, n.Coords())
+
+	require.True(types.SFPolygon{}.IsNormalized())
+	require.True(types.SFPolygon{}.Normalize().IsNil())
+}
+
+func TestSFPolygonNormalizeWindingJSON(t *testing.T) {
+	require := require.New(t)
+	defer func(v bool) { types.NormalizePolygonWinding = v }(types.NormalizePolygonWinding)
+
+	wrong := types.NewSFPolygonXY(reversedRing(testPolygonExternal), reversedRing(testPolygonInternal))
+	wrongGeoJSON := []byte(`{"type":"Polygon","coordinates":[[[30,10],[10,20],[20,40],[40,40],[30,10]],[[28,15],[35,35],[22,35],[15,21],[28,15]]]}`)
+
+	// By default, the stored winding is emitted as-is.
+	types.NormalizePolygonWinding = false
+	data, err := json.Marshal(wrong)
+	require.NoError(err)
+	require.EqualValues(wrongGeoJSON, data)
+
+	types.NormalizePolygonWinding = true
+	data, err = json.Marshal(wrong)
+	require.NoError(err)
+	require.EqualValues(testPolygonGeoJSON, data)
+	data, err = json.Marshal(types.NewSFPolygonXY(testPolygonExternal, testPolygonInternal))
+	require.NoError(err)
+	require.EqualValues(testPolygonGeoJSON, data)
+
+	// Either winding is accepted when unmarshalling, and is left untouched.
+	var p types.SFPolygon
+	require.NoError(json.Unmarshal(wrongGeoJSON, &p))
+	require.Equal(wrong.Coords(), p.Coords())
+	require.NoError(json.Unmarshal(testPolygonGeoJSON, &p))
+	require.Equal(testPolygonCoords, p.Coords())
+}
+
 func TestSFPolygonIsNil(t *testing.T) {
 	require := require.New(t)
 
@@ -224,6 +397,11 @@ func TestSFPolygonSQLScan(t *testing.T) {
 	var bad types.SFPolygon
 	err = bad.Scan(driver.Value(nil))
 	require.Error(err)
+
+	// The WKB must describe a Polygon.
+	err = p.Scan(driver.Value(testPointWKB))
+	require.EqualError(err, "types.SFPolygon: expected Polygon, got Point")
+	require.Equal(testPolygonCoords, p.Coords())
 }
 
 func TestSFPolygonMarshalJSON(t *testing.T) {
@@ -254,6 +432,14 @@ func TestSFPolygonUnmarshalJSON(t *testing.T) {
 	err = json.Unmarshal(testPolygonGeoJSON, &p)
 	require.NoError(err)
 	require.Equal(testPolygonCoords, p.Coords())
+
+	// Valid GeoJSON of another geometry type is an error, and leaves p as it
+	// was.
+	err = json.Unmarshal(testPointGeoJSON, &p)
+	require.EqualError(err, "types.SFPolygon: cannot unmarshal a GeoJSON *geom.Point")
+	err = json.Unmarshal([]byte(`{"type":"LineString","coordinates":[[1,2],[3,4]]}`), &p)
+	require.EqualError(err, "types.SFPolygon: cannot unmarshal a GeoJSON *geom.LineString")
+	require.Equal(testPolygonCoords, p.Coords())
 }
 
 func TestSFPolygonMarshsalMapValue(t *testing.T) {
@@ -271,3 +457,90 @@ func TestSFPolygonMarshsalMapValue(t *testing.T) {
 	require.NoError(err)
 	require.Equal(types.NewSFPolygon(testPolygonGoGeom), data["Polygon"])
 }
+
+func TestSFPolygonString(t *testing.T) {
+	require := require.New(t)
+
+	p := types.NewSFPolygonXY([][2]float64{{0, 0}, {1, 0}, {1, 1}, {0, 0}})
+	require.Equal("POLYGON ((0 0, 1 0, 1 1, 0 0))", p.String())
+	require.Equal("POLYGON EMPTY", types.NewSFPolygon(*geom.NewPolygon(geom.XY)).String())
+	require.Equal("<nil polygon>", types.SFPolygon{}.String())
+}
+
+func TestSFPolygonAreaCentroid(t *testing.T) {
+	require := require.New(t)
+
+	// A 4x2 rectangle, wound either way.
+	r := types.NewSFPolygonXY([][2]float64{{0, 0}, {4, 0}, {4, 2}, {0, 2}, {0, 0}})
+	require.Equal(8.0, r.Area())
+	require.Equal(types.NewSFPointXY(2, 1), r.Centroid())
+	cw := types.NewSFPolygonXY([][2]float64{{0, 0}, {0, 2}, {4, 2}, {4, 0}, {0, 0}})
+	require.Equal(8.0, cw.Area())
+	require.Equal(types.NewSFPointXY(2, 1), cw.Centroid())
+
+	// A hole in the right half pulls the centroid left.
+	h := types.NewSFPolygonXY(
+		[][2]float64{{0, 0}, {4, 0}, {4, 2}, {0, 2}, {0, 0}},
+		[][2]float64{{2, 0}, {4, 0}, {4, 2}, {2, 2}, {2, 0}},
+	)
+	require.Equal(4.0, h.Area())
+	require.Equal(types.NewSFPointXY(1, 1), h.Centroid())
+
+	require.Equal(0.0, types.SFPolygon{}.Area())
+	require.True(types.SFPolygon{}.Centroid().IsEmpty())
+	flat := types.NewSFPolygonXY([][2]float64{{0, 0}, {1, 1}, {2, 2}, {0, 0}})
+	require.True(flat.Centroid().IsEmpty())
+}
+
+func TestSFPolygonPointOnSurface(t *testing.T) {
+	require := require.New(t)
+
+	r := types.NewSFPolygonXY([][2]float64{{0, 0}, {4, 0}, {4, 2}, {0, 2}, {0, 0}})
+	r.SetSRID(4326)
+	pt := r.PointOnSurface()
+	require.Equal(4326, pt.SRID())
+	require.Equal([2]float64{2, 1}, pt.XY())
+
+	// The centroid of a U falls in its notch, and that of a square with a
+	// square hole falls in the hole; the point on surface falls in neither.
+	u := types.NewSFPolygonXY([][2]float64{
+		{0, 0}, {6, 0}, {6, 6}, {4, 6}, {4, 2}, {2, 2}, {2, 6}, {0, 6}, {0, 0},
+	})
+	notch := types.NewBBox(2, 2, 4, 6)
+	require.True(notch.Contains(u.Centroid()))
+	pt = u.PointOnSurface()
+	require.False(notch.Contains(pt))
+	require.True(u.BBox().Contains(pt))
+
+	donut := types.NewSFPolygonXY(
+		[][2]float64{{0, 0}, {6, 0}, {6, 6}, {0, 6}, {0, 0}},
+		[][2]float64{{2, 2}, {4, 2}, {4, 4}, {2, 4}, {2, 2}},
+	)
+	hole := types.NewBBox(2, 2, 4, 4)
+	require.True(hole.Contains(donut.Centroid()))
+	pt = donut.PointOnSurface()
+	require.False(hole.Contains(pt))
+	require.True(donut.BBox().Contains(pt))
+
+	require.True(types.SFPolygon{}.PointOnSurface().IsEmpty())
+	flat := types.NewSFPolygonXY([][2]float64{{0, 0}, {1, 1}, {2, 2}, {0, 0}})
+	require.True(flat.PointOnSurface().IsEmpty())
+}
+
+func TestMultiPolygonAreaCentroid(t *testing.T) {
+	require := require.New(t)
+
+	polys := []types.SFPolygon{
+		types.NewSFPolygonFromBBox(0, 0, 2, 2),
+		{},
+		types.NewSFPolygonFromBBox(10, 0, 11, 1),
+	}
+	require.Equal(5.0, types.MultiPolygonArea(polys))
+	c := types.MultiPolygonCentroid(polys)
+	require.InDelta((4*1+1*10.5)/5, c.X(), 1e-12)
+	require.InDelta((4*1+1*0.5)/5, c.Y(), 1e-12)
+
+	require.Equal(0.0, types.MultiPolygonArea(nil))
+	require.True(types.MultiPolygonCentroid(nil).IsEmpty())
+	require.True(types.MultiPolygonCentroid([]types.SFPolygon{{}}).IsEmpty())
+}
@@ -0,0 +1,231 @@
+package types
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/twpayne/go-geom"
+)
+
+// TWKB (Tiny Well-Known Binary) is a compact binary geometry encoding, in which
+// coordinates are scaled to integers at a fixed decimal precision, delta
+// encoded, and written as variable length integers. See
+// https://github.com/TWKB/Specification for the details.
+//
+// Only Points and LineStrings are supported. When decoding, the bounding box
+// and size metadata are accepted, but skipped. ID lists are rejected, as they
+// only apply to multi-geometries. When encoding, neither a bounding box, a
+// size, nor an ID list is written; only the header, the extended dimensions if
+// the geometry has Z or M coordinates, and the coordinates themselves.
+
+const (
+	twkbPoint      = 1
+	twkbLineString = 2
+
+	twkbFlagBBox     = 0x01
+	twkbFlagSize     = 0x02
+	twkbFlagIDList   = 0x04
+	twkbFlagExtended = 0x08
+	twkbFlagEmpty    = 0x10
+
+	// twkbMinPrecision and twkbMaxPrecision bound the XY precision that can be
+	// stored in the 4 bit, zig-zag encoded, precision field of a TWKB header.
+	// Z and M precisions are unsigned 3 bit values.
+	twkbMinPrecision = -8
+	twkbMaxPrecision = 7
+)
+
+// appendTWKB appends the TWKB encoding of the geometry of type typ, with the
+// given layout and coordinates, to dst. XY coordinates are rounded to precision
+// decimal places, and Z and M coordinates to precision clamped to [0, 7].
+func appendTWKB(dst []byte, typ byte, layout geom.Layout, flat []float64, precision int) ([]byte, error) {
+	if precision < twkbMinPrecision || precision > twkbMaxPrecision {
+		return nil, fmt.Errorf("cannot encode TWKB with precision %d; must be in [%d, %d]",
+			precision, twkbMinPrecision, twkbMaxPrecision)
+	}
+	zmPrecision := precision
+	if zmPrecision < 0 {
+		zmPrecision = 0
+	}
+
+	dst = append(dst, typ|byte(zigzag(int64(precision))<<4))
+	var meta, ext byte
+	switch layout {
+	case geom.XY:
+	case geom.XYZ:
+		ext = 0x01 | byte(zmPrecision)<<2
+	case geom.XYM:
+		ext = 0x02 | byte(zmPrecision)<<5
+	case geom.XYZM:
+		ext = 0x03 | byte(zmPrecision)<<2 | byte(zmPrecision)<<5
+	default:
+		return nil, fmt.Errorf("cannot encode a geometry with layout %v as TWKB", layout)
+	}
+	if ext != 0 {
+		meta |= twkbFlagExtended
+	}
+	if len(flat) == 0 {
+		meta |= twkbFlagEmpty
+	}
+	dst = append(dst, meta)
+	if ext != 0 {
+		dst = append(dst, ext)
+	}
+	if len(flat) == 0 {
+		return dst, nil
+	}
+
+	stride := layout.Stride()
+	scales := twkbScales(layout, precision, zmPrecision, zmPrecision)
+	if typ == twkbLineString {
+		dst = binary.AppendUvarint(dst, uint64(len(flat)/stride))
+	}
+	prev := make([]int64, stride)
+	for i, c := range flat {
+		d := i % stride
+		v := math.Round(c * scales[d])
+		// Leave some headroom, so that deltas between coordinates can't
+		// overflow.
+		if math.IsNaN(v) || math.Abs(v) > 1<<62 {
+			return nil, fmt.Errorf("cannot encode coordinate %v as TWKB with precision %d", c, precision)
+		}
+		dst = binary.AppendUvarint(dst, zigzag(int64(v)-prev[d]))
+		prev[d] = int64(v)
+	}
+	return dst, nil
+}
+
+// decodeTWKB decodes the TWKB encoded geometry b, which must be of type typ,
+// returning its layout and coordinates.
+func decodeTWKB(b []byte, typ byte) (geom.Layout, []float64, error) {
+	r := twkbReader{b: b}
+	head, err := r.byte()
+	if err != nil {
+		return geom.NoLayout, nil, err
+	}
+	if head&0x0f != typ {
+		return geom.NoLayout, nil, fmt.Errorf("TWKB geometry type %d does not match the expected type %d", head&0x0f, typ)
+	}
+	precision := int(unzigzag(uint64(head >> 4)))
+	meta, err := r.byte()
+	if err != nil {
+		return geom.NoLayout, nil, err
+	}
+	layout, zPrecision, mPrecision := geom.XY, 0, 0
+	if meta&twkbFlagExtended != 0 {
+		ext, err := r.byte()
+		if err != nil {
+			return geom.NoLayout, nil, err
+		}
+		zPrecision, mPrecision = int(ext>>2&0x07), int(ext>>5&0x07)
+		switch ext & 0x03 {
+		case 0x01:
+			layout = geom.XYZ
+		case 0x02:
+			layout = geom.XYM
+		case 0x03:
+			layout = geom.XYZM
+		}
+	}
+	if meta&twkbFlagSize != 0 {
+		size, err := r.uvarint()
+		if err != nil {
+			return geom.NoLayout, nil, err
+		}
+		if size != uint64(len(r.b)-r.off) {
+			return geom.NoLayout, nil, fmt.Errorf("TWKB size %d does not match the %d bytes remaining", size, len(r.b)-r.off)
+		}
+	}
+	if meta&twkbFlagIDList != 0 {
+		return geom.NoLayout, nil, fmt.Errorf("TWKB ID lists are not supported")
+	}
+	stride := layout.Stride()
+	if meta&twkbFlagEmpty != 0 {
+		return layout, nil, r.done()
+	}
+	if meta&twkbFlagBBox != 0 {
+		for i := 0; i < 2*stride; i++ {
+			if _, err := r.uvarint(); err != nil {
+				return geom.NoLayout, nil, err
+			}
+		}
+	}
+
+	n := uint64(1)
+	if typ == twkbLineString {
+		if n, err = r.uvarint(); err != nil {
+			return geom.NoLayout, nil, err
+		}
+		// Every coordinate takes at least one byte; don't let a corrupt count
+		// drive a huge allocation.
+		if n > uint64(len(r.b)-r.off) {
+			return geom.NoLayout, nil, fmt.Errorf("TWKB point count %d exceeds the data remaining", n)
+		}
+	}
+	scales := twkbScales(layout, precision, zPrecision, mPrecision)
+	flat := make([]float64, int(n)*stride)
+	prev := make([]int64, stride)
+	for i := range flat {
+		d := i % stride
+		u, err := r.uvarint()
+		if err != nil {
+			return geom.NoLayout, nil, err
+		}
+		prev[d] += unzigzag(u)
+		flat[i] = float64(prev[d]) / scales[d]
+	}
+	return layout, flat, r.done()
+}
+
+// twkbScales returns the factors by which each dimension of a layout geometry
+// is scaled when encoded with the given precisions.
+func twkbScales(layout geom.Layout, precision, zPrecision, mPrecision int) []float64 {
+	ret := make([]float64, layout.Stride())
+	ret[0], ret[1] = math.Pow10(precision), math.Pow10(precision)
+	if i := layout.ZIndex(); i >= 0 {
+		ret[i] = math.Pow10(zPrecision)
+	}
+	if i := layout.MIndex(); i >= 0 {
+		ret[i] = math.Pow10(mPrecision)
+	}
+	return ret
+}
+
+func zigzag(v int64) uint64 {
+	return uint64(v<<1) ^ uint64(v>>63)
+}
+
+func unzigzag(u uint64) int64 {
+	return int64(u>>1) ^ -int64(u&1)
+}
+
+// twkbReader reads the components of a TWKB encoded geometry from b.
+type twkbReader struct {
+	b   []byte
+	off int
+}
+
+func (r *twkbReader) byte() (byte, error) {
+	if r.off >= len(r.b) {
+		return 0, fmt.Errorf("unexpected end of TWKB data")
+	}
+	r.off++
+	return r.b[r.off-1], nil
+}
+
+func (r *twkbReader) uvarint() (uint64, error) {
+	u, n := binary.Uvarint(r.b[r.off:])
+	if n <= 0 {
+		return 0, fmt.Errorf("malformed varint in TWKB data at offset %d", r.off)
+	}
+	r.off += n
+	return u, nil
+}
+
+func (r *twkbReader) done() error {
+	if r.off != len(r.b) {
+		return fmt.Errorf("%d bytes of trailing TWKB data", len(r.b)-r.off)
+	}
+	return nil
+}
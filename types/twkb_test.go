@@ -0,0 +1,115 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/twpayne/go-geom"
+
+	"github.com/pyrrho/encoding/types"
+)
+
+func TestSFPointTWKB(t *testing.T) {
+	require := require.New(t)
+
+	// POINT(1 2), as encoded by PostGIS' ST_AsTWKB.
+	data, err := types.NewSFPointXY(1, 2).MarshalTWKB(0)
+	require.NoError(err)
+	require.Equal([]byte{0x01, 0x00, 0x02, 0x04}, data)
+
+	for _, tc := range []struct {
+		in        types.SFPoint
+		precision int
+		expected  types.SFPoint
+	}{
+		{types.NewSFPointXY(-122.4194155, 37.7749295), 7, types.NewSFPointXY(-122.4194155, 37.7749295)},
+		{types.NewSFPointXY(-122.4194155, 37.7749295), 2, types.NewSFPointXY(-122.42, 37.77)},
+		{types.NewSFPointXY(1234, 5678), -2, types.NewSFPointXY(1200, 5700)},
+		{types.NewSFPointXYZ(1.25, 2.5, 100.123), 2, types.NewSFPointXYZ(1.25, 2.5, 100.12)},
+		{types.NewSFPointXYZ(1.25, 2.5, 100.123), -1, types.NewSFPointXYZ(0, 0, 100)},
+		{types.NewSFPoint(*geom.NewPointFlat(geom.XYZM, []float64{1, 2, 3, 4})), 0,
+			types.NewSFPoint(*geom.NewPointFlat(geom.XYZM, []float64{1, 2, 3, 4}))},
+		{types.NewSFPointEmpty(geom.XYM), 3, types.NewSFPointEmpty(geom.XYM)},
+	} {
+		data, err := tc.in.MarshalTWKB(tc.precision)
+		require.NoError(err)
+		var p types.SFPoint
+		require.NoError(p.UnmarshalTWKB(data))
+		require.Equal(tc.expected.Layout(), p.Layout())
+		require.Equal(tc.expected.IsEmpty(), p.IsEmpty())
+		require.InDeltaSlice(tc.expected.FlatCoords(), p.FlatCoords(), 1e-9)
+	}
+
+	_, err = types.SFPoint{}.MarshalTWKB(0)
+	require.Error(err)
+	_, err = types.NewSFPointXY(1, 2).MarshalTWKB(8)
+	require.Error(err)
+	_, err = types.NewSFPointXY(1, 2).MarshalTWKB(-9)
+	require.Error(err)
+}
+
+func TestSFLineStringTWKB(t *testing.T) {
+	require := require.New(t)
+
+	// LINESTRING(1 1,5 5), as encoded by PostGIS' ST_AsTWKB.
+	data, err := types.NewSFLineStringXY([][2]float64{{1, 1}, {5, 5}}).MarshalTWKB(0)
+	require.NoError(err)
+	require.Equal([]byte{0x02, 0x00, 0x02, 0x02, 0x02, 0x08, 0x08}, data)
+
+	// Nearby points are delta encoded, and take far less space than WKB.
+	points := make([][3]float64, 100)
+	for i := range points {
+		points[i] = [3]float64{-122.4194 + float64(i)*0.0001, 37.7749 - float64(i)*0.0001, float64(i)}
+	}
+	l := types.NewSFLineStringXYZ(points)
+	data, err = l.MarshalTWKB(4)
+	require.NoError(err)
+	wkbData, err := l.Value()
+	require.NoError(err)
+	require.Less(len(data)*4, len(wkbData.([]byte)))
+
+	var decoded types.SFLineString
+	require.NoError(decoded.UnmarshalTWKB(data))
+	require.Equal(geom.XYZ, decoded.Layout())
+	require.InDeltaSlice(l.FlatCoords(), decoded.FlatCoords(), 1e-9)
+
+	// Empty LineStrings round-trip.
+	data, err = types.NewSFLineString(*geom.NewLineString(geom.XY)).MarshalTWKB(5)
+	require.NoError(err)
+	require.NoError(decoded.UnmarshalTWKB(data))
+	require.Equal(geom.XY, decoded.Layout())
+	require.Empty(decoded.FlatCoords())
+
+	_, err = types.SFLineString{}.MarshalTWKB(0)
+	require.Error(err)
+}
+
+func TestTWKBMetadata(t *testing.T) {
+	require := require.New(t)
+
+	// POINT(1 2) with a bounding box and size, which are skipped.
+	var p types.SFPoint
+	require.NoError(p.UnmarshalTWKB([]byte{0x01, 0x03, 0x06, 0x02, 0x00, 0x04, 0x00, 0x02, 0x04}))
+	require.Equal(types.NewSFPointXY(1, 2), p)
+
+	// LINESTRING(1 1,5 5) with a bounding box.
+	var l types.SFLineString
+	require.NoError(l.UnmarshalTWKB([]byte{0x02, 0x01, 0x02, 0x08, 0x02, 0x08, 0x02, 0x02, 0x02, 0x08, 0x08}))
+	require.Equal([]float64{1, 1, 5, 5}, l.FlatCoords())
+
+	for _, bad := range [][]byte{
+		nil,
+		{0x01},
+		{0x02, 0x00, 0x02, 0x04},             // LineString header
+		{0x01, 0x00, 0x02},                   // missing y
+		{0x01, 0x00, 0x02, 0x04, 0x00},       // trailing data
+		{0x01, 0x02, 0x09, 0x02, 0x04},       // wrong size
+		{0x01, 0x04, 0x02, 0x04},             // ID list
+		{0x01, 0x00, 0x02, 0xff, 0xff, 0xff}, // truncated varint
+	} {
+		before := types.NewSFPointXY(9, 9)
+		require.Error(before.UnmarshalTWKB(bad), "%x", bad)
+		require.Equal(types.NewSFPointXY(9, 9), before)
+	}
+	require.Error(l.UnmarshalTWKB([]byte{0x02, 0x00, 0x7f, 0x02, 0x02}))
+}